@@ -0,0 +1,28 @@
+// Package main is the entrypoint of robotctl, which runs Hetzner Robot
+// operations with the credentials of the provider, without Terraform.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/robotctl"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+
+	err := robotctl.Run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+
+	stop()
+
+	if err != nil && !errors.Is(err, flag.ErrHelp) {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+
+		os.Exit(1)
+	}
+}
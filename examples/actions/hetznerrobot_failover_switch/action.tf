@@ -0,0 +1,18 @@
+action "hetznerrobot_failover_switch" "to_standby" {
+  config {
+    ip               = "1.2.3.4"
+    active_server_ip = "5.6.7.8"
+  }
+}
+
+# Switch the failover IP to the standby before resetting the primary.
+resource "terraform_data" "maintenance" {
+  input = var.maintenance_window
+
+  lifecycle {
+    action_trigger {
+      events  = [before_update]
+      actions = [action.hetznerrobot_failover_switch.to_standby]
+    }
+  }
+}
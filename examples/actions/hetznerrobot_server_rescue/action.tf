@@ -0,0 +1,6 @@
+action "hetznerrobot_server_rescue" "web" {
+  config {
+    server_id = "1234567"
+    ssh_keys  = [file("~/.ssh/id_ed25519.pub")]
+  }
+}
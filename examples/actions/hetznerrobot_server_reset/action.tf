@@ -0,0 +1,25 @@
+action "hetznerrobot_server_reset" "web" {
+  config {
+    server_id    = "1234567"
+    type         = "hw"
+    wait_for_ssh = true
+  }
+}
+
+# Reset the server whenever its firewall changes.
+resource "hetznerrobot_firewall" "web" {
+  server_id = "1234567"
+
+  rule {
+    name     = "ssh"
+    dst_port = "22"
+    action   = "accept"
+  }
+
+  lifecycle {
+    action_trigger {
+      events  = [after_update]
+      actions = [action.hetznerrobot_server_reset.web]
+    }
+  }
+}
@@ -0,0 +1,6 @@
+action "hetznerrobot_server_wake" "web" {
+  config {
+    server_id    = "1234567"
+    wait_for_ssh = true
+  }
+}
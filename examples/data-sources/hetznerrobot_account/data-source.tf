@@ -0,0 +1,5 @@
+data "hetznerrobot_account" "current" {}
+
+output "server_count" {
+  value = data.hetznerrobot_account.current.server_count
+}
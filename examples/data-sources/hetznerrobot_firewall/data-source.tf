@@ -0,0 +1,12 @@
+data "hetznerrobot_firewall" "production" {
+  filter {
+    name_regex = "^prod-"
+  }
+}
+
+check "production_firewalls" {
+  assert {
+    condition     = alltrue([for fw in data.hetznerrobot_firewall.production.firewalls : fw.active])
+    error_message = "Every production server must have an active firewall."
+  }
+}
@@ -0,0 +1,33 @@
+# Opening the ephemeral resource activates the rescue system, the plan
+# already does.
+ephemeral "hetznerrobot_rescue_credentials" "test" {
+  server_id = "1234567"
+}
+
+action "hetznerrobot_server_reset" "rescue" {
+  config {
+    server_id    = "1234567"
+    wait_for_ssh = true
+  }
+}
+
+resource "terraform_data" "install" {
+  connection {
+    type     = "ssh"
+    host     = ephemeral.hetznerrobot_rescue_credentials.test.ip
+    user     = "root"
+    password = ephemeral.hetznerrobot_rescue_credentials.test.ssh_password
+  }
+
+  provisioner "remote-exec" {
+    inline = ["installimage -a -c /tmp/setup.conf"]
+  }
+
+  # Boot into the rescue system once the password has been read.
+  lifecycle {
+    action_trigger {
+      events  = [before_create]
+      actions = [action.hetznerrobot_server_reset.rescue]
+    }
+  }
+}
@@ -0,0 +1,28 @@
+locals {
+  rules = [
+    provider::hetznerrobot::firewall_rule({ name = "ssh", protocol = "tcp", dst_port = 22, action = "accept" }),
+    provider::hetznerrobot::firewall_rule({ name = "other", action = "discard" }),
+  ]
+}
+
+resource "hetznerrobot_firewall" "test" {
+  server_id     = "1234567"
+  active        = true
+  whitelist_hos = true
+
+  dynamic "rule" {
+    for_each = local.rules
+
+    content {
+      ip_version = rule.value.ip_version
+      name       = rule.value.name
+      src_ip     = rule.value.src_ip
+      src_port   = rule.value.src_port
+      dst_ip     = rule.value.dst_ip
+      dst_port   = rule.value.dst_port
+      protocol   = rule.value.protocol
+      tcp_flags  = rule.value.tcp_flags
+      action     = rule.value.action
+    }
+  }
+}
@@ -0,0 +1,5 @@
+data "hetznerrobot_server" "all" {}
+
+output "ipv6_address" {
+  value = provider::hetznerrobot::ipv6_host(data.hetznerrobot_server.all.servers[0].ipv6_net, 2)
+}
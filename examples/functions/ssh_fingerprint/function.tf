@@ -0,0 +1,3 @@
+output "fingerprint" {
+  value = provider::hetznerrobot::ssh_fingerprint(file("~/.ssh/id_ed25519.pub"))
+}
@@ -0,0 +1,8 @@
+variable "vlan" {
+  type = number
+
+  validation {
+    condition     = provider::hetznerrobot::vlan_in_range(var.vlan)
+    error_message = "The VLAN ID must be in [4000..4091]."
+  }
+}
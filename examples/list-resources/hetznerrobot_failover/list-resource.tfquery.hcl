@@ -0,0 +1,7 @@
+list "hetznerrobot_failover" "hel1" {
+  provider = hetznerrobot
+
+  config {
+    datacenter = "HEL1"
+  }
+}
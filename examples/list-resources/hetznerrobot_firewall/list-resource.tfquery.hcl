@@ -0,0 +1,8 @@
+list "hetznerrobot_firewall" "fsn1" {
+  provider = hetznerrobot
+
+  config {
+    datacenter = "FSN1"
+    name       = "web-*"
+  }
+}
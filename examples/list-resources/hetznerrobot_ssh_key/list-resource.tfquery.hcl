@@ -0,0 +1,3 @@
+list "hetznerrobot_ssh_key" "all" {
+  provider = hetznerrobot
+}
@@ -0,0 +1,4 @@
+list "hetznerrobot_vswitch" "all" {
+  provider         = hetznerrobot
+  include_resource = true
+}
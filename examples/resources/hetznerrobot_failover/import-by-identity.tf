@@ -0,0 +1,6 @@
+import {
+  to = hetznerrobot_failover.failover
+  identity = {
+    ip = "1.2.3.4"
+  }
+}
@@ -0,0 +1,2 @@
+# Import by failover IP
+terraform import hetznerrobot_failover.failover 1.2.3.4
@@ -0,0 +1,6 @@
+import {
+  to = hetznerrobot_firewall.firewall
+  identity = {
+    server_id = "1234567"
+  }
+}
@@ -0,0 +1,5 @@
+# Import by server number
+terraform import hetznerrobot_firewall.firewall 1234567
+
+# Import by main IPv4 address of the server
+terraform import hetznerrobot_firewall.firewall 1.2.3.4
@@ -0,0 +1,6 @@
+import {
+  to = hetznerrobot_server.web
+  identity = {
+    server_id = "1234567"
+  }
+}
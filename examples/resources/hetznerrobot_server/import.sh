@@ -0,0 +1,2 @@
+# Import by server number
+terraform import hetznerrobot_server.web 1234567
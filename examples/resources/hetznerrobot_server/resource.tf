@@ -0,0 +1,4 @@
+resource "hetznerrobot_server" "web" {
+  server_id   = "1234567"
+  server_name = "web-1"
+}
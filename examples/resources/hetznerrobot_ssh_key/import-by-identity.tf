@@ -0,0 +1,6 @@
+import {
+  to = hetznerrobot_ssh_key.key
+  identity = {
+    fingerprint = "c3:c9:0a:0f:b8:2e:b4:51:9f:5a:39:24:db:ae:16:95"
+  }
+}
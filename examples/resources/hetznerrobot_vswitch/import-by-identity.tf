@@ -0,0 +1,6 @@
+import {
+  to = hetznerrobot_vswitch.vswitch
+  identity = {
+    vswitch_id = "4321"
+  }
+}
@@ -0,0 +1,2 @@
+# Import by vSwitch ID
+terraform import hetznerrobot_vswitch.vswitch 4321
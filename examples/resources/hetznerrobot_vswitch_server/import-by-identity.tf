@@ -0,0 +1,7 @@
+import {
+  to = hetznerrobot_vswitch_server.attachment
+  identity = {
+    vswitch_id    = "4321"
+    server_number = 1234567
+  }
+}
@@ -0,0 +1,2 @@
+# Import by <vswitch_id>/<server_number>
+terraform import hetznerrobot_vswitch_server.attachment 4321/1234567
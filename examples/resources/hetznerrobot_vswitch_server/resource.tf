@@ -0,0 +1,4 @@
+resource "hetznerrobot_vswitch_server" "web" {
+  vswitch_id    = 10000
+  server_number = 1234567
+}
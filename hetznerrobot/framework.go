@@ -0,0 +1,338 @@
+package hetznerrobot
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/hashicorp/terraform-plugin-framework/action"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/list"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	providerschema "github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-mux/tf5to6server"
+	"github.com/hashicorp/terraform-plugin-mux/tf6muxserver"
+	sdkdiag "github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/failover"
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/firewall"
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/server"
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/sshkey"
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/vswitch"
+)
+
+// providerTypeName is the name of the provider, prefixing its resources.
+const providerTypeName = "hetznerrobot"
+
+// frameworkProvider serves the resources migrated to
+// terraform-plugin-framework. Its provider block mirrors the SDKv2 one, which
+// configures the client shared by both.
+type frameworkProvider struct {
+	sdk *schema.Provider
+}
+
+// NewFrameworkProvider returns the framework provider sharing the client
+// configured by sdk.
+//
+//nolint:ireturn
+func NewFrameworkProvider(sdk *schema.Provider) provider.Provider {
+	return &frameworkProvider{sdk: sdk}
+}
+
+// ProviderServer returns the protocol 6 server muxing the SDKv2 and the
+// framework providers.
+func ProviderServer(ctx context.Context) (func() tfprotov6.ProviderServer, error) {
+	sdk := Provider()
+
+	upgraded, err := tf5to6server.UpgradeServer(
+		ctx,
+		func() tfprotov5.ProviderServer { return schema.NewGRPCProviderServer(sdk) },
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error upgrading SDKv2 provider server: %w", err)
+	}
+
+	// Providers are configured in order: the SDKv2 one comes first and
+	// builds the client the framework one shares.
+	mux, err := tf6muxserver.NewMuxServer(
+		ctx,
+		func() tfprotov6.ProviderServer { return upgraded },
+		providerserver.NewProtocol6(NewFrameworkProvider(sdk)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error creating mux server: %w", err)
+	}
+
+	return mux.ProviderServer, nil
+}
+
+func (p *frameworkProvider) Metadata(
+	_ context.Context,
+	_ provider.MetadataRequest,
+	resp *provider.MetadataResponse,
+) {
+	resp.TypeName = providerTypeName
+}
+
+// Schema mirrors the SDKv2 provider block, as muxed providers must declare
+// the same one.
+func (p *frameworkProvider) Schema(
+	_ context.Context,
+	_ provider.SchemaRequest,
+	resp *provider.SchemaResponse,
+) {
+	attributes := make(map[string]providerschema.Attribute, len(p.sdk.Schema))
+
+	for name, attribute := range p.sdk.Schema {
+		mirrored, err := mirrorAttribute(attribute)
+		if err != nil {
+			resp.Diagnostics.AddError("Unsupported provider attribute "+name, err.Error())
+
+			continue
+		}
+
+		attributes[name] = mirrored
+	}
+
+	resp.Schema = providerschema.Schema{Attributes: attributes}
+}
+
+// mirrorAttribute returns the framework equivalent of an SDKv2 provider
+// attribute. Defaults and validation are left to the SDKv2 provider.
+//
+//nolint:ireturn
+func mirrorAttribute(attribute *schema.Schema) (providerschema.Attribute, error) {
+	optional := !attribute.Required
+
+	switch attribute.Type {
+	case schema.TypeString:
+		return providerschema.StringAttribute{
+			Required:    attribute.Required,
+			Optional:    optional,
+			Sensitive:   attribute.Sensitive,
+			Description: attribute.Description,
+		}, nil
+	case schema.TypeBool:
+		return providerschema.BoolAttribute{
+			Required:    attribute.Required,
+			Optional:    optional,
+			Sensitive:   attribute.Sensitive,
+			Description: attribute.Description,
+		}, nil
+	case schema.TypeInt:
+		return providerschema.Int64Attribute{
+			Required:    attribute.Required,
+			Optional:    optional,
+			Sensitive:   attribute.Sensitive,
+			Description: attribute.Description,
+		}, nil
+	case schema.TypeList, schema.TypeMap:
+		elem, ok := attribute.Elem.(*schema.Schema)
+		if !ok || elem.Type != schema.TypeString && elem.Type != schema.TypeInt {
+			return nil, fmt.Errorf("unsupported element type %T", attribute.Elem)
+		}
+
+		var elemType attr.Type = types.StringType
+		if elem.Type == schema.TypeInt {
+			elemType = types.Int64Type
+		}
+
+		if attribute.Type == schema.TypeMap {
+			return providerschema.MapAttribute{
+				ElementType: elemType,
+				Required:    attribute.Required,
+				Optional:    optional,
+				Sensitive:   attribute.Sensitive,
+				Description: attribute.Description,
+			}, nil
+		}
+
+		return providerschema.ListAttribute{
+			ElementType: elemType,
+			Required:    attribute.Required,
+			Optional:    optional,
+			Sensitive:   attribute.Sensitive,
+			Description: attribute.Description,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %s", attribute.Type)
+	}
+}
+
+// Configure shares the client of the SDKv2 provider. The mux server
+// configures that one first; otherwise it is configured here from the same
+// provider block, and builds the client once either way.
+func (p *frameworkProvider) Configure(
+	ctx context.Context,
+	req provider.ConfigureRequest,
+	resp *provider.ConfigureResponse,
+) {
+	meta := p.sdk.Meta()
+	if meta == nil {
+		raw, err := rawConfig(req.Config.Raw)
+		if err != nil {
+			resp.Diagnostics.AddError("Unable to read the provider configuration", err.Error())
+
+			return
+		}
+
+		for _, diagnostic := range p.sdk.Configure(ctx, terraform.NewResourceConfigRaw(raw)) {
+			if diagnostic.Severity == sdkdiag.Error {
+				resp.Diagnostics.AddError(diagnostic.Summary, diagnostic.Detail)
+			} else {
+				resp.Diagnostics.AddWarning(diagnostic.Summary, diagnostic.Detail)
+			}
+		}
+
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		meta = p.sdk.Meta()
+	}
+
+	resp.ResourceData = meta
+	resp.DataSourceData = meta
+	resp.EphemeralResourceData = meta
+	resp.ListResourceData = meta
+	resp.ActionData = meta
+}
+
+func (p *frameworkProvider) Resources(_ context.Context) []func() resource.Resource {
+	return []func() resource.Resource{
+		firewall.NewResource,
+		server.NewResource,
+		sshkey.NewResource,
+	}
+}
+
+// Actions run imperative operations, e.g. from lifecycle triggers.
+func (p *frameworkProvider) Actions(_ context.Context) []func() action.Action {
+	return []func() action.Action{
+		failover.NewSwitchAction,
+		server.NewRescueAction,
+		server.NewResetAction,
+		server.NewWakeAction,
+	}
+}
+
+// ListResources lists the objects of the account for `terraform query`,
+// including the ones managed by SDKv2 resources.
+func (p *frameworkProvider) ListResources(_ context.Context) []func() list.ListResource {
+	return []func() list.ListResource{
+		failover.NewList,
+		firewall.NewList,
+		server.NewList,
+		sshkey.NewList,
+		vswitch.NewList,
+	}
+}
+
+func (p *frameworkProvider) DataSources(_ context.Context) []func() datasource.DataSource {
+	return nil
+}
+
+func (p *frameworkProvider) EphemeralResources(
+	_ context.Context,
+) []func() ephemeral.EphemeralResource {
+	return []func() ephemeral.EphemeralResource{
+		server.NewEphemeralRescueCredentials,
+	}
+}
+
+func (p *frameworkProvider) Functions(_ context.Context) []func() function.Function {
+	return []func() function.Function{
+		firewall.NewRuleFunction,
+		server.NewIPv6HostFunction,
+		sshkey.NewFingerprintFunction,
+		vswitch.NewVLANInRangeFunction,
+	}
+}
+
+// rawConfig returns the known attributes of a provider block, in the form the
+// SDKv2 provider is configured with. Unknown values are left unset.
+func rawConfig(value tftypes.Value) (map[string]any, error) {
+	var attributes map[string]tftypes.Value
+
+	err := value.As(&attributes)
+	if err != nil {
+		return nil, fmt.Errorf("error reading provider block: %w", err)
+	}
+
+	raw := make(map[string]any, len(attributes))
+
+	for name, attribute := range attributes {
+		if attribute.IsNull() || !attribute.IsFullyKnown() {
+			continue
+		}
+
+		raw[name], err = rawValue(attribute)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", name, err)
+		}
+	}
+
+	return raw, nil
+}
+
+//nolint:cyclop
+func rawValue(value tftypes.Value) (any, error) {
+	var err error
+
+	switch typ := value.Type(); {
+	case typ.Is(tftypes.String):
+		var raw string
+		err = value.As(&raw)
+
+		return raw, err
+	case typ.Is(tftypes.Bool):
+		var raw bool
+		err = value.As(&raw)
+
+		return raw, err
+	case typ.Is(tftypes.Number):
+		var raw big.Float
+		err = value.As(&raw)
+		number, _ := raw.Int64()
+
+		return int(number), err
+	case typ.Is(tftypes.List{}):
+		var elems []tftypes.Value
+		err = value.As(&elems)
+		raw := make([]any, len(elems))
+
+		for i := range elems {
+			raw[i], err = rawValue(elems[i])
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return raw, err
+	case typ.Is(tftypes.Map{}):
+		var elems map[string]tftypes.Value
+		err = value.As(&elems)
+		raw := make(map[string]any, len(elems))
+
+		for key, elem := range elems {
+			raw[key], err = rawValue(elem)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return raw, err
+	default:
+		return nil, fmt.Errorf("unsupported type %s", typ)
+	}
+}
@@ -3,84 +3,442 @@ package hetznerrobot
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/account"
 	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/client"
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/credentials"
 	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/failover"
 	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/firewall"
 	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/server"
-	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/sshkey"
 	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/vswitch"
 )
 
-// Provider provides the HetznerRobot Terraform provider.
+// Provider provides the HetznerRobot Terraform provider. Resources migrated
+// to terraform-plugin-framework are served by NewFrameworkProvider instead.
 func Provider() *schema.Provider {
+	//exhaustruct:ignore
+	clients := &clientOnce{}
+
 	return &schema.Provider{
 		Schema: map[string]*schema.Schema{
 			"username": {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "Hetzner Robot API username.",
-				DefaultFunc: schema.EnvDefaultFunc("HETZNERROBOT_USERNAME", nil),
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "Hetzner Robot API username. Defaults to the HETZNERROBOT_USERNAME " +
+					"environment variable, then to the other credential sources.",
 			},
 			"password": {
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+				Description: "Hetzner Robot API password. Defaults to the HETZNERROBOT_PASSWORD " +
+					"environment variable, then to the other credential sources.",
+			},
+			"credentials_file": {
 				Type:        schema.TypeString,
-				Required:    true,
-				Sensitive:   true,
-				Description: "Hetzner Robot API password.",
-				DefaultFunc: schema.EnvDefaultFunc("HETZNERROBOT_PASSWORD", nil),
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("HETZNERROBOT_CREDENTIALS_FILE", ""),
+				Description: "Path to an INI or JSON file of named profiles holding " +
+					"the username and password.",
 			},
-			"url": {
-				Type:     schema.TypeString,
+			"profile": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("HETZNERROBOT_PROFILE", "default"),
+				Description: "Profile of credentials_file to use.",
+			},
+			"credentials_command": {
+				Type:     schema.TypeList,
 				Optional: true,
-				DefaultFunc: schema.EnvDefaultFunc(
-					"HETZNERROBOT_URL",
-					"https://robot-ws.your-server.de",
-				),
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Description: "Command and arguments of a helper printing the credentials " +
+					`as {"username": "...", "password": "..."}.`,
+			},
+			"url": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("HETZNERROBOT_URL", client.DefaultBaseURL),
 				Description: "Base URL for the Hetzner Robot API.",
 			},
+			"cache_ttl": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				DefaultFunc:      schema.EnvDefaultFunc("HETZNERROBOT_CACHE_TTL", "0s"),
+				ValidateDiagFunc: validation.ToDiagFunc(validateDuration),
+				Description: "How long server and vSwitch listings are cached (e.g., 5m), " +
+					"to save API quota when many resources look up the same servers. " +
+					"Any change made by the provider clears the cache. Defaults to 0s, " +
+					"which only merges identical concurrent requests.",
+			},
+			"max_concurrency": {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Default:          10,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(1)),
+				Description:      "Maximum number of concurrent requests when reading many objects at once.",
+			},
+			"request_timeout": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				DefaultFunc:      schema.EnvDefaultFunc("HETZNERROBOT_REQUEST_TIMEOUT", "60s"),
+				ValidateDiagFunc: validation.ToDiagFunc(validateDuration),
+				Description:      "Timeout of a single request to the Robot API, 0s disables it.",
+			},
+			"max_idle_conns": {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Default:          10,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(1)),
+				Description:      "Number of idle connections to the Robot API kept open.",
+			},
+			"idle_conn_timeout": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "90s",
+				ValidateDiagFunc: validation.ToDiagFunc(validateDuration),
+				Description: "How long an idle connection to the Robot API is kept open, " +
+					"0s keeps the Go default of 90s.",
+			},
+			"http_proxy": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				DefaultFunc:      schema.EnvDefaultFunc("HETZNERROBOT_HTTP_PROXY", ""),
+				ValidateDiagFunc: validation.ToDiagFunc(validateProxyURL),
+				Description: "URL of the HTTP proxy to reach the Robot API through " +
+					"(e.g., http://proxy:3128). Defaults to the HTTPS_PROXY environment variable.",
+			},
+			"ca_cert_pem": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ConflictsWith:    []string{"ca_cert_file"},
+				ValidateDiagFunc: validation.ToDiagFunc(validateCACerts),
+				Description: "PEM encoded CA certificates to trust on top of the system ones, " +
+					"e.g. the one of a TLS inspecting proxy.",
+			},
+			"ca_cert_file": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"ca_cert_pem"},
+				Description:   "Path to a file of PEM encoded CA certificates, see ca_cert_pem.",
+			},
+			"insecure_skip_verify": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				Description: "Whether to skip the verification of the TLS certificate. " +
+					"Only meant for test endpoints.",
+			},
+			"skip_credentials_validation": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				Description: "Whether to skip the request checking the credentials when the " +
+					"provider is configured, e.g. to save server request quota.",
+			},
+			"quota_limits": {
+				Type:             schema.TypeMap,
+				Optional:         true,
+				Elem:             &schema.Schema{Type: schema.TypeInt},
+				ValidateDiagFunc: validation.ToDiagFunc(validateQuotaLimits),
+				Description: "Hourly request limit per endpoint family (boot, failover, firewall, " +
+					"key, reset, server, vswitch, wol), overriding the limits documented by Robot. " +
+					"Requests are throttled to stay within them.",
+			},
 		},
 		ResourcesMap: map[string]*schema.Resource{
 			"hetznerrobot_failover":        failover.Resource(),
-			"hetznerrobot_firewall":        firewall.Resource(),
 			"hetznerrobot_os_rescue":       server.ResourceOSRescue(),
-			"hetznerrobot_ssh_key":         sshkey.Resource(),
 			"hetznerrobot_vswitch":         vswitch.Resource(),
+			"hetznerrobot_vswitch_server":  vswitch.ServerResource(),
 			"hetznerrobot_vswitch_servers": vswitch.ServersResource(),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
-			"hetznerrobot_server":  server.DataSourceServers(),
-			"hetznerrobot_vswitch": vswitch.DataSource(),
+			"hetznerrobot_account":  account.DataSource(),
+			"hetznerrobot_firewall": firewall.DataSource(),
+			"hetznerrobot_server":   server.DataSourceServers(),
+			"hetznerrobot_vswitch":  vswitch.DataSource(),
 		},
-		ConfigureContextFunc: providerConfigure,
+		ConfigureContextFunc: clients.configure,
 	}
 }
 
+// clientOnce builds the client of a provider once: the SDKv2 and the
+// framework providers both configure it, in the order the mux server calls
+// them.
+type clientOnce struct {
+	once   sync.Once
+	client any
+	diags  diag.Diagnostics
+}
+
+func (c *clientOnce) configure(ctx context.Context, d *schema.ResourceData) (any, diag.Diagnostics) {
+	c.once.Do(func() {
+		c.client, c.diags = providerConfigure(ctx, d)
+	})
+
+	return c.client, c.diags
+}
+
 // providerConfigure configures the HetznerRobot Terraform provider.
-func providerConfigure(_ context.Context, d *schema.ResourceData) (any, diag.Diagnostics) {
+func providerConfigure(ctx context.Context, d *schema.ResourceData) (any, diag.Diagnostics) {
 	var diags diag.Diagnostics
 
-	username := d.Get("username").(string)
-	password := d.Get("password").(string)
 	url := d.Get("url").(string)
 
-	if username == "" || password == "" {
+	// Already validated by validateDuration.
+	cacheTTL, _ := time.ParseDuration(d.Get("cache_ttl").(string))
+	timeout, _ := time.ParseDuration(d.Get("request_timeout").(string))
+	idleConnTimeout, _ := time.ParseDuration(d.Get("idle_conn_timeout").(string))
+
+	creds, err := credentials.Resolve(ctx, credentialSources(d, url))
+	if errors.Is(err, credentials.ErrMissingCredentials) {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "Missing credentials",
-			Detail:   "Both username and password must be provided.",
+			Detail:   "Both username and password must be provided: " + err.Error(),
 		})
 
 		return nil, diags
 	}
 
+	if err != nil {
+		return nil, append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to read credentials",
+			Detail:   err.Error(),
+		})
+	}
+
+	tflog.Info(ctx, "Resolved Robot credentials", map[string]any{
+		"username_source": creds.UsernameSource,
+		"password_source": creds.PasswordSource,
+	})
+
+	caCertPEM := d.Get("ca_cert_pem").(string)
+
+	if caCertFile := d.Get("ca_cert_file").(string); caCertFile != "" {
+		data, err := os.ReadFile(caCertFile)
+		if err != nil {
+			return nil, append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to read CA certificate file",
+				Detail:   fmt.Sprintf("ca_cert_file: %v", err),
+			})
+		}
+
+		caCertPEM = string(data)
+	}
+
 	config := &client.ProviderConfig{
-		Username: username,
-		Password: password,
-		BaseURL:  url,
+		Username:           creds.Username,
+		Password:           creds.Password,
+		BaseURL:            url,
+		CacheTTL:           cacheTTL,
+		QuotaLimits:        expandQuotaLimits(d.Get("quota_limits").(map[string]any)),
+		MaxConcurrency:     d.Get("max_concurrency").(int),
+		Timeout:            timeout,
+		MaxIdleConns:       d.Get("max_idle_conns").(int),
+		IdleConnTimeout:    idleConnTimeout,
+		HTTPProxy:          d.Get("http_proxy").(string),
+		CACertPEM:          caCertPEM,
+		InsecureSkipVerify: d.Get("insecure_skip_verify").(bool),
+	}
+
+	client, err := client.New(config)
+	if err != nil {
+		return nil, append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Invalid transport configuration",
+			Detail:   err.Error(),
+		})
+	}
+
+	if !d.Get("skip_credentials_validation").(bool) {
+		diags = append(diags, validateCredentials(ctx, client, creds)...)
+		if diags.HasError() {
+			return nil, diags
+		}
+	}
+
+	if config.InsecureSkipVerify {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "TLS certificate verification disabled",
+			Detail: "insecure_skip_verify is set, the identity of the Robot API is not verified. " +
+				"Only use it with test endpoints.",
+		})
 	}
-	client := client.New(config)
 
 	return client, diags
 }
+
+func validateDuration(value any, key string) ([]string, []error) {
+	raw, ok := value.(string)
+	if !ok {
+		return nil, []error{fmt.Errorf("expected type of %s to be string", key)}
+	}
+
+	duration, err := time.ParseDuration(raw)
+	if err != nil || duration < 0 {
+		return nil, []error{
+			fmt.Errorf("%s must be a non-negative duration (e.g., 0s, 30s or 5m), got %q", key, raw),
+		}
+	}
+
+	return nil, nil
+}
+
+func expandQuotaLimits(raw map[string]any) map[string]int {
+	limits := make(map[string]int, len(raw))
+	for family, limit := range raw {
+		limits[family] = limit.(int)
+	}
+
+	return limits
+}
+
+func validateProxyURL(value any, key string) ([]string, []error) {
+	raw, ok := value.(string)
+	if !ok {
+		return nil, []error{fmt.Errorf("expected type of %s to be string", key)}
+	}
+
+	if raw == "" {
+		return nil, nil
+	}
+
+	_, err := client.ParseProxyURL(raw)
+	if err != nil {
+		return nil, []error{fmt.Errorf("%s: %w", key, err)}
+	}
+
+	return nil, nil
+}
+
+func validateQuotaLimits(value any, key string) ([]string, []error) {
+	raw, ok := value.(map[string]any)
+	if !ok {
+		return nil, []error{fmt.Errorf("expected type of %s to be map", key)}
+	}
+
+	defaults := client.DefaultQuotaLimits()
+
+	var errs []error
+
+	for _, family := range slices.Sorted(maps.Keys(raw)) {
+		if _, ok := defaults[family]; !ok {
+			errs = append(errs, fmt.Errorf(
+				"%s: unknown endpoint family %q, expected one of %s",
+				key, family, strings.Join(slices.Sorted(maps.Keys(defaults)), ", "),
+			))
+
+			continue
+		}
+
+		limit, err := strconv.Atoi(fmt.Sprint(raw[family]))
+		if err != nil || limit < 1 {
+			errs = append(errs, fmt.Errorf("%s: limit of %s must be at least 1, got %v", key, family, raw[family]))
+		}
+	}
+
+	return nil, errs
+}
+
+func validateCACerts(value any, key string) ([]string, []error) {
+	raw, ok := value.(string)
+	if !ok {
+		return nil, []error{fmt.Errorf("expected type of %s to be string", key)}
+	}
+
+	_, err := client.ParseCACerts(raw)
+	if err != nil {
+		return nil, []error{fmt.Errorf("%s: %w", key, err)}
+	}
+
+	return nil, nil
+}
+
+// validateCredentials turns the refusal of the credentials into a diagnostic
+// naming where they come from, instead of a 401 from the first resource.
+func validateCredentials(
+	ctx context.Context,
+	hClient *client.HetznerRobotClient,
+	creds credentials.Credentials,
+) diag.Diagnostics {
+	err := hClient.ValidateCredentials(ctx)
+
+	sources := fmt.Sprintf(
+		"The username comes from the %s and the password from the %s.",
+		creds.UsernameSource,
+		creds.PasswordSource,
+	)
+
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, client.ErrUnauthorized):
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  "Invalid Robot credentials",
+			Detail: fmt.Sprintf(
+				"The Robot API rejected the username %q and its password (401 Unauthorized). "+
+					"%s Use the webservice user of Robot > Settings > Webservice and app "+
+					"settings, not the Robot login.",
+				creds.Username,
+				sources,
+			),
+		}}
+	case errors.Is(err, client.ErrForbidden):
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  "Robot API access forbidden",
+			Detail: fmt.Sprintf(
+				"The Robot API refused access to the username %q (403 Forbidden), e.g. as the "+
+					"client IP is blocked after failed logins. %s\n\n%v",
+				creds.Username,
+				sources,
+				err,
+			),
+		}}
+	default:
+		// Resources report their own errors if the API stays unreachable.
+		return diag.Diagnostics{{
+			Severity: diag.Warning,
+			Summary:  "Unable to validate Robot credentials",
+			Detail:   err.Error(),
+		}}
+	}
+}
+
+// credentialSources returns the credential sources in order of precedence.
+func credentialSources(d *schema.ResourceData, url string) []credentials.Source {
+	raw := d.Get("credentials_command").([]any)
+
+	command := make([]string, len(raw))
+	for i, arg := range raw {
+		command[i], _ = arg.(string)
+	}
+
+	return credentials.Chain(credentials.Options{
+		Name:     "provider configuration",
+		Username: d.Get("username").(string),
+		Password: d.Get("password").(string),
+		Command:  command,
+		File:     d.Get("credentials_file").(string),
+		Profile:  d.Get("profile").(string),
+		URL:      url,
+	})
+}
@@ -1,10 +1,22 @@
 package hetznerrobot_test
 
 import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/stretchr/testify/assert"
 	"github.com/yellowhat/terraform-provider-hetznerrobot/hetznerrobot"
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/account"
 	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/failover"
 	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/firewall"
 	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/server"
@@ -21,16 +33,58 @@ func TestProvider(t *testing.T) {
 	}
 }
 
+func TestProviderValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		config  map[string]any
+		wantErr bool
+	}{
+		{
+			name:   "Quota limits",
+			config: map[string]any{"quota_limits": map[string]any{"server": 100, "wol": 1}},
+		},
+		{
+			name:    "Quota limits of an unknown family",
+			config:  map[string]any{"quota_limits": map[string]any{"servers": 100}},
+			wantErr: true,
+		},
+		{
+			name:   "Durations of 0s",
+			config: map[string]any{"cache_ttl": "0s", "request_timeout": "0s", "idle_conn_timeout": "0s"},
+		},
+		{
+			name:    "Negative duration",
+			config:  map[string]any{"request_timeout": "-1s"},
+			wantErr: true,
+		},
+		{
+			name:    "Quota limit below 1",
+			config:  map[string]any{"quota_limits": map[string]any{"server": 0}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			diags := hetznerrobot.Provider().Validate(terraform.NewResourceConfigRaw(tt.config))
+			assert.Equal(t, tt.wantErr, diags.HasError(), "diagnostics: %v", diags)
+		})
+	}
+}
+
 func TestProvider_Resources(t *testing.T) {
 	t.Parallel()
 
 	provider := hetznerrobot.Provider()
 	expectedResources := []string{
 		failover.ResourceType,
-		firewall.ResourceType,
 		server.ResourceOSRescueType,
-		sshkey.ResourceType,
 		vswitch.ResourceType,
+		vswitch.ServerResourceType,
 		vswitch.ServersResourceType,
 	}
 
@@ -47,6 +101,8 @@ func TestProvider_DataSources(t *testing.T) {
 
 	provider := hetznerrobot.Provider()
 	expectedDataSources := []string{
+		account.DataSourceType,
+		firewall.DataSourceType,
 		server.DataSourceType,
 		vswitch.DataSourceType,
 	}
@@ -58,3 +114,1267 @@ func TestProvider_DataSources(t *testing.T) {
 		assert.Contains(t, expectedDataSources, datasource.Name)
 	}
 }
+
+func TestProviderServer(t *testing.T) {
+	t.Parallel()
+
+	providerServer, err := hetznerrobot.ProviderServer(context.Background())
+	if err != nil {
+		t.Fatalf("ProviderServer() error: %v", err)
+	}
+
+	//exhaustruct:ignore
+	resp, err := providerServer().GetProviderSchema(
+		context.Background(),
+		&tfprotov6.GetProviderSchemaRequest{},
+	)
+	if err != nil {
+		t.Fatalf("GetProviderSchema() error: %v", err)
+	}
+
+	// The mux server reports differing provider blocks as diagnostics.
+	for _, diagnostic := range resp.Diagnostics {
+		t.Errorf("unexpected diagnostic: %s: %s", diagnostic.Summary, diagnostic.Detail)
+	}
+
+	expectedResources := []string{
+		failover.ResourceType,
+		firewall.ResourceType,
+		server.ResourceOSRescueType,
+		server.ResourceType,
+		sshkey.ResourceType,
+		vswitch.ResourceType,
+		vswitch.ServerResourceType,
+		vswitch.ServersResourceType,
+	}
+
+	assert.Len(t, resp.ResourceSchemas, len(expectedResources))
+
+	for _, name := range expectedResources {
+		assert.Contains(t, resp.ResourceSchemas, name)
+	}
+
+	assert.Contains(t, resp.EphemeralResourceSchemas, server.EphemeralRescueCredentialsType)
+
+	for _, name := range []string{
+		firewall.RuleFunctionName,
+		server.IPv6HostFunctionName,
+		sshkey.FingerprintFunctionName,
+		vswitch.VLANInRangeFunctionName,
+	} {
+		assert.Contains(t, resp.Functions, name)
+	}
+}
+
+// TestFrameworkProviderConfigure checks that the framework provider
+// configured first builds the client, and that the SDKv2 provider then
+// shares it.
+func TestFrameworkProviderConfigure(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	sdk := hetznerrobot.Provider()
+	frameworkServer := providerserver.NewProtocol6(hetznerrobot.NewFrameworkProvider(sdk))()
+
+	//exhaustruct:ignore
+	schemas, err := frameworkServer.GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatalf("GetProviderSchema() error: %v", err)
+	}
+
+	configureProvider(t, frameworkServer, schemas.Provider, map[string]any{
+		"username":                    "foo",
+		"password":                    "bar",
+		"skip_credentials_validation": true,
+		"quota_limits": map[string]tftypes.Value{
+			"server": tftypes.NewValue(tftypes.Number, 100),
+		},
+	})
+
+	meta := sdk.Meta()
+	if meta == nil {
+		t.Fatal("Meta() = nil after configuring the framework provider")
+	}
+
+	diags := sdk.Configure(ctx, terraform.NewResourceConfigRaw(map[string]any{
+		"username":                    "foo",
+		"password":                    "bar",
+		"skip_credentials_validation": true,
+	}))
+	assert.Empty(t, diags)
+	assert.Same(t, meta, sdk.Meta())
+}
+
+func TestCallFunction(t *testing.T) {
+	t.Parallel()
+
+	providerServer, err := hetznerrobot.ProviderServer(context.Background())
+	if err != nil {
+		t.Fatalf("ProviderServer() error: %v", err)
+	}
+
+	argument, err := tfprotov6.NewDynamicValue(
+		tftypes.String,
+		tftypes.NewValue(
+			tftypes.String,
+			"ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIAisoHodfVmpw35XXG9kLCOaD4REpVJj/E9zy+FKUsym",
+		),
+	)
+	if err != nil {
+		t.Fatalf("NewDynamicValue() error: %v", err)
+	}
+
+	// Functions are called without configuring the provider.
+	resp, err := providerServer().CallFunction(
+		context.Background(),
+		&tfprotov6.CallFunctionRequest{
+			Name:      sshkey.FingerprintFunctionName,
+			Arguments: []*tfprotov6.DynamicValue{&argument},
+		},
+	)
+	if err != nil {
+		t.Fatalf("CallFunction() error: %v", err)
+	}
+
+	if resp.Error != nil {
+		t.Fatalf("CallFunction() function error: %s", resp.Error.Text)
+	}
+
+	result, err := resp.Result.Unmarshal(tftypes.String)
+	if err != nil {
+		t.Fatalf("Unmarshal() error: %v", err)
+	}
+
+	var fingerprint string
+
+	err = result.As(&fingerprint)
+	if err != nil {
+		t.Fatalf("As() error: %v", err)
+	}
+
+	assert.Equal(t, "c3:c9:0a:0f:b8:2e:b4:51:9f:5a:39:24:db:ae:16:95", fingerprint)
+}
+
+// TestUpgradeResourceState checks that states written by the SDKv2
+// implementation of the migrated resources are still readable.
+func TestUpgradeResourceState(t *testing.T) {
+	t.Parallel()
+
+	sdkRule := `{"action":"accept","dst_ip":"","dst_port":"22","ip_version":"ipv4",` +
+		`"name":"ssh","protocol":"tcp","src_ip":"","src_port":"","tcp_flags":""}`
+
+	tests := []struct {
+		name         string
+		resourceType string
+		state        string
+	}{
+		{
+			name:         "Firewall",
+			resourceType: firewall.ResourceType,
+			state: `{"active":true,"compact":false,"effective_rule":[` + sdkRule + `],` +
+				`"id":"1","on_destroy":"allow_all","previous_firewall":"{}",` +
+				`"rule":[` + sdkRule + `],"server_id":"1","server_ip":"1.2.3.4","whitelist_hos":true}`,
+		},
+		{
+			name:         "Firewall before server_ip and compact",
+			resourceType: firewall.ResourceType,
+			state: `{"active":true,"id":"1","on_destroy":"keep","previous_firewall":"",` +
+				`"rule":[` + sdkRule + `],"server_id":"1","whitelist_hos":false}`,
+		},
+		{
+			name:         "Firewall from the first release",
+			resourceType: firewall.ResourceType,
+			state: `{"active":true,"id":"1","rule":[{"action":"accept","dst_ip":"","dst_port":"22",` +
+				`"name":"ssh","protocol":"tcp","src_ip":"","src_port":"","tcp_flags":""}],` +
+				`"server_id":"1","whitelist_hos":true}`,
+		},
+		{
+			name:         "SSH key",
+			resourceType: sshkey.ResourceType,
+			state: `{"created_at":"2021-01-01 00:00:00","data":"ssh-ed25519 AAAA",` +
+				`"fingerprint":"aa:bb","id":"aa:bb","name":"laptop","size":256,"type":"ED25519"}`,
+		},
+	}
+
+	providerServer, err := hetznerrobot.ProviderServer(context.Background())
+	if err != nil {
+		t.Fatalf("ProviderServer() error: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			//exhaustruct:ignore
+			resp, err := providerServer().UpgradeResourceState(
+				context.Background(),
+				&tfprotov6.UpgradeResourceStateRequest{
+					TypeName: tt.resourceType,
+					Version:  0,
+					//exhaustruct:ignore
+					RawState: &tfprotov6.RawState{JSON: []byte(tt.state)},
+				},
+			)
+			if err != nil {
+				t.Fatalf("UpgradeResourceState() error: %v", err)
+			}
+
+			for _, diagnostic := range resp.Diagnostics {
+				t.Errorf("unexpected diagnostic: %s: %s", diagnostic.Summary, diagnostic.Detail)
+			}
+
+			if resp.UpgradedState == nil {
+				t.Fatal("UpgradeResourceState() returned no state")
+			}
+		})
+	}
+}
+
+// TestUpgradeBaselineFirewallState checks that a firewall state written by
+// the first SDKv2 release, before on_destroy, compact, effective_rule,
+// server_ip and ip_version existed, refreshes to the schema defaults and
+// plans no change.
+func TestUpgradeBaselineFirewallState(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	robot := httptest.NewServer(
+		http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+			switch req.URL.Path {
+			case "/server/1":
+				_, _ = writer.Write([]byte(`{"server":{"server_ip":"1.2.3.4","server_number":1}}`))
+			case "/firewall/1.2.3.4":
+				_, _ = writer.Write([]byte(`{"firewall":{"status":"active","whitelist_hos":true,` +
+					`"rules":{"input":[{"ip_version":"ipv4","name":"ssh","dst_port":"22",` +
+					`"protocol":"tcp","action":"accept"}]}}}`))
+			default:
+				http.NotFound(writer, req)
+			}
+		}),
+	)
+	t.Cleanup(robot.Close)
+
+	providerServer, err := hetznerrobot.ProviderServer(ctx)
+	if err != nil {
+		t.Fatalf("ProviderServer() error: %v", err)
+	}
+
+	//exhaustruct:ignore
+	schemas, err := providerServer().GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatalf("GetProviderSchema() error: %v", err)
+	}
+
+	configureProvider(t, providerServer(), schemas.Provider, map[string]any{
+		"username":                    "foo",
+		"password":                    "bar",
+		"url":                         robot.URL,
+		"skip_credentials_validation": true,
+	})
+
+	stateType := schemas.ResourceSchemas[firewall.ResourceType].ValueType()
+	attributeTypes := stateType.(tftypes.Object).AttributeTypes
+	ruleType := attributeTypes["rule"].(tftypes.List).ElementType
+	ruleAttributeTypes := ruleType.(tftypes.Object).AttributeTypes
+
+	//exhaustruct:ignore
+	upgradeResp, err := providerServer().UpgradeResourceState(ctx, &tfprotov6.UpgradeResourceStateRequest{
+		TypeName: firewall.ResourceType,
+		Version:  0,
+		//exhaustruct:ignore
+		RawState: &tfprotov6.RawState{JSON: []byte(`{"active":true,"id":"1","rule":[{"action":"accept",` +
+			`"dst_ip":"","dst_port":"22","name":"ssh","protocol":"tcp","src_ip":"","src_port":"",` +
+			`"tcp_flags":""}],"server_id":"1","whitelist_hos":true}`)},
+	})
+	if err != nil {
+		t.Fatalf("UpgradeResourceState() error: %v", err)
+	}
+
+	for _, diagnostic := range upgradeResp.Diagnostics {
+		t.Errorf("unexpected diagnostic: %s: %s", diagnostic.Summary, diagnostic.Detail)
+	}
+
+	//exhaustruct:ignore
+	readResp, err := providerServer().ReadResource(ctx, &tfprotov6.ReadResourceRequest{
+		TypeName:     firewall.ResourceType,
+		CurrentState: upgradeResp.UpgradedState,
+	})
+	if err != nil {
+		t.Fatalf("ReadResource() error: %v", err)
+	}
+
+	for _, diagnostic := range readResp.Diagnostics {
+		t.Errorf("unexpected diagnostic: %s: %s", diagnostic.Summary, diagnostic.Detail)
+	}
+
+	var state map[string]tftypes.Value
+
+	err = unmarshalValue(t, readResp.NewState, stateType).As(&state)
+	if err != nil {
+		t.Fatalf("As() error: %v", err)
+	}
+
+	var rules []tftypes.Value
+
+	err = state["rule"].As(&rules)
+	if err != nil || len(rules) != 1 {
+		t.Fatalf("rule = %v, error: %v", state["rule"], err)
+	}
+
+	var rule map[string]tftypes.Value
+
+	err = rules[0].As(&rule)
+	if err != nil {
+		t.Fatalf("As() error: %v", err)
+	}
+
+	assert.True(t, state["on_destroy"].Equal(tftypes.NewValue(tftypes.String, "allow_all")))
+	assert.True(t, state["compact"].Equal(tftypes.NewValue(tftypes.Bool, false)))
+	assert.True(t, state["server_ip"].Equal(tftypes.NewValue(tftypes.String, "1.2.3.4")))
+	assert.True(t, rule["ip_version"].Equal(tftypes.NewValue(tftypes.String, "ipv4")))
+	assert.True(t, rule["src_ip"].IsNull())
+
+	// The configuration of the first release, proposed as Terraform would.
+	configRule := maps.Clone(rule)
+	configRule["ip_version"] = tftypes.NewValue(ruleAttributeTypes["ip_version"], nil)
+
+	config := maps.Clone(state)
+	for _, key := range []string{"id", "server_ip", "on_destroy", "compact", "effective_rule"} {
+		config[key] = tftypes.NewValue(attributeTypes[key], nil)
+	}
+
+	config["rule"] = tftypes.NewValue(attributeTypes["rule"], []tftypes.Value{
+		tftypes.NewValue(ruleType, configRule),
+	})
+
+	//exhaustruct:ignore
+	planResp, err := providerServer().PlanResourceChange(ctx, &tfprotov6.PlanResourceChangeRequest{
+		TypeName:         firewall.ResourceType,
+		PriorState:       readResp.NewState,
+		ProposedNewState: readResp.NewState,
+		Config:           newDynamicValue(t, stateType, config),
+		PriorPrivate:     readResp.Private,
+		PriorIdentity:    readResp.NewIdentity,
+	})
+	if err != nil {
+		t.Fatalf("PlanResourceChange() error: %v", err)
+	}
+
+	for _, diagnostic := range planResp.Diagnostics {
+		t.Errorf("unexpected diagnostic: %s: %s", diagnostic.Summary, diagnostic.Detail)
+	}
+
+	assert.Empty(t, planResp.RequiresReplace)
+	assert.True(t, unmarshalValue(t, planResp.PlannedState, stateType).Equal(
+		unmarshalValue(t, readResp.NewState, stateType),
+	))
+}
+
+// TestImportIdentityRoundTrip checks that importing by ID and by the
+// resulting identity give the same resource, so that generated import blocks
+// are stable.
+func TestImportIdentityRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	providerServer, err := hetznerrobot.ProviderServer(ctx)
+	if err != nil {
+		t.Fatalf("ProviderServer() error: %v", err)
+	}
+
+	//exhaustruct:ignore
+	identitySchemas, err := providerServer().GetResourceIdentitySchemas(
+		ctx,
+		&tfprotov6.GetResourceIdentitySchemasRequest{},
+	)
+	if err != nil {
+		t.Fatalf("GetResourceIdentitySchemas() error: %v", err)
+	}
+
+	for _, name := range []string{
+		failover.ResourceType,
+		firewall.ResourceType,
+		server.ResourceOSRescueType,
+		server.ResourceType,
+		sshkey.ResourceType,
+		vswitch.ResourceType,
+		vswitch.ServerResourceType,
+		vswitch.ServersResourceType,
+	} {
+		assert.Contains(t, identitySchemas.IdentitySchemas, name)
+	}
+
+	//exhaustruct:ignore
+	schemas, err := providerServer().GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatalf("GetProviderSchema() error: %v", err)
+	}
+
+	// The server import fetches the server, the vSwitch import the vSwitch,
+	// found pending cancellation.
+	robot := httptest.NewServer(
+		http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+			switch req.URL.Path {
+			case "/server/1":
+				_, _ = writer.Write([]byte(`{"server":{"server_ip":"1.2.3.4","server_number":1,"server_name":"web-1"}}`))
+			case "/vswitch/4321":
+				_, _ = writer.Write([]byte(`{"id":4321,"name":"private","vlan":4000,"cancelled":true,"server":[]}`))
+			default:
+				http.NotFound(writer, req)
+			}
+		}),
+	)
+	t.Cleanup(robot.Close)
+
+	configureProvider(t, providerServer(), schemas.Provider, map[string]any{
+		"username":                    "foo",
+		"password":                    "bar",
+		"url":                         robot.URL,
+		"skip_credentials_validation": true,
+	})
+
+	// The firewall import fetches the server, it is covered by
+	// TestFirewallPreviousFirewall.
+	tests := []struct {
+		resourceType string
+		id           string
+		identity     map[string]any
+	}{
+		{
+			resourceType: failover.ResourceType,
+			id:           "1.2.3.4",
+			identity:     map[string]any{"ip": "1.2.3.4"},
+		},
+		{
+			resourceType: server.ResourceType,
+			id:           "1",
+			identity:     map[string]any{"server_id": "1"},
+		},
+		{
+			resourceType: sshkey.ResourceType,
+			id:           "c3:c9:0a:0f:b8:2e:b4:51:9f:5a:39:24:db:ae:16:95",
+			identity:     map[string]any{"fingerprint": "c3:c9:0a:0f:b8:2e:b4:51:9f:5a:39:24:db:ae:16:95"},
+		},
+		{
+			resourceType: vswitch.ResourceType,
+			id:           "4321",
+			identity:     map[string]any{"vswitch_id": "4321"},
+		},
+		{
+			resourceType: vswitch.ServerResourceType,
+			id:           "4321/1",
+			identity:     map[string]any{"vswitch_id": "4321", "server_number": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.resourceType, func(t *testing.T) {
+			t.Parallel()
+
+			identityType := identitySchemas.IdentitySchemas[tt.resourceType].ValueType()
+			stateType := schemas.ResourceSchemas[tt.resourceType].ValueType()
+
+			attributes := make(map[string]tftypes.Value, len(tt.identity))
+			for key, value := range tt.identity {
+				attributes[key] = tftypes.NewValue(
+					identityType.(tftypes.Object).AttributeTypes[key],
+					value,
+				)
+			}
+
+			want := tftypes.NewValue(identityType, attributes)
+
+			//exhaustruct:ignore
+			byID := importResource(t, providerServer(), &tfprotov6.ImportResourceStateRequest{
+				TypeName: tt.resourceType,
+				ID:       tt.id,
+			})
+
+			gotIdentity := unmarshalValue(t, byID.Identity.IdentityData, identityType)
+			if !gotIdentity.Equal(want) {
+				t.Fatalf("identity after import by ID\nwant: %s\ngot:  %s", want, gotIdentity)
+			}
+
+			identityData, err := tfprotov6.NewDynamicValue(identityType, want)
+			if err != nil {
+				t.Fatalf("NewDynamicValue() error: %v", err)
+			}
+
+			//exhaustruct:ignore
+			byIdentity := importResource(t, providerServer(), &tfprotov6.ImportResourceStateRequest{
+				TypeName: tt.resourceType,
+				Identity: &tfprotov6.ResourceIdentityData{IdentityData: &identityData},
+			})
+
+			gotIdentity = unmarshalValue(t, byIdentity.Identity.IdentityData, identityType)
+			if !gotIdentity.Equal(want) {
+				t.Errorf("identity after import by identity\nwant: %s\ngot:  %s", want, gotIdentity)
+			}
+
+			var state map[string]tftypes.Value
+
+			err = unmarshalValue(t, byIdentity.State, stateType).As(&state)
+			if err != nil {
+				t.Fatalf("As() error: %v", err)
+			}
+
+			var id string
+
+			err = state["id"].As(&id)
+			if err != nil {
+				t.Fatalf("As() error: %v", err)
+			}
+
+			assert.Equal(t, tt.id, id)
+		})
+	}
+}
+
+func importResource(
+	t *testing.T,
+	providerServer tfprotov6.ProviderServer,
+	req *tfprotov6.ImportResourceStateRequest,
+) *tfprotov6.ImportedResource {
+	t.Helper()
+
+	resp, err := providerServer.ImportResourceState(context.Background(), req)
+	if err != nil {
+		t.Fatalf("ImportResourceState() error: %v", err)
+	}
+
+	for _, diagnostic := range resp.Diagnostics {
+		t.Fatalf("unexpected diagnostic: %s: %s", diagnostic.Summary, diagnostic.Detail)
+	}
+
+	if len(resp.ImportedResources) != 1 || resp.ImportedResources[0].Identity == nil {
+		t.Fatalf("ImportResourceState() returned %d resources without identity", len(resp.ImportedResources))
+	}
+
+	return resp.ImportedResources[0]
+}
+
+func unmarshalValue(t *testing.T, value *tfprotov6.DynamicValue, valueType tftypes.Type) tftypes.Value {
+	t.Helper()
+
+	unmarshaled, err := value.Unmarshal(valueType)
+	if err != nil {
+		t.Fatalf("Unmarshal() error: %v", err)
+	}
+
+	return unmarshaled
+}
+
+func TestListResource(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	responses := map[string]string{
+		"/server": `[` +
+			`{"server":{"server_ip":"1.2.3.4","server_number":1,"server_name":"web-1","dc":"FSN1-DC14"}},` +
+			`{"server":{"server_ip":"5.6.7.8","server_number":2,"server_name":"db-1","dc":"HEL1-DC2"}},` +
+			`{"server":{"server_ip":"9.8.7.6","server_number":3,"server_name":"old-1","dc":"FSN1-DC14","cancelled":true}}]`,
+		"/key": `[{"key":{"name":"laptop","fingerprint":"c3:c9:0a:0f:b8:2e:b4:51:9f:5a:39:24:db:ae:16:95",` +
+			`"type":"ED25519","size":256,"data":"ssh-ed25519 AAAA","created_at":"2024-01-01 00:00:00"}}]`,
+		"/failover": `[{"failover":{"ip":"9.9.9.9","netmask":"255.255.255.255","server_ip":"5.6.7.8",` +
+			`"server_number":2,"active_server_ip":"1.2.3.4"}}]`,
+		"/vswitch": `[{"id":4321,"name":"private","vlan":4000,"cancelled":false},` +
+			`{"id":4322,"name":"gone","vlan":4001,"cancelled":true}]`,
+		"/vswitch/4321": `{"id":4321,"name":"private","vlan":4000,"cancelled":false,` +
+			`"server":[{"server_number":1,"server_ip":"1.2.3.4","status":"ready"}],"subnets":[],"cloud_networks":[]}`,
+		"/firewall/1.2.3.4": `{"firewall":{"server_ip":"1.2.3.4","status":"active",` +
+			`"rules":{"input":[{"ip_version":"ipv4","name":"ssh","dst_port":"22","action":"accept"}]}}}`,
+	}
+
+	robot := httptest.NewServer(
+		http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+			body, ok := responses[req.URL.Path]
+			if !ok {
+				http.NotFound(writer, req)
+
+				return
+			}
+
+			_, _ = writer.Write([]byte(body))
+		}),
+	)
+	t.Cleanup(robot.Close)
+
+	providerServer, err := hetznerrobot.ProviderServer(ctx)
+	if err != nil {
+		t.Fatalf("ProviderServer() error: %v", err)
+	}
+
+	//exhaustruct:ignore
+	schemas, err := providerServer().GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatalf("GetProviderSchema() error: %v", err)
+	}
+
+	listServer, ok := providerServer().(tfprotov6.ProviderServerWithListResource)
+	if !ok {
+		t.Fatalf("provider server %T does not serve list resources", providerServer())
+	}
+
+	configureProvider(t, providerServer(), schemas.Provider, map[string]any{
+		"username":                    "foo",
+		"password":                    "bar",
+		"url":                         robot.URL,
+		"skip_credentials_validation": true,
+	})
+
+	tests := []struct {
+		name            string
+		resourceType    string
+		config          map[string]any
+		includeResource bool
+		want            []string
+		wantResource    map[string]any
+	}{
+		{
+			name:         "firewall",
+			resourceType: firewall.ResourceType,
+			config:       map[string]any{},
+			want:         []string{"web-1", "db-1"},
+		},
+		{
+			name:            "firewall by datacenter",
+			resourceType:    firewall.ResourceType,
+			config:          map[string]any{"datacenter": "fsn1"},
+			includeResource: true,
+			want:            []string{"web-1"},
+			wantResource:    map[string]any{"server_id": "1", "server_ip": "1.2.3.4"},
+		},
+		{
+			name:         "firewall by name",
+			resourceType: firewall.ResourceType,
+			config:       map[string]any{"name": "db-*"},
+			want:         []string{"db-1"},
+		},
+		{
+			name:            "server",
+			resourceType:    server.ResourceType,
+			config:          map[string]any{"name": "web-*"},
+			includeResource: true,
+			want:            []string{"web-1"},
+			wantResource:    map[string]any{"server_id": "1", "server_name": "web-1", "datacenter": "FSN1-DC14"},
+		},
+		{
+			name:         "server by datacenter",
+			resourceType: server.ResourceType,
+			config:       map[string]any{"datacenter": "HEL1"},
+			want:         []string{"db-1"},
+		},
+		{
+			name:            "ssh key",
+			resourceType:    sshkey.ResourceType,
+			config:          map[string]any{},
+			includeResource: true,
+			want:            []string{"laptop"},
+			wantResource:    map[string]any{"fingerprint": "c3:c9:0a:0f:b8:2e:b4:51:9f:5a:39:24:db:ae:16:95"},
+		},
+		{
+			name:            "failover",
+			resourceType:    failover.ResourceType,
+			config:          map[string]any{"datacenter": "HEL1"},
+			includeResource: true,
+			want:            []string{"9.9.9.9"},
+			wantResource:    map[string]any{"active_server_ip": "1.2.3.4", "server_ip": "5.6.7.8"},
+		},
+		{
+			name:         "failover by other datacenter",
+			resourceType: failover.ResourceType,
+			config:       map[string]any{"datacenter": "FSN1"},
+			want:         []string{},
+		},
+		{
+			name:            "vswitch",
+			resourceType:    vswitch.ResourceType,
+			config:          map[string]any{"datacenter": "FSN1"},
+			includeResource: true,
+			want:            []string{"private"},
+			wantResource:    map[string]any{"id": "4321", "name": "private"},
+		},
+		{
+			name:         "vswitch by name",
+			resourceType: vswitch.ResourceType,
+			config:       map[string]any{"name": "public"},
+			want:         []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			listSchema := schemas.ListResourceSchemas[tt.resourceType]
+			if listSchema == nil {
+				t.Fatalf("no list resource schema for %s", tt.resourceType)
+			}
+
+			//exhaustruct:ignore
+			stream, err := listServer.ListResource(ctx, &tfprotov6.ListResourceRequest{
+				TypeName:        tt.resourceType,
+				Config:          dynamicValue(t, listSchema, tt.config),
+				IncludeResource: tt.includeResource,
+			})
+			if err != nil {
+				t.Fatalf("ListResource() error: %v", err)
+			}
+
+			got := []string{}
+
+			for result := range stream.Results {
+				for _, diagnostic := range result.Diagnostics {
+					t.Fatalf("unexpected diagnostic: %s: %s", diagnostic.Summary, diagnostic.Detail)
+				}
+
+				if result.Identity == nil {
+					t.Fatalf("result %q has no identity", result.DisplayName)
+				}
+
+				got = append(got, result.DisplayName)
+
+				if !tt.includeResource {
+					continue
+				}
+
+				var state map[string]tftypes.Value
+
+				stateType := schemas.ResourceSchemas[tt.resourceType].ValueType()
+
+				err = unmarshalValue(t, result.Resource, stateType).As(&state)
+				if err != nil {
+					t.Fatalf("As() error: %v", err)
+				}
+
+				for key, want := range tt.wantResource {
+					assert.True(t, state[key].Equal(tftypes.NewValue(tftypes.String, want)), "%s = %s", key, state[key])
+				}
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// configureProvider configures the provider with the given attributes, the
+// others being null.
+func configureProvider(
+	t *testing.T,
+	providerServer tfprotov6.ProviderServer,
+	providerSchema *tfprotov6.Schema,
+	attributes map[string]any,
+) {
+	t.Helper()
+
+	//exhaustruct:ignore
+	resp, err := providerServer.ConfigureProvider(context.Background(), &tfprotov6.ConfigureProviderRequest{
+		TerraformVersion: "1.14.0",
+		Config:           dynamicValue(t, providerSchema, attributes),
+	})
+	if err != nil {
+		t.Fatalf("ConfigureProvider() error: %v", err)
+	}
+
+	for _, diagnostic := range resp.Diagnostics {
+		if diagnostic.Severity == tfprotov6.DiagnosticSeverityError {
+			t.Fatalf("unexpected diagnostic: %s: %s", diagnostic.Summary, diagnostic.Detail)
+		}
+	}
+}
+
+// dynamicValue returns a value of the schema with the given attributes, the
+// others being null.
+func dynamicValue(t *testing.T, valueSchema *tfprotov6.Schema, attributes map[string]any) *tfprotov6.DynamicValue {
+	t.Helper()
+
+	objectType := valueSchema.ValueType().(tftypes.Object)
+
+	values := make(map[string]tftypes.Value, len(objectType.AttributeTypes))
+	for key, attributeType := range objectType.AttributeTypes {
+		values[key] = tftypes.NewValue(attributeType, attributes[key])
+	}
+
+	value, err := tfprotov6.NewDynamicValue(objectType, tftypes.NewValue(objectType, values))
+	if err != nil {
+		t.Fatalf("NewDynamicValue() error: %v", err)
+	}
+
+	return &value
+}
+
+func TestInvokeAction(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	var (
+		mu       sync.Mutex
+		requests []string
+		// Active server of the failover IPs, changed by POST requests.
+		routes = map[string]string{"9.9.9.9": "1.2.3.4", "8.8.8.8": "5.6.7.8"}
+	)
+
+	robot := httptest.NewServer(
+		http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			err := req.ParseForm()
+			if err != nil {
+				t.Errorf("ParseForm() error: %v", err)
+			}
+
+			if req.Method == http.MethodPost {
+				requests = append(requests, req.URL.Path+"?"+req.PostForm.Encode())
+			}
+
+			switch {
+			case strings.HasPrefix(req.URL.Path, "/failover/"):
+				ip := strings.TrimPrefix(req.URL.Path, "/failover/")
+				if req.Method == http.MethodPost {
+					routes[ip] = req.PostForm.Get("active_server_ip")
+				}
+
+				_, _ = fmt.Fprintf(writer, `{"failover":{"ip":%q,"active_server_ip":%q}}`, ip, routes[ip])
+			case req.URL.Path == "/boot/1/rescue":
+				_, _ = writer.Write([]byte(`{"rescue":{"server_ip":"1.2.3.4","active":true,"password":null}}`))
+			case req.URL.Path == "/reset/1", req.URL.Path == "/wol/1":
+				_, _ = writer.Write([]byte(`{}`))
+			default:
+				http.NotFound(writer, req)
+			}
+		}),
+	)
+	t.Cleanup(robot.Close)
+
+	providerServer, err := hetznerrobot.ProviderServer(ctx)
+	if err != nil {
+		t.Fatalf("ProviderServer() error: %v", err)
+	}
+
+	actionServer, ok := providerServer().(tfprotov6.ProviderServerWithActions)
+	if !ok {
+		t.Fatalf("provider server %T does not serve actions", providerServer())
+	}
+
+	//exhaustruct:ignore
+	schemas, err := providerServer().GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatalf("GetProviderSchema() error: %v", err)
+	}
+
+	configureProvider(t, providerServer(), schemas.Provider, map[string]any{
+		"username":                    "foo",
+		"password":                    "bar",
+		"url":                         robot.URL,
+		"skip_credentials_validation": true,
+	})
+
+	tests := []struct {
+		actionType   string
+		config       map[string]any
+		wantRequests []string
+		wantProgress []string
+	}{
+		{
+			actionType:   failover.ActionSwitchType,
+			config:       map[string]any{"ip": "9.9.9.9", "active_server_ip": "5.6.7.8"},
+			wantRequests: []string{"/failover/9.9.9.9?active_server_ip=5.6.7.8"},
+			wantProgress: []string{"Routing failover 9.9.9.9 from 1.2.3.4 to 5.6.7.8"},
+		},
+		{
+			actionType:   failover.ActionSwitchType,
+			config:       map[string]any{"ip": "8.8.8.8", "active_server_ip": "5.6.7.8"},
+			wantRequests: nil,
+			wantProgress: []string{"Failover 8.8.8.8 already routes to 5.6.7.8"},
+		},
+		{
+			actionType:   server.ActionResetType,
+			config:       map[string]any{"server_id": "1", "type": "sw"},
+			wantRequests: []string{"/reset/1?type=sw"},
+			wantProgress: []string{"Sending sw reset to server 1"},
+		},
+		{
+			actionType:   server.ActionRescueType,
+			config:       map[string]any{"server_id": "1", "reboot": false},
+			wantRequests: []string{"/boot/1/rescue?os=linux"},
+			wantProgress: []string{"Activating the linux rescue system of server 1"},
+		},
+		{
+			actionType:   server.ActionWakeType,
+			config:       map[string]any{"server_id": "1"},
+			wantRequests: []string{"/wol/1?"},
+			wantProgress: []string{"Sending Wake on LAN to server 1"},
+		},
+	}
+
+	// Run in sequence, the requests are recorded in a single list.
+	for _, tt := range tests {
+		mu.Lock()
+		requests = nil
+		mu.Unlock()
+
+		actionSchema := schemas.ActionSchemas[tt.actionType]
+		if actionSchema == nil {
+			t.Fatalf("no action schema for %s", tt.actionType)
+		}
+
+		//exhaustruct:ignore
+		stream, err := actionServer.InvokeAction(ctx, &tfprotov6.InvokeActionRequest{
+			ActionType: tt.actionType,
+			Config:     dynamicValue(t, actionSchema.Schema, tt.config),
+		})
+		if err != nil {
+			t.Fatalf("InvokeAction(%s) error: %v", tt.actionType, err)
+		}
+
+		var progress []string
+
+		for event := range stream.Events {
+			switch eventType := event.Type.(type) {
+			case tfprotov6.ProgressInvokeActionEventType:
+				progress = append(progress, eventType.Message)
+			case tfprotov6.CompletedInvokeActionEventType:
+				for _, diagnostic := range eventType.Diagnostics {
+					t.Errorf("%s: unexpected diagnostic: %s: %s", tt.actionType, diagnostic.Summary, diagnostic.Detail)
+				}
+			}
+		}
+
+		mu.Lock()
+		assert.Equal(t, tt.wantRequests, requests, tt.actionType)
+		mu.Unlock()
+
+		assert.Equal(t, tt.wantProgress, progress, tt.actionType)
+	}
+}
+
+func TestOpenEphemeralResource(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	var (
+		mu    sync.Mutex
+		posts []string
+	)
+
+	// The rescue system of server 1 is already active, the one of server 2
+	// is activated.
+	robot := httptest.NewServer(
+		http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			err := req.ParseForm()
+			if err != nil {
+				t.Errorf("ParseForm() error: %v", err)
+			}
+
+			if req.Method == http.MethodPost {
+				posts = append(posts, req.URL.Path+"?"+req.PostForm.Encode())
+			}
+
+			switch {
+			case req.URL.Path == "/boot/1/rescue" && req.Method == http.MethodPost:
+				writer.WriteHeader(http.StatusConflict)
+				_, _ = writer.Write([]byte(
+					`{"error":{"status":409,"code":"BOOT_ALREADY_ENABLED","message":"already enabled"}}`,
+				))
+			case req.URL.Path == "/boot/1/rescue":
+				_, _ = writer.Write([]byte(
+					`{"rescue":{"server_ip":"1.2.3.4","os":"linux","active":true,"password":"s3cret"}}`,
+				))
+			case req.URL.Path == "/boot/2/rescue":
+				_, _ = writer.Write([]byte(
+					`{"rescue":{"server_ip":"5.6.7.8","os":"linux","active":true,"password":"n3w"}}`,
+				))
+			default:
+				http.NotFound(writer, req)
+			}
+		}),
+	)
+	t.Cleanup(robot.Close)
+
+	providerServer, err := hetznerrobot.ProviderServer(ctx)
+	if err != nil {
+		t.Fatalf("ProviderServer() error: %v", err)
+	}
+
+	//exhaustruct:ignore
+	schemas, err := providerServer().GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatalf("GetProviderSchema() error: %v", err)
+	}
+
+	configureProvider(t, providerServer(), schemas.Provider, map[string]any{
+		"username":                    "foo",
+		"password":                    "bar",
+		"url":                         robot.URL,
+		"skip_credentials_validation": true,
+	})
+
+	ephemeralSchema := schemas.EphemeralResourceSchemas[server.EphemeralRescueCredentialsType]
+
+	open := func(serverID string) map[string]tftypes.Value {
+		t.Helper()
+
+		//exhaustruct:ignore
+		resp, err := providerServer().OpenEphemeralResource(ctx, &tfprotov6.OpenEphemeralResourceRequest{
+			TypeName: server.EphemeralRescueCredentialsType,
+			Config:   dynamicValue(t, ephemeralSchema, map[string]any{"server_id": serverID}),
+		})
+		if err != nil {
+			t.Fatalf("OpenEphemeralResource() error: %v", err)
+		}
+
+		for _, diagnostic := range resp.Diagnostics {
+			t.Fatalf("unexpected diagnostic: %s: %s", diagnostic.Summary, diagnostic.Detail)
+		}
+
+		var attributes map[string]tftypes.Value
+
+		err = unmarshalValue(t, resp.Result, ephemeralSchema.ValueType()).As(&attributes)
+		if err != nil {
+			t.Fatalf("As() error: %v", err)
+		}
+
+		return attributes
+	}
+
+	// Opened during plan and again during apply, the active rescue system is
+	// reused.
+	for range 2 {
+		attributes := open("1")
+		assert.True(t, attributes["ssh_password"].Equal(tftypes.NewValue(tftypes.String, "s3cret")))
+	}
+
+	attributes := open("2")
+	assert.True(t, attributes["ssh_password"].Equal(tftypes.NewValue(tftypes.String, "n3w")))
+	assert.True(t, attributes["ip"].Equal(tftypes.NewValue(tftypes.String, "5.6.7.8")))
+
+	mu.Lock()
+	assert.Equal(t, []string{"/boot/1/rescue?os=linux", "/boot/1/rescue?os=linux", "/boot/2/rescue?os=linux"}, posts)
+	mu.Unlock()
+}
+
+// TestFirewallPreviousFirewall checks that the firewall found on import, or
+// on the new server when the firewall moves in place, is kept in private
+// state and restored by on_destroy = "restore_previous".
+func TestFirewallPreviousFirewall(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	var (
+		mu    sync.Mutex
+		posts []string
+	)
+
+	robot := httptest.NewServer(
+		http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			switch req.URL.Path {
+			case "/server":
+				_, _ = writer.Write([]byte(`[{"server":{"server_ip":"1.2.3.4","server_number":1}}]`))
+			case "/server/1":
+				_, _ = writer.Write([]byte(`{"server":{"server_ip":"1.2.3.4","server_number":1}}`))
+			case "/server/2":
+				_, _ = writer.Write([]byte(`{"server":{"server_ip":"5.6.7.8","server_number":2}}`))
+			case "/firewall/1.2.3.4", "/firewall/5.6.7.8":
+				if req.Method == http.MethodPost {
+					_ = req.ParseForm()
+					posts = append(posts, req.URL.Path+"?"+req.PostForm.Encode())
+				}
+
+				// Each server starts with its own rule.
+				name := map[string]string{"/firewall/1.2.3.4": "ssh", "/firewall/5.6.7.8": "web"}[req.URL.Path]
+				_, _ = fmt.Fprintf(writer, `{"firewall":{"status":"active","rules":{"input":[`+
+					`{"ip_version":"ipv4","name":%q,"dst_port":"22","action":"accept"}]}}}`, name)
+			default:
+				http.NotFound(writer, req)
+			}
+		}),
+	)
+	t.Cleanup(robot.Close)
+
+	providerServer, err := hetznerrobot.ProviderServer(ctx)
+	if err != nil {
+		t.Fatalf("ProviderServer() error: %v", err)
+	}
+
+	//exhaustruct:ignore
+	schemas, err := providerServer().GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatalf("GetProviderSchema() error: %v", err)
+	}
+
+	//exhaustruct:ignore
+	identitySchemas, err := providerServer().GetResourceIdentitySchemas(
+		ctx,
+		&tfprotov6.GetResourceIdentitySchemasRequest{},
+	)
+	if err != nil {
+		t.Fatalf("GetResourceIdentitySchemas() error: %v", err)
+	}
+
+	configureProvider(t, providerServer(), schemas.Provider, map[string]any{
+		"username":                    "foo",
+		"password":                    "bar",
+		"url":                         robot.URL,
+		"skip_credentials_validation": true,
+	})
+
+	stateType := schemas.ResourceSchemas[firewall.ResourceType].ValueType()
+	identityType := identitySchemas.IdentitySchemas[firewall.ResourceType].ValueType()
+
+	identityData, err := tfprotov6.NewDynamicValue(identityType, tftypes.NewValue(identityType, map[string]tftypes.Value{
+		"server_id": tftypes.NewValue(tftypes.String, "1"),
+	}))
+	if err != nil {
+		t.Fatalf("NewDynamicValue() error: %v", err)
+	}
+
+	var imported *tfprotov6.ImportedResource
+
+	// The firewall may be imported by server number, main IP or identity.
+	//exhaustruct:ignore
+	for _, req := range []*tfprotov6.ImportResourceStateRequest{
+		{TypeName: firewall.ResourceType, ID: "1"},
+		{TypeName: firewall.ResourceType, ID: "1.2.3.4"},
+		{TypeName: firewall.ResourceType, Identity: &tfprotov6.ResourceIdentityData{IdentityData: &identityData}},
+	} {
+		imported = importResource(t, providerServer(), req)
+
+		assert.Contains(t, string(imported.Private), `"previous_firewall"`)
+		assert.True(t, unmarshalValue(t, imported.Identity.IdentityData, identityType).Equal(
+			unmarshalValue(t, &identityData, identityType),
+		))
+	}
+
+	var state map[string]tftypes.Value
+
+	err = unmarshalValue(t, imported.State, stateType).As(&state)
+	if err != nil {
+		t.Fatalf("As() error: %v", err)
+	}
+
+	assert.NotContains(t, state, "previous_firewall")
+
+	// Moving the firewall to server 2 is an in-place update.
+	attributeTypes := stateType.(tftypes.Object).AttributeTypes
+	proposed := maps.Clone(state)
+	proposed["server_id"] = tftypes.NewValue(tftypes.String, "2")
+	proposed["on_destroy"] = tftypes.NewValue(tftypes.String, "restore_previous")
+
+	config := maps.Clone(proposed)
+	for _, key := range []string{"id", "server_ip", "effective_rule", "compact"} {
+		config[key] = tftypes.NewValue(attributeTypes[key], nil)
+	}
+
+	priorState := newDynamicValue(t, stateType, state)
+	proposedState := newDynamicValue(t, stateType, proposed)
+	configValue := newDynamicValue(t, stateType, config)
+
+	//exhaustruct:ignore
+	planResp, err := providerServer().PlanResourceChange(ctx, &tfprotov6.PlanResourceChangeRequest{
+		TypeName:         firewall.ResourceType,
+		PriorState:       priorState,
+		ProposedNewState: proposedState,
+		Config:           configValue,
+		PriorPrivate:     imported.Private,
+		PriorIdentity:    imported.Identity,
+	})
+	if err != nil {
+		t.Fatalf("PlanResourceChange() error: %v", err)
+	}
+
+	for _, diagnostic := range planResp.Diagnostics {
+		t.Errorf("unexpected diagnostic: %s: %s", diagnostic.Summary, diagnostic.Detail)
+	}
+
+	assert.Empty(t, planResp.RequiresReplace)
+
+	//exhaustruct:ignore
+	applyResp, err := providerServer().ApplyResourceChange(ctx, &tfprotov6.ApplyResourceChangeRequest{
+		TypeName:        firewall.ResourceType,
+		PriorState:      priorState,
+		PlannedState:    planResp.PlannedState,
+		Config:          configValue,
+		PlannedPrivate:  planResp.PlannedPrivate,
+		PlannedIdentity: planResp.PlannedIdentity,
+	})
+	if err != nil {
+		t.Fatalf("ApplyResourceChange() error: %v", err)
+	}
+
+	for _, diagnostic := range applyResp.Diagnostics {
+		t.Errorf("unexpected diagnostic: %s: %s", diagnostic.Summary, diagnostic.Detail)
+	}
+
+	err = unmarshalValue(t, applyResp.NewState, stateType).As(&state)
+	if err != nil {
+		t.Fatalf("As() error: %v", err)
+	}
+
+	assert.True(t, state["id"].Equal(tftypes.NewValue(tftypes.String, "2")))
+	assert.True(t, state["server_ip"].Equal(tftypes.NewValue(tftypes.String, "5.6.7.8")))
+
+	nullState := newDynamicValue(t, stateType, nil)
+
+	//exhaustruct:ignore
+	destroyResp, err := providerServer().ApplyResourceChange(ctx, &tfprotov6.ApplyResourceChangeRequest{
+		TypeName:       firewall.ResourceType,
+		PriorState:     applyResp.NewState,
+		PlannedState:   nullState,
+		Config:         nullState,
+		PlannedPrivate: applyResp.Private,
+	})
+	if err != nil {
+		t.Fatalf("ApplyResourceChange() error: %v", err)
+	}
+
+	for _, diagnostic := range destroyResp.Diagnostics {
+		t.Errorf("unexpected diagnostic: %s: %s", diagnostic.Summary, diagnostic.Detail)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	// The rules of the resource, then the rules found on server 2 restored.
+	if assert.Len(t, posts, 2) {
+		assert.Contains(t, posts[0], "/firewall/5.6.7.8?")
+		assert.Contains(t, posts[0], "rules%5Binput%5D%5B0%5D%5Bname%5D=ssh")
+		assert.Contains(t, posts[1], "/firewall/5.6.7.8?")
+		assert.Contains(t, posts[1], "rules%5Binput%5D%5B0%5D%5Bname%5D=web")
+	}
+}
+
+// newDynamicValue returns the dynamic value of an object with the given
+// attributes, or null.
+func newDynamicValue(
+	t *testing.T,
+	objectType tftypes.Type,
+	attributes map[string]tftypes.Value,
+) *tfprotov6.DynamicValue {
+	t.Helper()
+
+	var raw any
+	if attributes != nil {
+		raw = attributes
+	}
+
+	value, err := tfprotov6.NewDynamicValue(objectType, tftypes.NewValue(objectType, raw))
+	if err != nil {
+		t.Fatalf("NewDynamicValue() error: %v", err)
+	}
+
+	return &value
+}
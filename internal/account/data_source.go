@@ -0,0 +1,81 @@
+// Package account defines the account terraform datasource.
+package account
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/client"
+)
+
+// DataSourceType is the type name of the Hetzner Robot account datasource.
+const DataSourceType = "hetznerrobot_account"
+
+// DataSource defines the account terraform datasource, summarizing what the
+// provider credentials can see.
+func DataSource() *schema.Resource {
+	return &schema.Resource{
+		Description: "What the provider credentials can see in the Robot account.",
+		ReadContext: dataSourceRead,
+		Schema: map[string]*schema.Schema{
+			"username": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Robot webservice username in use.",
+			},
+			"base_url": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Base URL of the Robot API in use.",
+			},
+			"server_count": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Number of servers of the account.",
+			},
+			"vswitch_count": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Number of vSwitches of the account.",
+			},
+			"ssh_key_count": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Number of SSH keys of the account.",
+			},
+		},
+	}
+}
+
+func dataSourceRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	hClient, ok := meta.(*client.HetznerRobotClient)
+	if !ok {
+		return diag.Errorf("invalid client type")
+	}
+
+	account, err := hClient.FetchAccount(ctx)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("failed to fetch account: %w", err))
+	}
+
+	attributes := map[string]any{
+		"username":      account.Username,
+		"base_url":      account.BaseURL,
+		"server_count":  account.ServerCount,
+		"vswitch_count": account.VSwitchCount,
+		"ssh_key_count": account.SSHKeyCount,
+	}
+
+	for key, value := range attributes {
+		err = d.Set(key, value)
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("error setting %s attribute: %w", key, err))
+		}
+	}
+
+	d.SetId(account.Username)
+
+	return nil
+}
@@ -0,0 +1,112 @@
+// Package cli holds what the command-line tools share with the provider: the
+// client settings, taken from flags and the provider environment variables,
+// and the credential chain.
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/client"
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/credentials"
+)
+
+const defaultTimeout = 60 * time.Second
+
+// Config holds the client settings of a command.
+type Config struct {
+	URL                string
+	CredentialsFile    string
+	CredentialsCommand string
+	Profile            string
+	Timeout            time.Duration
+	HTTPProxy          string
+	CACertFile         string
+}
+
+// RegisterFlags adds the client flags to fs, defaulting to the environment
+// variables of the provider.
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.URL, "url", envDefault("HETZNERROBOT_URL", client.DefaultBaseURL),
+		"Base URL for the Hetzner Robot API (HETZNERROBOT_URL)")
+	fs.StringVar(&c.CredentialsFile, "credentials-file", os.Getenv("HETZNERROBOT_CREDENTIALS_FILE"),
+		"INI or JSON file holding the credentials (HETZNERROBOT_CREDENTIALS_FILE)")
+	fs.StringVar(&c.Profile, "profile", envDefault("HETZNERROBOT_PROFILE", "default"),
+		"Profile of the credentials file (HETZNERROBOT_PROFILE)")
+	fs.StringVar(&c.CredentialsCommand, "credentials-command", "",
+		`Command printing the credentials as {"username": "...", "password": "..."}`)
+	fs.DurationVar(&c.Timeout, "timeout", envDuration("HETZNERROBOT_REQUEST_TIMEOUT", defaultTimeout),
+		"Timeout of each request (HETZNERROBOT_REQUEST_TIMEOUT)")
+	fs.StringVar(&c.HTTPProxy, "http-proxy", os.Getenv("HETZNERROBOT_HTTP_PROXY"),
+		"Proxy URL, overriding the one of the environment (HETZNERROBOT_HTTP_PROXY)")
+	fs.StringVar(&c.CACertFile, "ca-cert-file", "",
+		"File of PEM encoded CA certificates trusted on top of the system ones")
+}
+
+// Client resolves the credentials like the provider and returns a client.
+// Usernames and passwords are only read from the environment and files, so
+// they never show up in the process list.
+func (c *Config) Client(ctx context.Context) (*client.HetznerRobotClient, error) {
+	creds, err := credentials.Resolve(ctx, credentials.Chain(credentials.Options{
+		Name:     "command line",
+		Username: "",
+		Password: "",
+		Command:  strings.Fields(c.CredentialsCommand),
+		File:     c.CredentialsFile,
+		Profile:  c.Profile,
+		URL:      c.URL,
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("error resolving credentials: %w", err)
+	}
+
+	var caCertPEM string
+
+	if c.CACertFile != "" {
+		data, err := os.ReadFile(c.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading CA certificate file: %w", err)
+		}
+
+		caCertPEM = string(data)
+	}
+
+	//exhaustruct:ignore
+	hClient, err := client.New(&client.ProviderConfig{
+		Username:  creds.Username,
+		Password:  creds.Password,
+		BaseURL:   c.URL,
+		Timeout:   c.Timeout,
+		HTTPProxy: c.HTTPProxy,
+		CACertPEM: caCertPEM,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating client: %w", err)
+	}
+
+	return hClient, nil
+}
+
+// envDefault returns the environment variable key, or fallback if unset.
+func envDefault(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+
+	return fallback
+}
+
+// envDuration returns the duration of the environment variable key, or
+// fallback if unset or invalid.
+func envDuration(key string, fallback time.Duration) time.Duration {
+	duration, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+
+	return duration
+}
@@ -0,0 +1,145 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+var (
+	// ErrUnauthorized is returned when the Robot API rejects the credentials.
+	ErrUnauthorized = errors.New("robot API rejected the credentials")
+	// ErrForbidden is returned when the Robot API refuses the credentials
+	// access, e.g. when the client IP is blocked after failed logins.
+	ErrForbidden = errors.New("robot API access forbidden")
+)
+
+// rateLimitExceededCode is the Robot error code of a 403 caused by the request
+// quota, not by the credentials.
+const rateLimitExceededCode = "RATE_LIMIT_EXCEEDED"
+
+// validatedCredentials caches the result of ValidateCredentials per base URL
+// and credentials, as the provider is configured more than once per run.
+//
+//nolint:gochecknoglobals
+var validatedCredentials sync.Map
+
+// credentialsCheck is the cached result of a credentials validation.
+type credentialsCheck struct {
+	once sync.Once
+	err  error
+}
+
+// ValidateCredentials issues a cheap authenticated request, returning an
+// error wrapping ErrUnauthorized or ErrForbidden when the credentials are
+// refused. The result is cached for the lifetime of the process.
+func (c *HetznerRobotClient) ValidateCredentials(ctx context.Context) error {
+	key := sha256.Sum256(
+		[]byte(c.Config.BaseURL + "\x00" + c.Config.Username + "\x00" + c.Config.Password),
+	)
+
+	value, _ := validatedCredentials.LoadOrStore(key, &credentialsCheck{once: sync.Once{}, err: nil})
+
+	check, ok := value.(*credentialsCheck)
+	if !ok {
+		return errors.New("unexpected credentials cache entry")
+	}
+
+	check.once.Do(func() {
+		_, check.err = c.countItems(ctx, "/server")
+	})
+
+	// Transient failures are retried by the next configure.
+	if check.err != nil && !errors.Is(check.err, ErrUnauthorized) &&
+		!errors.Is(check.err, ErrForbidden) {
+		validatedCredentials.CompareAndDelete(key, check)
+	}
+
+	return check.err
+}
+
+// Account summarizes what the credentials can see.
+type Account struct {
+	Username     string
+	BaseURL      string
+	ServerCount  int
+	VSwitchCount int
+	SSHKeyCount  int
+}
+
+// FetchAccount counts the servers, vSwitches and SSH keys of the account.
+func (c *HetznerRobotClient) FetchAccount(ctx context.Context) (Account, error) {
+	account := Account{
+		Username:     c.Config.Username,
+		BaseURL:      c.Config.BaseURL,
+		ServerCount:  0,
+		VSwitchCount: 0,
+		SSHKeyCount:  0,
+	}
+
+	var err error
+
+	account.ServerCount, err = c.countItems(ctx, "/server")
+	if err != nil {
+		return account, err
+	}
+
+	account.VSwitchCount, err = c.countItems(ctx, "/vswitch")
+	if err != nil {
+		return account, err
+	}
+
+	account.SSHKeyCount, err = c.countItems(ctx, "/key")
+	if err != nil {
+		return account, err
+	}
+
+	return account, nil
+}
+
+// countItems returns the length of the list at path. Robot answers 404 when
+// the list is empty.
+func (c *HetznerRobotClient) countItems(ctx context.Context, path string) (int, error) {
+	resp, err := c.cachedGet(ctx, path)
+	if err != nil {
+		return 0, fmt.Errorf("GET %s request error: %w", path, err)
+	}
+
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("unable to read response body: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		return 0, fmt.Errorf("%w: GET %s: status %d", ErrUnauthorized, path, resp.StatusCode)
+	case resp.StatusCode == http.StatusForbidden && robotErrorCode(data) != rateLimitExceededCode:
+		return 0, fmt.Errorf(
+			"%w: GET %s: status %d, body %s",
+			ErrForbidden,
+			path,
+			resp.StatusCode,
+			data,
+		)
+	case resp.StatusCode == http.StatusNotFound:
+		return 0, nil
+	case resp.StatusCode != http.StatusOK:
+		return 0, fmt.Errorf("GET %s: status %d, body %s", path, resp.StatusCode, data)
+	}
+
+	var items []json.RawMessage
+
+	err = json.Unmarshal(data, &items)
+	if err != nil {
+		return 0, fmt.Errorf("GET %s decode error: %w", path, err)
+	}
+
+	return len(items), nil
+}
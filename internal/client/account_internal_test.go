@@ -0,0 +1,124 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestValidateCredentials(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		wantErr   error
+		wantCalls int32
+	}{
+		{name: "Valid", status: http.StatusOK, body: `[]`, wantErr: nil, wantCalls: 1},
+		{
+			name:      "No server",
+			status:    http.StatusNotFound,
+			body:      `{"error":{"status":404,"code":"NOT_FOUND","message":"Not found"}}`,
+			wantErr:   nil,
+			wantCalls: 1,
+		},
+		{
+			name:      "Unauthorized",
+			status:    http.StatusUnauthorized,
+			body:      `{"error":{"status":401,"code":"UNAUTHORIZED","message":"Unauthorized"}}`,
+			wantErr:   ErrUnauthorized,
+			wantCalls: 1,
+		},
+		{
+			name:      "Forbidden",
+			status:    http.StatusForbidden,
+			body:      `{"error":{"status":403,"code":"FORBIDDEN","message":"Forbidden"}}`,
+			wantErr:   ErrForbidden,
+			wantCalls: 1,
+		},
+		{
+			// Not a credentials error, retried by the next call.
+			name:      "Rate limit",
+			status:    http.StatusForbidden,
+			body:      `{"error":{"status":403,"code":"RATE_LIMIT_EXCEEDED","message":"limit"}}`,
+			wantErr:   nil,
+			wantCalls: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var calls atomic.Int32
+
+			server := httptest.NewServer(
+				http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+					calls.Add(1)
+					writer.WriteHeader(tt.status)
+					_, _ = writer.Write([]byte(tt.body))
+				}),
+			)
+			defer server.Close()
+
+			client := newTestClient(t, &ProviderConfig{Username: "foo", Password: "bar", BaseURL: server.URL})
+
+			for range 2 {
+				err := client.ValidateCredentials(context.Background())
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Errorf("ValidateCredentials() error = %v, want %v", err, tt.wantErr)
+				}
+
+				if tt.wantErr == nil && (errors.Is(err, ErrUnauthorized) || errors.Is(err, ErrForbidden)) {
+					t.Errorf("ValidateCredentials() unexpected credentials error: %v", err)
+				}
+			}
+
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("requests: want %d, got %d", tt.wantCalls, got)
+			}
+		})
+	}
+}
+
+func TestFetchAccount(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(
+		http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+			switch req.URL.Path {
+			case "/server":
+				_, _ = writer.Write([]byte(`[{"server":{"server_number":1}},{"server":{"server_number":2}}]`))
+			case "/vswitch":
+				_, _ = writer.Write([]byte(`[{"id":4321}]`))
+			default:
+				writer.WriteHeader(http.StatusNotFound)
+				_, _ = writer.Write([]byte(`{"error":{"status":404,"code":"NOT_FOUND","message":"Not found"}}`))
+			}
+		}),
+	)
+	defer server.Close()
+
+	client := newTestClient(t, &ProviderConfig{Username: "foo", Password: "bar", BaseURL: server.URL})
+
+	got, err := client.FetchAccount(context.Background())
+	if err != nil {
+		t.Fatalf("FetchAccount() error: %v", err)
+	}
+
+	want := Account{
+		Username:     "foo",
+		BaseURL:      server.URL,
+		ServerCount:  2,
+		VSwitchCount: 1,
+		SSHKeyCount:  0,
+	}
+	if got != want {
+		t.Errorf("FetchAccount() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,152 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// cacheEntry is a successful GET response body kept until expires.
+type cacheEntry struct {
+	body    []byte
+	expires time.Time
+}
+
+// responseCache keeps the bodies of read-only GET requests for a TTL and
+// merges concurrent identical requests into a single one.
+type responseCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]cacheEntry
+	group   singleflight.Group
+	// generation is bumped by invalidate, so that responses fetched before
+	// a mutation are neither cached nor shared with later requests.
+	generation uint64
+}
+
+func newResponseCache(ttl time.Duration) *responseCache {
+	return &responseCache{
+		mu:         sync.Mutex{},
+		ttl:        ttl,
+		entries:    make(map[string]cacheEntry),
+		group:      singleflight.Group{},
+		generation: 0,
+	}
+}
+
+// get returns the cached body of path, along with the current generation.
+func (r *responseCache) get(path string, now time.Time) ([]byte, uint64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.entries[path]
+	if !ok || now.After(entry.expires) {
+		return nil, r.generation, false
+	}
+
+	return entry.body, r.generation, true
+}
+
+// set caches the body of path, unless the cache was invalidated since
+// generation.
+func (r *responseCache) set(path string, body []byte, generation uint64, now time.Time) {
+	if r.ttl <= 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if generation != r.generation {
+		return
+	}
+
+	r.entries[path] = cacheEntry{body: body, expires: now.Add(r.ttl)}
+}
+
+// invalidate drops every cached response. Called on any mutation, as changing
+// a resource can affect the listings of others (e.g. a vSwitch and its servers).
+func (r *responseCache) invalidate() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	clear(r.entries)
+	r.generation++
+}
+
+// cachedResponse is the result shared by the callers of a deduplicated GET.
+type cachedResponse struct {
+	status int
+	body   []byte
+}
+
+// cachedGet performs a read-only GET through the response cache. Only use it
+// for data that does not need to be polled, as it can be up to the provider
+// cache_ttl old. Responses other than 200 OK are not cached.
+//
+// The shared request outlives the caller that started it, bounded by the
+// client timeout, so that cancelling one caller does not fail the others;
+// each caller still returns as soon as its own context is done.
+func (c *HetznerRobotClient) cachedGet(ctx context.Context, path string) (*http.Response, error) {
+	body, generation, ok := c.cache.get(path, time.Now())
+	if ok {
+		return newCachedResponse(http.StatusOK, body), nil
+	}
+
+	key := fmt.Sprintf("%d %s", generation, path)
+
+	results := c.cache.group.DoChan(key, func() (any, error) {
+		fetchCtx := context.WithoutCancel(ctx)
+
+		if c.Config.Timeout > 0 {
+			var cancel context.CancelFunc
+
+			fetchCtx, cancel = context.WithTimeout(fetchCtx, c.Config.Timeout)
+			defer cancel()
+		}
+
+		resp, err := c.DoRequest(fetchCtx, "GET", path, nil, "")
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read response body: %w", err)
+		}
+
+		if resp.StatusCode == http.StatusOK {
+			c.cache.set(path, body, generation, time.Now())
+		}
+
+		return cachedResponse{status: resp.StatusCode, body: body}, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("GET %s: %w", path, ctx.Err())
+	case result := <-results:
+		if result.Err != nil {
+			return nil, fmt.Errorf("GET %s: %w", path, result.Err)
+		}
+
+		shared := result.Val.(cachedResponse)
+
+		return newCachedResponse(shared.status, shared.body), nil
+	}
+}
+
+func newCachedResponse(status int, body []byte) *http.Response {
+	//exhaustruct:ignore
+	return &http.Response{
+		StatusCode: status,
+		Body:       io.NopCloser(bytes.NewReader(body)),
+	}
+}
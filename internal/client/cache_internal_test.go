@@ -0,0 +1,193 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCachedGet(t *testing.T) {
+	t.Parallel()
+
+	var gets atomic.Int32
+
+	release := make(chan struct{})
+
+	server := httptest.NewServer(
+		http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+			if req.Method == http.MethodPost {
+				_, _ = writer.Write([]byte(`{"server":{"server_name":"foo"}}`))
+
+				return
+			}
+
+			gets.Add(1)
+			<-release
+
+			_, _ = writer.Write([]byte(`[{"server":{"server_number":1}}]`))
+		}),
+	)
+	defer server.Close()
+
+	client := newTestClient(t, &ProviderConfig{
+		Username: "foo",
+		Password: "bar",
+		BaseURL:  server.URL,
+		CacheTTL: time.Minute,
+	})
+	ctx := context.Background()
+
+	// Concurrent identical requests are merged.
+	var wg sync.WaitGroup
+
+	for range 5 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := client.FetchAllServers(ctx)
+			if err != nil {
+				t.Errorf("FetchAllServers() error: %v", err)
+			}
+		}()
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := gets.Load(); got != 1 {
+		t.Errorf("GET requests after concurrent calls: want 1, got %d", got)
+	}
+
+	// Cached within the TTL.
+	servers, err := client.FetchAllServers(ctx)
+	if err != nil {
+		t.Fatalf("FetchAllServers() error: %v", err)
+	}
+
+	if len(servers) != 1 || servers[0].Number != 1 {
+		t.Errorf("FetchAllServers() = %+v", servers)
+	}
+
+	if got := gets.Load(); got != 1 {
+		t.Errorf("GET requests after cached call: want 1, got %d", got)
+	}
+
+	// Invalidated by a mutation.
+	_, err = client.RenameServer(ctx, "1", "foo")
+	if err != nil {
+		t.Fatalf("RenameServer() error: %v", err)
+	}
+
+	_, err = client.FetchAllServers(ctx)
+	if err != nil {
+		t.Fatalf("FetchAllServers() error: %v", err)
+	}
+
+	if got := gets.Load(); got != 2 {
+		t.Errorf("GET requests after mutation: want 2, got %d", got)
+	}
+}
+
+func TestResponseCacheExpiry(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	cache := newResponseCache(time.Minute)
+	cache.set("/server", []byte("[]"), 0, now)
+
+	if _, _, ok := cache.get("/server", now.Add(30*time.Second)); !ok {
+		t.Error("get() within TTL: want hit")
+	}
+
+	if _, _, ok := cache.get("/server", now.Add(2*time.Minute)); ok {
+		t.Error("get() after TTL: want miss")
+	}
+
+	disabled := newResponseCache(0)
+	disabled.set("/server", []byte("[]"), 0, now)
+
+	if _, _, ok := disabled.get("/server", now); ok {
+		t.Error("get() with caching disabled: want miss")
+	}
+}
+
+func TestResponseCacheGeneration(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	cache := newResponseCache(time.Minute)
+
+	_, generation, _ := cache.get("/server", now)
+
+	// A response fetched before a mutation is not cached.
+	cache.invalidate()
+	cache.set("/server", []byte("[]"), generation, now)
+
+	if _, _, ok := cache.get("/server", now); ok {
+		t.Error("get() after a stale set: want miss")
+	}
+}
+
+func TestCachedGetCancel(t *testing.T) {
+	t.Parallel()
+
+	var gets atomic.Int32
+
+	release := make(chan struct{})
+
+	server := httptest.NewServer(
+		http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+			gets.Add(1)
+			<-release
+
+			_, _ = writer.Write([]byte(`[{"server":{"server_number":1}}]`))
+		}),
+	)
+	defer server.Close()
+
+	client := newTestClient(t, &ProviderConfig{
+		Username: "foo",
+		Password: "bar",
+		BaseURL:  server.URL,
+		CacheTTL: time.Minute,
+	})
+
+	done := make(chan error)
+
+	go func() {
+		_, err := client.FetchAllServers(context.Background())
+		done <- err
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+
+	// Cancelling a caller sharing the request only fails that caller.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := client.FetchAllServers(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("FetchAllServers() error = %v, want context.Canceled", err)
+	}
+
+	close(release)
+
+	err = <-done
+	if err != nil {
+		t.Errorf("FetchAllServers() error: %v", err)
+	}
+
+	if got := gets.Load(); got != 1 {
+		t.Errorf("GET requests: want 1, got %d", got)
+	}
+}
@@ -2,13 +2,22 @@
 package client
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"time"
 )
 
+// DefaultBaseURL is the Hetzner Robot API.
+const DefaultBaseURL = "https://robot-ws.your-server.de"
+
+// ErrUnexpectedStatus is wrapped by the errors of requests that Robot answered
+// with an unexpected status, as opposed to requests that did not complete.
+var ErrUnexpectedStatus = errors.New("unexpected response status")
+
 const (
 	waitMaxRetries = 60
 	waitDuration   = 20 * time.Second
@@ -19,23 +28,60 @@ type ProviderConfig struct {
 	Username string
 	Password string
 	BaseURL  string
+	// CacheTTL is how long read-only responses are cached, 0 disables it.
+	CacheTTL time.Duration
+	// QuotaLimits overrides the hourly request limit of endpoint families,
+	// see DefaultQuotaLimits. A limit of 0 disables the throttling.
+	QuotaLimits map[string]int
+	// MaxConcurrency limits the concurrent requests of batch lookups, 10 if not
+	// positive.
+	MaxConcurrency int
+
+	// Timeout limits the duration of a request, 0 means no limit.
+	Timeout time.Duration
+	// MaxIdleConns is the number of idle connections kept open.
+	MaxIdleConns int
+	// IdleConnTimeout is how long an idle connection is kept open.
+	IdleConnTimeout time.Duration
+	// HTTPProxy overrides the proxy taken from the environment.
+	HTTPProxy string
+	// CACertPEM holds PEM encoded certificates trusted on top of the system ones.
+	CACertPEM string
+	// InsecureSkipVerify disables the TLS certificate verification.
+	InsecureSkipVerify bool
 }
 
 // HetznerRobotClient represents the Hetzner Robot client.
 type HetznerRobotClient struct {
 	Config *ProviderConfig
 	Client *http.Client
+
+	vswitches *vswitchCoordinator
+	firewalls *keyedMutex
+	cache     *responseCache
+	quota     *quotaTracker
 }
 
-// New creates a new Hetzner Robot client.
-func New(config *ProviderConfig) *HetznerRobotClient {
-	return &HetznerRobotClient{
-		Config: config,
-		Client: &http.Client{},
+// New creates a new Hetzner Robot client, failing on invalid transport
+// settings.
+func New(config *ProviderConfig) (*HetznerRobotClient, error) {
+	httpClient, err := newHTTPClient(config)
+	if err != nil {
+		return nil, err
 	}
+
+	return &HetznerRobotClient{
+		Config:    config,
+		Client:    httpClient,
+		vswitches: newVSwitchCoordinator(),
+		firewalls: newKeyedMutex(),
+		cache:     newResponseCache(config.CacheTTL),
+		quota:     newQuotaTracker(config.QuotaLimits),
+	}, nil
 }
 
-// DoRequest executes a request to the Hetzner Robot API.
+// DoRequest executes a request to the Hetzner Robot API. Requests are logged
+// through tflog, with credentials redacted.
 func (c *HetznerRobotClient) DoRequest(
 	ctx context.Context,
 	method string,
@@ -43,11 +89,22 @@ func (c *HetznerRobotClient) DoRequest(
 	body io.Reader,
 	contentType string,
 ) (*http.Response, error) {
+	var payload []byte
+
+	if body != nil {
+		var err error
+
+		payload, err = io.ReadAll(body)
+		if err != nil {
+			return nil, fmt.Errorf("error reading request body: %w", err)
+		}
+	}
+
 	req, err := http.NewRequestWithContext(
 		ctx,
 		method,
 		fmt.Sprintf("%s%s", c.Config.BaseURL, path),
-		body,
+		bytes.NewReader(payload),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %w", err)
@@ -59,7 +116,23 @@ func (c *HetznerRobotClient) DoRequest(
 		req.Header.Set("Content-Type", contentType)
 	}
 
+	// Any change can make cached responses stale, including the ones fetched
+	// while it was in flight.
+	if method != http.MethodGet {
+		c.cache.invalidate()
+		defer c.cache.invalidate()
+	}
+
+	err = c.quota.wait(ctx, quotaFamily(path))
+	if err != nil {
+		return nil, err
+	}
+
+	logResponse := logRequest(ctx, req, payload)
+
 	resp, err := c.Client.Do(req)
+	logResponse(resp)
+
 	if err != nil {
 		return nil, fmt.Errorf("error making request: %w", err)
 	}
@@ -230,11 +230,14 @@ func TestAuth(t *testing.T) {
 			server := mockServer()
 			defer server.Close()
 
-			client := client.New(&client.ProviderConfig{
+			client, err := client.New(&client.ProviderConfig{
 				Username: test.username,
 				Password: test.password,
 				BaseURL:  server.URL,
 			})
+			if err != nil {
+				t.Fatalf("New() error: %v", err)
+			}
 
 			ctx := context.Background()
 
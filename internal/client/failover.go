@@ -60,6 +60,50 @@ func (c *HetznerRobotClient) FetchFailover(ctx context.Context, ip string) (Fail
 	return result.Failover, nil
 }
 
+// FetchAllFailovers returns the routing state of all failover IPs of the
+// account.
+func (c *HetznerRobotClient) FetchAllFailovers(ctx context.Context) ([]Failover, error) {
+	resp, err := c.cachedGet(ctx, "/failover")
+	if err != nil {
+		return nil, fmt.Errorf("error fetching failovers: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	// Robot answers 404 when the account has no failover IP.
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read response body: %w", err)
+		}
+
+		return nil, fmt.Errorf(
+			"error fetching failovers: status %d, body %s",
+			resp.StatusCode, body,
+		)
+	}
+
+	var raw []struct {
+		Failover Failover `json:"failover"`
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&raw)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding failovers response: %w", err)
+	}
+
+	failovers := make([]Failover, len(raw))
+	for i, item := range raw {
+		failovers[i] = item.Failover
+	}
+
+	return failovers, nil
+}
+
 // SetFailover routes a failover IP to a different active server.
 func (c *HetznerRobotClient) SetFailover(ctx context.Context, ip, activeServerIP string) error {
 	form := url.Values{}
@@ -3,6 +3,7 @@ package client
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -13,9 +14,12 @@ import (
 )
 
 // Firewall defines the body format for /firewall requests.
+// FilterIPv6 and Port are only reported by Robot, SetFirewall does not send them.
 type Firewall struct {
 	IP                       string        `json:"ip"`
 	WhitelistHetznerServices bool          `json:"whitelist_hos"`
+	FilterIPv6               bool          `json:"filter_ipv6"`
+	Port                     string        `json:"port"`
 	Status                   string        `json:"status"`
 	Rules                    FirewallRules `json:"rules"`
 }
@@ -27,14 +31,15 @@ type FirewallRules struct {
 
 // FirewallRule defines a firewall rule for FirewallRules.
 type FirewallRule struct {
-	Name     string `json:"name,omitempty"`
-	SrcIP    string `json:"src_ip,omitempty"`
-	SrcPort  string `json:"src_port,omitempty"`
-	DstIP    string `json:"dst_ip,omitempty"`
-	DstPort  string `json:"dst_port,omitempty"`
-	Protocol string `json:"protocol,omitempty"`
-	TCPFlags string `json:"tcp_flags,omitempty"`
-	Action   string `json:"action"`
+	IPVersion string `json:"ip_version,omitempty"`
+	Name      string `json:"name,omitempty"`
+	SrcIP     string `json:"src_ip,omitempty"`
+	SrcPort   string `json:"src_port,omitempty"`
+	DstIP     string `json:"dst_ip,omitempty"`
+	DstPort   string `json:"dst_port,omitempty"`
+	Protocol  string `json:"protocol,omitempty"`
+	TCPFlags  string `json:"tcp_flags,omitempty"`
+	Action    string `json:"action"`
 }
 
 // FirewallResponse defines the response from /firewall.
@@ -59,7 +64,7 @@ func (c *HetznerRobotClient) GetFirewall(ctx context.Context, ip string) (*Firew
 			return nil, fmt.Errorf("unable to read response body: %w", err)
 		}
 
-		return nil, fmt.Errorf("unexpected response status: %d, body: %s", resp.StatusCode, data)
+		return nil, fmt.Errorf("%w: %d, body: %s", ErrUnexpectedStatus, resp.StatusCode, data)
 	}
 
 	var fwResp FirewallResponse
@@ -72,19 +77,105 @@ func (c *HetznerRobotClient) GetFirewall(ctx context.Context, ip string) (*Firew
 	return &fwResp.Firewall, nil
 }
 
-// SetFirewall sets firewall rules for a server ip.
+// FetchFirewallsByIPs returns the firewalls of the servers with the given ips,
+// in the same order. If some fail, the others are returned along with an
+// error wrapping TaskErrors.
+func (c *HetznerRobotClient) FetchFirewallsByIPs(
+	ctx context.Context,
+	ips []string,
+) ([]Firewall, error) {
+	firewalls, err := runConcurrentTasks(
+		ctx,
+		ips,
+		c.Config.MaxConcurrency,
+		func(ctx context.Context, ip string) (Firewall, error) {
+			firewall, err := c.GetFirewall(ctx, ip)
+			if err != nil {
+				return Firewall{}, err
+			}
+
+			// Robot reports the address as server_ip, keep the requested one.
+			firewall.IP = ip
+
+			return *firewall, nil
+		},
+	)
+	if err != nil {
+		return firewalls, fmt.Errorf("error fetching firewalls: %w", err)
+	}
+
+	return firewalls, nil
+}
+
+// ErrFirewallInProcess is returned when a firewall stays "in process" for
+// longer than the wait timeout.
+var ErrFirewallInProcess = errors.New("firewall still in process")
+
+// errFirewallConflict is returned when Robot rejects an update because a
+// previous one is still being applied.
+var errFirewallConflict = errors.New("firewall update in process")
+
+const (
+	firewallStatusInProcess = "in process"
+	firewallInProcessCode   = "FIREWALL_IN_PROCESS"
+
+	// firewallTimeout bounds a whole SetFirewall call, retries of rejected
+	// updates included.
+	firewallTimeout = waitMaxRetries * waitDuration
+)
+
+// SetFirewall sets firewall rules for a server ip. Updates of the same ip are
+// serialised, and an update rejected because a previous one is still in
+// process is retried once the firewall has settled, within firewallTimeout.
 func (c *HetznerRobotClient) SetFirewall(
 	ctx context.Context,
 	firewall Firewall,
 ) error {
-	path := "/firewall/" + firewall.IP
+	unlock := c.firewalls.lock(firewall.IP)
+	defer unlock()
+
+	data := encodeFirewall(firewall)
+	deadline := time.Now().Add(firewallTimeout)
+
+	for attempt := range waitMaxRetries {
+		err := c.postFirewall(withAttempt(ctx, attempt+1), firewall.IP, data)
+		if err == nil {
+			return c.waitForFirewall(ctx, firewall.IP, deadline, func(status string) bool {
+				return status == firewall.Status
+			})
+		}
+
+		if !errors.Is(err, errFirewallConflict) {
+			return err
+		}
 
+		err = c.waitForFirewall(ctx, firewall.IP, deadline, func(status string) bool {
+			return status != firewallStatusInProcess
+		})
+		if err != nil {
+			return err
+		}
+
+		if !time.Now().Before(deadline) {
+			break
+		}
+	}
+
+	return fmt.Errorf("%w: ip %s kept rejecting updates", ErrFirewallInProcess, firewall.IP)
+}
+
+func encodeFirewall(firewall Firewall) url.Values {
 	data := url.Values{}
 	data.Set("whitelist_hos", strconv.FormatBool(firewall.WhitelistHetznerServices))
 	data.Set("status", firewall.Status)
 
 	for index, rule := range firewall.Rules.Input {
-		data.Set(fmt.Sprintf("rules[input][%d][ip_version]", index), "ipv4")
+		ipVersion := rule.IPVersion
+		if ipVersion == "" {
+			ipVersion = "ipv4"
+		}
+
+		data.Set(fmt.Sprintf("rules[input][%d][ip_version]", index), ipVersion)
 
 		fields := map[string]string{
 			"name":      rule.Name,
@@ -105,10 +196,14 @@ func (c *HetznerRobotClient) SetFirewall(
 		data.Set(fmt.Sprintf("rules[input][%d][action]", index), rule.Action)
 	}
 
+	return data
+}
+
+func (c *HetznerRobotClient) postFirewall(ctx context.Context, ip string, data url.Values) error {
 	resp, err := c.DoRequest(
 		ctx,
 		"POST",
-		path,
+		"/firewall/"+ip,
 		strings.NewReader(data.Encode()),
 		"application/x-www-form-urlencoded",
 	)
@@ -123,28 +218,59 @@ func (c *HetznerRobotClient) SetFirewall(
 			return fmt.Errorf("unable to read response body: %w", err)
 		}
 
-		return fmt.Errorf("unexpected response status: %d, body: %s", resp.StatusCode, data)
+		if resp.StatusCode == http.StatusConflict &&
+			robotErrorCode(data) == firewallInProcessCode {
+			return errFirewallConflict
+		}
+
+		return fmt.Errorf("%w: %d, body: %s", ErrUnexpectedStatus, resp.StatusCode, data)
 	}
 
-	return c.waitForFirewallActive(ctx, firewall.IP)
+	return nil
 }
 
-func (c *HetznerRobotClient) waitForFirewallActive(
+// waitForFirewall waits until the status of the firewall satisfies ready, at
+// the latest until deadline, reporting a firewall stuck "in process" with
+// ErrFirewallInProcess.
+func (c *HetznerRobotClient) waitForFirewall(
 	ctx context.Context,
 	ip string,
+	deadline time.Time,
+	ready func(status string) bool,
 ) error {
-	for range waitMaxRetries {
+	var status string
+
+	for {
 		firewall, err := c.GetFirewall(ctx, ip)
 		if err != nil {
 			return fmt.Errorf("error checking firewall status: %w", err)
 		}
 
-		if firewall.Status == "active" {
+		status = firewall.Status
+		if ready(status) {
 			return nil
 		}
 
-		time.Sleep(waitDuration)
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("error waiting for firewall on ip %s: %w", ip, ctx.Err())
+		case <-time.After(min(waitDuration, remaining)):
+		}
+	}
+
+	if status == firewallStatusInProcess {
+		return fmt.Errorf(
+			"%w: ip %s did not settle within %s",
+			ErrFirewallInProcess,
+			ip,
+			firewallTimeout,
+		)
 	}
 
-	return fmt.Errorf("timeout waiting for firewall to become active on ip: %s", ip)
+	return fmt.Errorf("timeout waiting for firewall on ip %s, last status %q", ip, status)
 }
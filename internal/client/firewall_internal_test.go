@@ -0,0 +1,130 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSetFirewallInProcessRetry(t *testing.T) {
+	t.Parallel()
+
+	var posts atomic.Int32
+
+	server := httptest.NewServer(
+		http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+			switch req.Method {
+			case http.MethodPost:
+				if posts.Add(1) == 1 {
+					writer.WriteHeader(http.StatusConflict)
+					_, _ = writer.Write([]byte(
+						`{"error":{"status":409,"code":"FIREWALL_IN_PROCESS","message":"busy"}}`,
+					))
+
+					return
+				}
+
+				_, _ = writer.Write([]byte(`{"firewall":{"ip":"1.2.3.4","status":"in process"}}`))
+			case http.MethodGet:
+				_, _ = writer.Write([]byte(`{"firewall":{"ip":"1.2.3.4","status":"active"}}`))
+			}
+		}),
+	)
+	defer server.Close()
+
+	client := newTestClient(t, &ProviderConfig{Username: "foo", Password: "bar", BaseURL: server.URL})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	//exhaustruct:ignore
+	err := client.SetFirewall(ctx, Firewall{IP: "1.2.3.4", Status: "active"})
+	if err != nil {
+		t.Fatalf("SetFirewall() error: %v", err)
+	}
+
+	if got := posts.Load(); got != 2 {
+		t.Errorf("requests: want 2, got %d", got)
+	}
+}
+
+func TestSetFirewallCancelled(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(
+		http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+			_, _ = writer.Write([]byte(`{"firewall":{"ip":"1.2.3.4","status":"in process"}}`))
+		}),
+	)
+	defer server.Close()
+
+	client := newTestClient(t, &ProviderConfig{Username: "foo", Password: "bar", BaseURL: server.URL})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	// The firewall never settles, the wait ends with the context.
+	//exhaustruct:ignore
+	err := client.SetFirewall(ctx, Firewall{IP: "1.2.3.4", Status: "active"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("SetFirewall() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	if waited := time.Since(start); waited > 5*time.Second {
+		t.Errorf("SetFirewall() returned after %s, want right after the context is done", waited)
+	}
+}
+
+func TestSetFirewallUnexpectedStatus(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(
+		http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+			writer.WriteHeader(http.StatusBadRequest)
+			_, _ = writer.Write([]byte(`{"error":{"status":400,"code":"INVALID_INPUT","message":"invalid input"}}`))
+		}),
+	)
+	defer server.Close()
+
+	client := newTestClient(t, &ProviderConfig{Username: "foo", Password: "bar", BaseURL: server.URL})
+
+	//exhaustruct:ignore
+	err := client.SetFirewall(context.Background(), Firewall{IP: "1.2.3.4", Status: "active"})
+	if !errors.Is(err, ErrUnexpectedStatus) {
+		t.Errorf("SetFirewall() error = %v, want %v", err, ErrUnexpectedStatus)
+	}
+}
+
+func TestRobotErrorCode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "Robot error",
+			body: `{"error":{"status":409,"code":"FIREWALL_IN_PROCESS","message":"busy"}}`,
+			want: "FIREWALL_IN_PROCESS",
+		},
+		{name: "Not JSON", body: "Bad Gateway", want: ""},
+		{name: "Other JSON", body: `{"firewall":{}}`, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := robotErrorCode([]byte(tt.body)); got != tt.want {
+				t.Errorf("robotErrorCode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -11,6 +11,8 @@ import (
 var testFirewall = client.Firewall{
 	IP:                       "1.2.3.4",
 	WhitelistHetznerServices: true,
+	FilterIPv6:               false,
+	Port:                     "main",
 	Status:                   "active",
 	Rules: client.FirewallRules{
 		//exhaustruct:ignore
@@ -39,11 +41,14 @@ func TestGetFirewall(t *testing.T) {
 	server := mockServer()
 	defer server.Close()
 
-	client := client.New(&client.ProviderConfig{
+	client, err := client.New(&client.ProviderConfig{
 		Username: testUsername,
 		Password: testPassword,
 		BaseURL:  server.URL,
 	})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
 
 	firewall, err := client.GetFirewall(context.Background(), testFirewall.IP)
 	if err != nil {
@@ -114,14 +119,54 @@ func TestSetFirewall(t *testing.T) {
 	server := mockServer()
 	defer server.Close()
 
-	client := client.New(&client.ProviderConfig{
+	client, err := client.New(&client.ProviderConfig{
 		Username: testUsername,
 		Password: testPassword,
 		BaseURL:  server.URL,
 	})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
 
-	err := client.SetFirewall(context.Background(), testFirewall)
+	err = client.SetFirewall(context.Background(), testFirewall)
 	if err != nil {
 		t.Errorf("SetFirewall() error: %v", err)
 	}
 }
+
+func TestFetchFirewallsByIPs(t *testing.T) {
+	t.Parallel()
+
+	server := mockServer()
+	defer server.Close()
+
+	client, err := client.New(&client.ProviderConfig{
+		Username: testUsername,
+		Password: testPassword,
+		BaseURL:  server.URL,
+	})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	ips := []string{"1.2.3.4", "5.6.7.8"}
+
+	firewalls, err := client.FetchFirewallsByIPs(context.Background(), ips)
+	if err != nil {
+		t.Fatalf("FetchFirewallsByIPs() error: %v", err)
+	}
+
+	if len(firewalls) != len(ips) {
+		t.Fatalf("firewalls: want %d, got %d", len(ips), len(firewalls))
+	}
+
+	for i, firewall := range firewalls {
+		if firewall.IP != ips[i] {
+			t.Errorf("firewall[%d] IP: want %v, got %v", i, ips[i], firewall.IP)
+		}
+
+		if firewall.Port != testFirewall.Port {
+			t.Errorf("firewall[%d] Port: want %v, got %v", i, testFirewall.Port, firewall.Port)
+		}
+	}
+}
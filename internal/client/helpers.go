@@ -2,61 +2,183 @@ package client
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"sync"
 )
 
+// defaultConcurrency is the default number of concurrent requests of
+// runConcurrentTasks.
+const defaultConcurrency = 10
+
+// robotError defines the error body returned by the Robot API.
+type robotError struct {
+	Error struct {
+		Status  int    `json:"status"`
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// robotErrorCode returns the Robot error code of a response body, e.g.
+// "FIREWALL_IN_PROCESS", or an empty string if the body is not a Robot error.
+func robotErrorCode(body []byte) string {
+	var robotErr robotError
+
+	err := json.Unmarshal(body, &robotErr)
+	if err != nil {
+		return ""
+	}
+
+	return robotErr.Error.Code
+}
+
+// keyedMutex provides a mutex per key, e.g. per vSwitch or per server IP.
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+func newKeyedMutex() *keyedMutex {
+	return &keyedMutex{
+		mu:    sync.Mutex{},
+		locks: make(map[string]*sync.Mutex),
+	}
+}
+
+// lock acquires the mutex of key and returns the function releasing it.
+func (k *keyedMutex) lock(key string) func() {
+	k.mu.Lock()
+
+	lock, ok := k.locks[key]
+	if !ok {
+		lock = &sync.Mutex{}
+		k.locks[key] = lock
+	}
+
+	k.mu.Unlock()
+
+	lock.Lock()
+
+	return lock.Unlock
+}
+
+// TaskErrors maps the IDs whose task failed to their error.
+type TaskErrors map[string]error
+
+// Error lists the failures sorted by ID.
+func (e TaskErrors) Error() string {
+	return errors.Join(e.Unwrap()...).Error()
+}
+
+// Unwrap returns the failures sorted by ID, so that errors.Is and errors.As
+// match any of them.
+func (e TaskErrors) Unwrap() []error {
+	ids := e.IDs()
+
+	errs := make([]error, 0, len(ids))
+	for _, id := range ids {
+		errs = append(errs, fmt.Errorf("%s: %w", id, e[id]))
+	}
+
+	return errs
+}
+
+// IDs returns the IDs of the failed tasks, sorted.
+func (e TaskErrors) IDs() []string {
+	return slices.Sorted(maps.Keys(e))
+}
+
+// runConcurrentTasks runs worker for every id, at most limit at a time (10 if
+// limit is not positive). It returns the successful items in the order of ids
+// and, if any task failed, a TaskErrors along with them so callers can choose
+// to use partial results. Once ctx is cancelled, no new task is started.
 func runConcurrentTasks[T any](
 	ctx context.Context,
 	ids []string,
+	limit int,
 	worker func(ctx context.Context, id string) (T, error),
 ) ([]T, error) {
+	if limit <= 0 {
+		limit = defaultConcurrency
+	}
+
 	var (
-		wg    sync.WaitGroup
-		mu    sync.Mutex
-		items []T
-		errs  []error
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs = TaskErrors{}
 	)
 
-	const maxConcurrent = 10
+	results := make([]T, len(ids))
+	succeeded := make([]bool, len(ids))
+	sem := make(chan struct{}, limit)
 
-	sem := make(chan struct{}, maxConcurrent)
+	for index, id := range ids {
+		if !acquire(ctx, sem) {
+			mu.Lock()
 
-	for _, id := range ids {
-		wg.Add(1)
+			for _, skipped := range ids[index:] {
+				errs[skipped] = ctx.Err()
+			}
 
-		go func(id string) {
-			defer wg.Done()
+			mu.Unlock()
 
-			sem <- struct{}{}
+			break
+		}
 
-			item, err := worker(ctx, id)
+		wg.Add(1)
 
-			<-sem
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
 
+			item, err := worker(ctx, id)
 			if err != nil {
 				mu.Lock()
 
-				errs = append(errs, err)
+				errs[id] = err
 
 				mu.Unlock()
 
 				return
 			}
 
-			mu.Lock()
-
-			items = append(items, item)
-
-			mu.Unlock()
-		}(id)
+			// Each task owns its index, no lock needed.
+			results[index] = item
+			succeeded[index] = true
+		}()
 	}
 
 	wg.Wait()
 
+	items := make([]T, 0, len(ids))
+
+	for index, item := range results {
+		if succeeded[index] {
+			items = append(items, item)
+		}
+	}
+
 	if len(errs) > 0 {
-		return nil, fmt.Errorf("error fetching: %v", errs)
+		return items, errs
 	}
 
 	return items, nil
 }
+
+// acquire takes a slot of sem, or returns false once ctx is cancelled.
+func acquire(ctx context.Context, sem chan struct{}) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	select {
+	case sem <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -2,14 +2,16 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
-	"sort"
-	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 )
 
+var errTaskFailed = errors.New("task failed")
+
 func TestRunConcurrentTasks(t *testing.T) {
 	t.Parallel()
 
@@ -22,11 +24,16 @@ func TestRunConcurrentTasks(t *testing.T) {
 	}{
 		{
 			name: "All success",
-			ids:  []string{"1", "2", "3"},
+			ids:  []string{"3", "1", "2"},
 			worker: func(_ context.Context, id string) (string, error) {
+				// Finish out of order.
+				if id == "3" {
+					time.Sleep(50 * time.Millisecond)
+				}
+
 				return "item-" + id, nil
 			},
-			wantItems: []string{"item-1", "item-2", "item-3"},
+			wantItems: []string{"item-3", "item-1", "item-2"},
 			wantErrs:  nil,
 		},
 		{
@@ -34,13 +41,13 @@ func TestRunConcurrentTasks(t *testing.T) {
 			ids:  []string{"1", "2", "3", "4", "5"},
 			worker: func(_ context.Context, id string) (string, error) {
 				if id == "2" || id == "4" {
-					return "", fmt.Errorf("failed for id: %s", id)
+					return "", fmt.Errorf("failed for id: %s: %w", id, errTaskFailed)
 				}
 
 				return "item-" + id, nil
 			},
-			wantItems: nil,
-			wantErrs:  []string{"failed for id: 2", "failed for id: 4"},
+			wantItems: []string{"item-1", "item-3", "item-5"},
+			wantErrs:  []string{"2", "4"},
 		},
 	}
 
@@ -51,31 +58,103 @@ func TestRunConcurrentTasks(t *testing.T) {
 			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
 			defer cancel()
 
-			items, err := runConcurrentTasks(ctx, tt.ids, tt.worker)
+			items, err := runConcurrentTasks(ctx, tt.ids, 2, tt.worker)
 
-			if !haveSameItems(tt.wantItems, items) {
+			if !reflect.DeepEqual(tt.wantItems, items) {
 				t.Errorf("unexpected items, want %s, got %s", tt.wantItems, items)
 			}
 
-			switch {
-			case tt.wantErrs == nil && err == nil:
+			if tt.wantErrs == nil {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+
 				return
-			case tt.wantErrs == nil && err != nil:
-				t.Errorf("unexpected error: %v", err)
 			}
 
-			for _, wantErr := range tt.wantErrs {
-				if !strings.Contains(err.Error(), wantErr) {
-					t.Errorf("unexpected errors, want %s, got %s", wantErr, err)
+			if !errors.Is(err, errTaskFailed) {
+				t.Errorf("errors.Is(err, errTaskFailed) = false, err: %v", err)
+			}
+
+			var taskErrs TaskErrors
+			if !errors.As(err, &taskErrs) {
+				t.Fatalf("errors.As(err, TaskErrors) = false, err: %v", err)
+			}
+
+			if len(taskErrs) != len(tt.wantErrs) {
+				t.Errorf("unexpected errors, want IDs %s, got %v", tt.wantErrs, taskErrs)
+			}
+
+			for _, id := range tt.wantErrs {
+				if taskErrs[id] == nil {
+					t.Errorf("missing error for ID %s", id)
 				}
 			}
 		})
 	}
 }
 
-func haveSameItems(a, b []string) bool {
-	sort.Strings(a)
-	sort.Strings(b)
+func TestRunConcurrentTasksLimit(t *testing.T) {
+	t.Parallel()
+
+	var running, peak atomic.Int32
+
+	ids := []string{"1", "2", "3", "4", "5", "6"}
+
+	_, err := runConcurrentTasks(
+		context.Background(),
+		ids,
+		2,
+		func(_ context.Context, id string) (string, error) {
+			current := running.Add(1)
+			defer running.Add(-1)
+
+			for {
+				old := peak.Load()
+				if current <= old || peak.CompareAndSwap(old, current) {
+					break
+				}
+			}
+
+			time.Sleep(20 * time.Millisecond)
 
-	return reflect.DeepEqual(a, b)
+			return id, nil
+		},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := peak.Load(); got > 2 {
+		t.Errorf("concurrent tasks: want at most 2, got %d", got)
+	}
+}
+
+func TestRunConcurrentTasksCancel(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var started atomic.Int32
+
+	ids := []string{"1", "2", "3", "4"}
+
+	items, err := runConcurrentTasks(ctx, ids, 1, func(_ context.Context, id string) (string, error) {
+		started.Add(1)
+		cancel()
+
+		return id, nil
+	})
+
+	if got := started.Load(); got != 1 {
+		t.Errorf("started tasks after cancel: want 1, got %d", got)
+	}
+
+	if !reflect.DeepEqual([]string{"1"}, items) {
+		t.Errorf("unexpected items: %s", items)
+	}
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("errors.Is(err, context.Canceled) = false, err: %v", err)
+	}
 }
@@ -0,0 +1,164 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const redacted = "[REDACTED]"
+
+// attemptKey is the context key of the retry attempt of a request.
+type attemptKey struct{}
+
+// withAttempt records in ctx that the requests it carries are the given retry
+// attempt, starting from 1, so that DoRequest logs it.
+func withAttempt(ctx context.Context, attempt int) context.Context {
+	return context.WithValue(ctx, attemptKey{}, attempt)
+}
+
+func attemptFrom(ctx context.Context) int {
+	attempt, ok := ctx.Value(attemptKey{}).(int)
+	if !ok {
+		return 1
+	}
+
+	return attempt
+}
+
+// isSensitiveField reports whether a form or JSON field holds a secret:
+// passwords, including rescue passwords, and SSH key data.
+func isSensitiveField(name string) bool {
+	name = strings.ToLower(name)
+
+	return strings.Contains(name, "password") || name == "data"
+}
+
+// redactHeaders returns the request headers with credentials masked.
+func redactHeaders(header http.Header) map[string]string {
+	result := make(map[string]string, len(header))
+
+	for name := range header {
+		value := header.Get(name)
+		if strings.EqualFold(name, "Authorization") {
+			value = redacted
+		}
+
+		result[name] = value
+	}
+
+	return result
+}
+
+// redactBody returns a loggable copy of a form or JSON body with the
+// sensitive fields masked.
+func redactBody(contentType string, body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return redacted
+		}
+
+		for name := range values {
+			if isSensitiveField(name) {
+				values[name] = []string{redacted}
+			}
+		}
+
+		return values.Encode()
+	}
+
+	var value any
+
+	err := json.Unmarshal(body, &value)
+	if err != nil {
+		// Plain text, e.g. an error page from a proxy.
+		return string(body)
+	}
+
+	data, err := json.Marshal(redactJSON(value))
+	if err != nil {
+		return redacted
+	}
+
+	return string(data)
+}
+
+func redactJSON(value any) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		for key, item := range typed {
+			if isSensitiveField(key) {
+				typed[key] = redacted
+			} else {
+				typed[key] = redactJSON(item)
+			}
+		}
+	case []any:
+		for index, item := range typed {
+			typed[index] = redactJSON(item)
+		}
+	}
+
+	return value
+}
+
+// logRequest logs a request to the Robot API and returns the function logging
+// its response, which buffers the response body so it can still be read.
+func logRequest(ctx context.Context, req *http.Request, body []byte) func(*http.Response) {
+	start := time.Now()
+	fields := map[string]any{
+		"method":  req.Method,
+		"path":    req.URL.Path,
+		"attempt": attemptFrom(ctx),
+	}
+
+	tflog.Debug(ctx, "Sending Robot API request", fields)
+	tflog.Trace(ctx, "Robot API request details", map[string]any{
+		"method":  req.Method,
+		"path":    req.URL.Path,
+		"headers": redactHeaders(req.Header),
+		"body":    redactBody(req.Header.Get("Content-Type"), body),
+	})
+
+	return func(resp *http.Response) {
+		fields["duration_ms"] = time.Since(start).Milliseconds()
+
+		if resp == nil {
+			tflog.Debug(ctx, "Robot API request failed", fields)
+
+			return
+		}
+
+		respBody, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+		fields["status"] = resp.StatusCode
+		if code := robotErrorCode(respBody); code != "" {
+			fields["robot_error_code"] = code
+		}
+
+		if err != nil {
+			fields["read_error"] = err.Error()
+		}
+
+		tflog.Debug(ctx, "Received Robot API response", fields)
+		tflog.Trace(ctx, "Robot API response details", map[string]any{
+			"method": req.Method,
+			"path":   req.URL.Path,
+			"body":   redactBody(resp.Header.Get("Content-Type"), respBody),
+		})
+	}
+}
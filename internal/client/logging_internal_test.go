@@ -0,0 +1,122 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+)
+
+func TestRedactBody(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        string
+	}{
+		{
+			name:        "Form",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "data=ssh-ed25519+AAAA&name=laptop",
+			want:        "data=%5BREDACTED%5D&name=laptop",
+		},
+		{
+			name:        "Rescue password",
+			contentType: "application/json",
+			body:        `{"rescue":{"password":"s3cret","server_ip":"1.2.3.4"}}`,
+			want:        `{"rescue":{"password":"[REDACTED]","server_ip":"1.2.3.4"}}`,
+		},
+		{
+			name:        "SSH keys",
+			contentType: "",
+			body:        `[{"key":{"data":"ssh-ed25519 AAAA","name":"laptop"}}]`,
+			want:        `[{"key":{"data":"[REDACTED]","name":"laptop"}}]`,
+		},
+		{name: "Plain text", contentType: "", body: "Bad Gateway", want: "Bad Gateway"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := redactBody(tt.contentType, []byte(tt.body)); got != tt.want {
+				t.Errorf("redactBody() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDoRequestLogging(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(
+		http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+			writer.WriteHeader(http.StatusConflict)
+			_, _ = writer.Write([]byte(
+				`{"error":{"status":409,"code":"FIREWALL_IN_PROCESS","message":"busy"}}`,
+			))
+		}),
+	)
+	defer server.Close()
+
+	var output bytes.Buffer
+
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+
+	client := newTestClient(t, &ProviderConfig{Username: "foo", Password: "s3cret", BaseURL: server.URL})
+
+	resp, err := client.DoRequest(
+		withAttempt(ctx, 2),
+		http.MethodPost,
+		"/boot/1/rescue",
+		strings.NewReader("os=linux&password=s3cret"),
+		"application/x-www-form-urlencoded",
+	)
+	if err != nil {
+		t.Fatalf("DoRequest() error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// The response body can still be read after being logged.
+	if robotErrorCode(readAll(t, resp)) != firewallInProcessCode {
+		t.Error("response body not readable after logging")
+	}
+
+	logs := output.String()
+
+	for _, want := range []string{
+		`"path":"/boot/1/rescue"`,
+		`"status":409`,
+		`"attempt":2`,
+		`"robot_error_code":"FIREWALL_IN_PROCESS"`,
+		"password=%5BREDACTED%5D",
+		`"Authorization":"[REDACTED]"`,
+	} {
+		if !strings.Contains(logs, want) {
+			t.Errorf("logs do not contain %s:\n%s", want, logs)
+		}
+	}
+
+	if strings.Contains(logs, "s3cret") {
+		t.Errorf("logs leak the password:\n%s", logs)
+	}
+}
+
+func readAll(t *testing.T, resp *http.Response) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	_, err := buf.ReadFrom(resp.Body)
+	if err != nil {
+		t.Fatalf("reading response body: %v", err)
+	}
+
+	return buf.Bytes()
+}
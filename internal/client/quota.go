@@ -0,0 +1,175 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Endpoint families sharing a Robot request quota.
+const (
+	QuotaFamilyBoot     = "boot"
+	QuotaFamilyFailover = "failover"
+	QuotaFamilyFirewall = "firewall"
+	QuotaFamilyKey      = "key"
+	QuotaFamilyReset    = "reset"
+	QuotaFamilyServer   = "server"
+	QuotaFamilyVSwitch  = "vswitch"
+	QuotaFamilyWOL      = "wol"
+
+	quotaInterval = time.Hour
+)
+
+// ErrQuotaExceeded is returned when the planned requests do not fit in the
+// remaining quota of an endpoint family.
+var ErrQuotaExceeded = errors.New("robot request quota exceeded")
+
+// DefaultQuotaLimits returns the hourly request limits documented by Robot
+// for each endpoint family.
+func DefaultQuotaLimits() map[string]int {
+	return map[string]int{
+		QuotaFamilyBoot:     500,
+		QuotaFamilyFailover: 100,
+		QuotaFamilyFirewall: 500,
+		QuotaFamilyKey:      200,
+		QuotaFamilyReset:    50,
+		QuotaFamilyServer:   200,
+		QuotaFamilyVSwitch:  500,
+		QuotaFamilyWOL:      10,
+	}
+}
+
+// tokenBucket holds the requests left in the hourly quota of a family,
+// refilled continuously.
+type tokenBucket struct {
+	limit  float64
+	tokens float64
+	last   time.Time
+}
+
+// refill adds the tokens earned since the last call.
+func (b *tokenBucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last)
+	if elapsed <= 0 {
+		return
+	}
+
+	b.last = now
+
+	b.tokens = math.Min(b.limit, b.tokens+b.limit*elapsed.Seconds()/quotaInterval.Seconds())
+}
+
+// quotaTracker counts the requests per endpoint family and throttles them
+// before Robot starts rejecting them. The quota consumed before the provider
+// started is unknown, so it is best effort.
+type quotaTracker struct {
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	now     func() time.Time
+}
+
+// newQuotaTracker creates a tracker with the default limits overridden by
+// limits. A limit of 0 disables the throttling of a family.
+func newQuotaTracker(limits map[string]int) *quotaTracker {
+	merged := DefaultQuotaLimits()
+	for family, limit := range limits {
+		merged[family] = limit
+	}
+
+	now := time.Now()
+	buckets := make(map[string]*tokenBucket, len(merged))
+
+	for family, limit := range merged {
+		if limit > 0 {
+			buckets[family] = &tokenBucket{limit: float64(limit), tokens: float64(limit), last: now}
+		}
+	}
+
+	return &quotaTracker{mu: sync.Mutex{}, buckets: buckets, now: time.Now}
+}
+
+// quotaFamily returns the endpoint family of a request path, e.g. "server"
+// for "/server/123".
+func quotaFamily(path string) string {
+	family, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+
+	return family
+}
+
+// wait blocks until a request to family fits in the quota, then consumes it.
+func (q *quotaTracker) wait(ctx context.Context, family string) error {
+	for {
+		delay := q.reserve(family)
+		if delay == 0 {
+			return nil
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for %s request quota: %w", family, ctx.Err())
+		}
+	}
+}
+
+// reserve consumes a token of family, or returns how long to wait for one.
+func (q *quotaTracker) reserve(family string) time.Duration {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	bucket, ok := q.buckets[family]
+	if !ok {
+		return 0
+	}
+
+	bucket.refill(q.now())
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+
+		return 0
+	}
+
+	missing := 1 - bucket.tokens
+
+	return time.Duration(missing / bucket.limit * float64(quotaInterval))
+}
+
+// remaining returns the requests left in the quota of family, and false if
+// the family is not throttled.
+func (q *quotaTracker) remaining(family string) (int, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	bucket, ok := q.buckets[family]
+	if !ok {
+		return 0, false
+	}
+
+	bucket.refill(q.now())
+
+	return int(bucket.tokens), true
+}
+
+// CheckQuota reports with ErrQuotaExceeded when requests more calls to family
+// do not fit in its remaining quota. The calls are still made, throttled, so
+// callers surface it as a warning.
+func (c *HetznerRobotClient) CheckQuota(family string, requests int) error {
+	remaining, ok := c.quota.remaining(family)
+	if !ok || requests <= remaining {
+		return nil
+	}
+
+	return fmt.Errorf(
+		"%w: %d %s requests are needed but only %d are left in the hourly quota, "+
+			"the provider will slow down to stay within it",
+		ErrQuotaExceeded,
+		requests,
+		family,
+		remaining,
+	)
+}
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestQuotaFamily(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/server", want: QuotaFamilyServer},
+		{path: "/server/123", want: QuotaFamilyServer},
+		{path: "/boot/123/rescue", want: QuotaFamilyBoot},
+		{path: "/firewall/1.2.3.4", want: QuotaFamilyFirewall},
+		{path: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			t.Parallel()
+
+			if got := quotaFamily(tt.path); got != tt.want {
+				t.Errorf("quotaFamily(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuotaTrackerReserve(t *testing.T) {
+	t.Parallel()
+
+	tracker := newQuotaTracker(map[string]int{QuotaFamilyReset: 2, QuotaFamilyServer: 0})
+
+	// After the creation of the buckets, so that no time goes backwards.
+	now := time.Now()
+	tracker.now = func() time.Time { return now }
+
+	for i := range 2 {
+		if delay := tracker.reserve(QuotaFamilyReset); delay != 0 {
+			t.Errorf("reserve() #%d: want no delay, got %s", i, delay)
+		}
+	}
+
+	// Two requests per hour refill a token every 30 minutes.
+	if delay := tracker.reserve(QuotaFamilyReset); delay != 30*time.Minute {
+		t.Errorf("reserve() over limit: want 30m delay, got %s", delay)
+	}
+
+	now = now.Add(30 * time.Minute)
+
+	if delay := tracker.reserve(QuotaFamilyReset); delay != 0 {
+		t.Errorf("reserve() after refill: want no delay, got %s", delay)
+	}
+
+	// Disabled and unknown families are not throttled.
+	for _, family := range []string{QuotaFamilyServer, "unknown"} {
+		if _, ok := tracker.remaining(family); ok {
+			t.Errorf("remaining(%q): want untracked", family)
+		}
+	}
+}
+
+func TestCheckQuota(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClient(t, &ProviderConfig{
+		Username:    "foo",
+		Password:    "bar",
+		BaseURL:     "http://localhost",
+		QuotaLimits: map[string]int{QuotaFamilyFirewall: 5},
+	})
+
+	err := client.CheckQuota(QuotaFamilyFirewall, 5)
+	if err != nil {
+		t.Errorf("CheckQuota() within quota: unexpected error %v", err)
+	}
+
+	err = client.CheckQuota(QuotaFamilyFirewall, 6)
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("CheckQuota() over quota: want ErrQuotaExceeded, got %v", err)
+	}
+}
@@ -3,10 +3,12 @@ package client
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -26,14 +28,57 @@ type Server struct {
 	PaidUntil  string `json:"paid_until"`
 }
 
-// HetznerRescueResponse defines the response when setting rescue mode.
+// ErrRescueAlreadyActive is returned when activating the rescue system of a
+// server on which it is already active.
+var ErrRescueAlreadyActive = errors.New("rescue system already active")
+
+// bootAlreadyEnabledCode is the Robot error code of a boot configuration
+// activated twice.
+const bootAlreadyEnabledCode = "BOOT_ALREADY_ENABLED"
+
+// HetznerRescueResponse defines the response when setting or querying rescue
+// mode.
 type HetznerRescueResponse struct {
 	Rescue struct {
 		ServerIP string `json:"server_ip"`
+		Active   bool   `json:"active"`
+		// OS is the active rescue system, or the list of the available ones
+		// when inactive.
+		OS             json.RawMessage `json:"os"`
+		AuthorizedKeys []struct {
+			Key struct {
+				Fingerprint string `json:"fingerprint"`
+			} `json:"key"`
+		} `json:"authorized_key"`
+		// Password is null when the rescue system is inactive or was
+		// activated with SSH keys.
 		Password string `json:"password"`
 	} `json:"rescue"`
 }
 
+// Matches reports whether the rescue system is active with os and authorizes
+// exactly the keys with the given fingerprints.
+func (r *HetznerRescueResponse) Matches(os string, fingerprints []string) bool {
+	var activeOS string
+
+	err := json.Unmarshal(r.Rescue.OS, &activeOS)
+	if err != nil || !r.Rescue.Active || activeOS != os {
+		return false
+	}
+
+	authorized := make([]string, 0, len(r.Rescue.AuthorizedKeys))
+	for _, key := range r.Rescue.AuthorizedKeys {
+		authorized = append(authorized, key.Key.Fingerprint)
+	}
+
+	wanted := slices.Clone(fingerprints)
+
+	slices.Sort(authorized)
+	slices.Sort(wanted)
+
+	return slices.Equal(authorized, wanted)
+}
+
 // HetznerRenameResponse defines the response when renaming a server.
 type HetznerRenameResponse struct {
 	Server struct {
@@ -45,13 +90,17 @@ type HetznerRenameResponse struct {
 func (c *HetznerRobotClient) FetchServerByID(ctx context.Context, id string) (Server, error) {
 	path := "/server/" + id
 
-	resp, err := c.DoRequest(ctx, "GET", path, nil, "")
+	resp, err := c.cachedGet(ctx, path)
 	if err != nil {
 		return Server{}, fmt.Errorf("FetchServerByID request error: %w", err)
 	}
 
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotFound {
+		return Server{}, fmt.Errorf("%w: no server with id %s", ErrServerNotFound, id)
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		data, err := io.ReadAll(resp.Body)
 		if err != nil {
@@ -78,20 +127,22 @@ func (c *HetznerRobotClient) FetchServerByID(ctx context.Context, id string) (Se
 	return result.Server, nil
 }
 
-// FetchServersByIDs returns Server objects for a server ids.
+// FetchServersByIDs returns Server objects for a server ids. If some fail, the
+// others are returned along with an error wrapping TaskErrors.
 func (c *HetznerRobotClient) FetchServersByIDs(
 	ctx context.Context,
 	ids []string,
 ) ([]Server, error) {
-	servers, err := runConcurrentTasks(ctx, ids, c.FetchServerByID)
-	if err != nil {
-		return nil, fmt.Errorf("error fetching Servers: %w", err)
-	}
+	servers, err := runConcurrentTasks(ctx, ids, c.Config.MaxConcurrency, c.FetchServerByID)
 
 	sort.Slice(servers, func(i, j int) bool {
 		return servers[i].Number < servers[j].Number
 	})
 
+	if err != nil {
+		return servers, fmt.Errorf("error fetching Servers: %w", err)
+	}
+
 	return servers, nil
 }
 
@@ -99,7 +150,7 @@ func (c *HetznerRobotClient) FetchServersByIDs(
 func (c *HetznerRobotClient) FetchAllServers(ctx context.Context) ([]Server, error) {
 	path := "/server"
 
-	resp, err := c.DoRequest(ctx, "GET", path, nil, "")
+	resp, err := c.cachedGet(ctx, path)
 	if err != nil {
 		return nil, fmt.Errorf("FetchAllServers request error: %w", err)
 	}
@@ -132,6 +183,25 @@ func (c *HetznerRobotClient) FetchAllServers(ctx context.Context) ([]Server, err
 	return servers, nil
 }
 
+// ErrServerNotFound is returned when no server of the account matches.
+var ErrServerNotFound = errors.New("server not found")
+
+// FetchServerByIP returns the server whose main IPv4 address is ip.
+func (c *HetznerRobotClient) FetchServerByIP(ctx context.Context, ip string) (Server, error) {
+	servers, err := c.FetchAllServers(ctx)
+	if err != nil {
+		return Server{}, err
+	}
+
+	for _, server := range servers {
+		if server.IP == ip {
+			return server, nil
+		}
+	}
+
+	return Server{}, fmt.Errorf("%w: no server with main ip %s", ErrServerNotFound, ip)
+}
+
 // RenameServer renames a server.
 func (c *HetznerRobotClient) RenameServer(
 	ctx context.Context,
@@ -202,6 +272,44 @@ func (c *HetznerRobotClient) EnableRescueMode(
 
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read response body: %w", err)
+		}
+
+		if resp.StatusCode == http.StatusConflict &&
+			robotErrorCode(data) == bootAlreadyEnabledCode {
+			return nil, fmt.Errorf("%w on server %s", ErrRescueAlreadyActive, serverID)
+		}
+
+		return nil, fmt.Errorf("unexpected status code %d, body: %s", resp.StatusCode, data)
+	}
+
+	var rescueResp HetznerRescueResponse
+
+	err = json.NewDecoder(resp.Body).Decode(&rescueResp)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing rescue response: %w", err)
+	}
+
+	return &rescueResp, nil
+}
+
+// FetchRescue returns the rescue system status of a server, including the
+// root password of an active rescue system. The response holds a secret, so
+// it is never cached.
+func (c *HetznerRobotClient) FetchRescue(
+	ctx context.Context,
+	serverID string,
+) (*HetznerRescueResponse, error) {
+	resp, err := c.DoRequest(ctx, "GET", fmt.Sprintf("/boot/%s/rescue", serverID), nil, "")
+	if err != nil {
+		return nil, fmt.Errorf("error fetching rescue mode of server %s: %w", serverID, err)
+	}
+
+	defer resp.Body.Close()
+
 	if resp.StatusCode != http.StatusOK {
 		data, err := io.ReadAll(resp.Body)
 		if err != nil {
@@ -271,6 +379,26 @@ func (c *HetznerRobotClient) RebootServer(
 	return nil
 }
 
+// WakeOnLAN sends a Wake on LAN packet to a server.
+func (c *HetznerRobotClient) WakeOnLAN(ctx context.Context, serverID string) error {
+	resp, err := c.DoRequest(ctx, "POST", "/wol/"+serverID, nil, "")
+	if err != nil {
+		return fmt.Errorf("error sending Wake on LAN to server %s: %w", serverID, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("unable to read response body: %w", err)
+		}
+
+		return fmt.Errorf("unexpected status code %d, body: %s", resp.StatusCode, data)
+	}
+
+	return nil
+}
+
 func (c *HetznerRobotClient) powerOnServer(
 	ctx context.Context,
 	serverID string,
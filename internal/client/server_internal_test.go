@@ -0,0 +1,121 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEnableRescueModeAlreadyActive(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(
+		http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+			switch req.Method {
+			case http.MethodPost:
+				writer.WriteHeader(http.StatusConflict)
+				_, _ = writer.Write([]byte(
+					`{"error":{"status":409,"code":"BOOT_ALREADY_ENABLED","message":"already enabled"}}`,
+				))
+			case http.MethodGet:
+				_, _ = writer.Write([]byte(
+					`{"rescue":{"server_ip":"1.2.3.4","active":true,"password":"s3cret"}}`,
+				))
+			}
+		}),
+	)
+	defer server.Close()
+
+	client := newTestClient(t, &ProviderConfig{Username: "foo", Password: "bar", BaseURL: server.URL})
+
+	_, err := client.EnableRescueMode(context.Background(), "1", "linux", nil)
+	if !errors.Is(err, ErrRescueAlreadyActive) {
+		t.Fatalf("EnableRescueMode() error = %v, want ErrRescueAlreadyActive", err)
+	}
+
+	rescue, err := client.FetchRescue(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("FetchRescue() error: %v", err)
+	}
+
+	if !rescue.Rescue.Active || rescue.Rescue.ServerIP != "1.2.3.4" ||
+		rescue.Rescue.Password != "s3cret" {
+		t.Errorf("FetchRescue() = %+v", rescue.Rescue)
+	}
+}
+
+func TestRescueMatches(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		body         string
+		os           string
+		fingerprints []string
+		want         bool
+	}{
+		{
+			name:         "same os and keys",
+			body:         `{"rescue":{"active":true,"os":"linux","authorized_key":[{"key":{"fingerprint":"b"}},{"key":{"fingerprint":"a"}}]}}`,
+			os:           "linux",
+			fingerprints: []string{"a", "b"},
+			want:         true,
+		},
+		{
+			name: "other os",
+			body: `{"rescue":{"active":true,"os":"vkvm","authorized_key":[]}}`,
+			os:   "linux",
+			want: false,
+		},
+		{
+			name:         "other keys",
+			body:         `{"rescue":{"active":true,"os":"linux","authorized_key":[{"key":{"fingerprint":"a"}}]}}`,
+			os:           "linux",
+			fingerprints: []string{"b"},
+			want:         false,
+		},
+		{
+			name: "inactive",
+			body: `{"rescue":{"active":false,"os":["linux","vkvm"],"authorized_key":[]}}`,
+			os:   "linux",
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		var rescue HetznerRescueResponse
+
+		err := json.Unmarshal([]byte(tt.body), &rescue)
+		if err != nil {
+			t.Fatalf("%s: Unmarshal() error: %v", tt.name, err)
+		}
+
+		if got := rescue.Matches(tt.os, tt.fingerprints); got != tt.want {
+			t.Errorf("%s: Matches() = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFetchServerByIDNotFound(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(
+		http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+			writer.WriteHeader(http.StatusNotFound)
+			_, _ = writer.Write([]byte(
+				`{"error":{"status":404,"code":"SERVER_NOT_FOUND","message":"Server not found"}}`,
+			))
+		}),
+	)
+	defer server.Close()
+
+	client := newTestClient(t, &ProviderConfig{Username: "foo", Password: "bar", BaseURL: server.URL})
+
+	_, err := client.FetchServerByID(context.Background(), "1")
+	if !errors.Is(err, ErrServerNotFound) {
+		t.Fatalf("FetchServerByID() error = %v, want ErrServerNotFound", err)
+	}
+}
@@ -58,6 +58,43 @@ func (c *HetznerRobotClient) FetchSSHKey(ctx context.Context, fingerprint string
 	return result.Key, nil
 }
 
+// FetchAllSSHKeys returns all SSH keys registered in the account.
+func (c *HetznerRobotClient) FetchAllSSHKeys(ctx context.Context) ([]SSHKey, error) {
+	resp, err := c.cachedGet(ctx, "/key")
+	if err != nil {
+		return nil, fmt.Errorf("FetchAllSSHKeys request error: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	// Robot answers 404 when the account has no key.
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+
+		return nil, fmt.Errorf("FetchAllSSHKeys: status %d, body %s", resp.StatusCode, body)
+	}
+
+	var raw []struct {
+		Key SSHKey `json:"key"`
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&raw)
+	if err != nil {
+		return nil, fmt.Errorf("FetchAllSSHKeys decode error: %w", err)
+	}
+
+	keys := make([]SSHKey, len(raw))
+	for i, item := range raw {
+		keys[i] = item.Key
+	}
+
+	return keys, nil
+}
+
 // CreateSSHKey uploads a new SSH key. The fingerprint is computed by Hetzner.
 func (c *HetznerRobotClient) CreateSSHKey(ctx context.Context, name, data string) (SSHKey, error) {
 	form := url.Values{}
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFetchAllSSHKeys(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   []string
+	}{
+		{
+			name:   "keys",
+			status: http.StatusOK,
+			body:   `[{"key":{"name":"laptop","fingerprint":"aa:bb"}},{"key":{"name":"ci","fingerprint":"cc:dd"}}]`,
+			want:   []string{"aa:bb", "cc:dd"},
+		},
+		{
+			name:   "no key",
+			status: http.StatusNotFound,
+			body:   `{"error":{"status":404,"code":"NOT_FOUND","message":"Not found"}}`,
+			want:   []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(
+				http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+					writer.WriteHeader(tt.status)
+					_, _ = writer.Write([]byte(tt.body))
+				}),
+			)
+			defer server.Close()
+
+			client := newTestClient(t, &ProviderConfig{Username: "foo", Password: "bar", BaseURL: server.URL})
+
+			keys, err := client.FetchAllSSHKeys(context.Background())
+			if err != nil {
+				t.Fatalf("FetchAllSSHKeys() error: %v", err)
+			}
+
+			got := []string{}
+			for _, key := range keys {
+				got = append(got, key.Fingerprint)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("FetchAllSSHKeys() = %v, want %v", got, tt.want)
+			}
+
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("FetchAllSSHKeys()[%d] = %s, want %s", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// ErrInvalidCACert is returned when the CA bundle is not a valid list of PEM
+// encoded certificates.
+var ErrInvalidCACert = errors.New("invalid CA certificate bundle")
+
+// ErrInvalidProxy is returned when the proxy is not an absolute URL.
+var ErrInvalidProxy = errors.New("invalid proxy URL")
+
+// ParseCACerts parses a bundle of PEM encoded certificates, reporting which
+// block is invalid.
+func ParseCACerts(bundle string) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+
+	rest := []byte(bundle)
+
+	for index := 0; ; index++ {
+		var block *pem.Block
+
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+
+		if block.Type != "CERTIFICATE" {
+			return nil, fmt.Errorf(
+				"%w: block %d is a %q, expected a \"CERTIFICATE\"",
+				ErrInvalidCACert,
+				index,
+				block.Type,
+			)
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%w: block %d: %w", ErrInvalidCACert, index, err)
+		}
+
+		certs = append(certs, cert)
+	}
+
+	if len(certs) == 0 {
+		return nil, fmt.Errorf(
+			"%w: no PEM block found, expected \"-----BEGIN CERTIFICATE-----\"",
+			ErrInvalidCACert,
+		)
+	}
+
+	return certs, nil
+}
+
+// ParseProxyURL parses the URL of an HTTP proxy, e.g. http://proxy:3128.
+func ParseProxyURL(raw string) (*url.URL, error) {
+	proxy, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidProxy, err)
+	}
+
+	if proxy.Scheme == "" || proxy.Host == "" {
+		return nil, fmt.Errorf("%w: %q must include a scheme and a host", ErrInvalidProxy, raw)
+	}
+
+	return proxy, nil
+}
+
+// newHTTPClient builds the HTTP client from the transport settings of config.
+// Settings left to their zero value keep the Go defaults, and the proxy
+// defaults to the HTTP_PROXY and HTTPS_PROXY environment variables.
+func newHTTPClient(config *ProviderConfig) (*http.Client, error) {
+	defaultTransport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil, errors.New("unexpected default HTTP transport type")
+	}
+
+	transport := defaultTransport.Clone()
+
+	if config.MaxIdleConns > 0 {
+		// Every request goes to the same host.
+		transport.MaxIdleConns = config.MaxIdleConns
+		transport.MaxIdleConnsPerHost = config.MaxIdleConns
+	}
+
+	if config.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = config.IdleConnTimeout
+	}
+
+	if config.HTTPProxy != "" {
+		proxy, err := ParseProxyURL(config.HTTPProxy)
+		if err != nil {
+			return nil, err
+		}
+
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	//exhaustruct:ignore
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// Only for test endpoints, opted in explicitly.
+		InsecureSkipVerify: config.InsecureSkipVerify, //nolint:gosec
+	}
+
+	if config.CACertPEM != "" {
+		certs, err := ParseCACerts(config.CACertPEM)
+		if err != nil {
+			return nil, err
+		}
+
+		// Added to the system roots, as a TLS inspecting proxy may not be
+		// in the path of every environment.
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+
+		for _, cert := range certs {
+			pool.AddCert(cert)
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	transport.TLSClientConfig = tlsConfig
+
+	return &http.Client{Transport: transport, Timeout: config.Timeout}, nil
+}
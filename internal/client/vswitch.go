@@ -3,6 +3,7 @@ package client
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -47,6 +48,9 @@ type VSwitchCloudNet struct {
 	Gateway string `json:"gateway"`
 }
 
+// ErrVSwitchNotFound is returned when no vSwitch matches the requested id.
+var ErrVSwitchNotFound = errors.New("vswitch not found")
+
 // FetchVSwitchByID returns VSwitch object for a vSwitch id.
 func (c *HetznerRobotClient) FetchVSwitchByID(
 	ctx context.Context,
@@ -58,6 +62,10 @@ func (c *HetznerRobotClient) FetchVSwitchByID(
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotFound {
+		return VSwitch{}, ErrVSwitchNotFound
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		data, err := io.ReadAll(resp.Body)
 		if err != nil {
@@ -81,26 +89,28 @@ func (c *HetznerRobotClient) FetchVSwitchByID(
 	return vswitch, nil
 }
 
-// FetchVSwitchesByIDs returns VSwitch objects for a vSwitch ids.
+// FetchVSwitchesByIDs returns VSwitch objects for a vSwitch ids. If some fail,
+// the others are returned along with an error wrapping TaskErrors.
 func (c *HetznerRobotClient) FetchVSwitchesByIDs(
 	ctx context.Context,
 	ids []string,
 ) ([]VSwitch, error) {
-	vswitches, err := runConcurrentTasks(ctx, ids, c.FetchVSwitchByID)
-	if err != nil {
-		return nil, fmt.Errorf("error fetching vSwitches: %w", err)
-	}
+	vswitches, err := runConcurrentTasks(ctx, ids, c.Config.MaxConcurrency, c.FetchVSwitchByID)
 
 	sort.Slice(vswitches, func(i, j int) bool {
 		return vswitches[i].ID < vswitches[j].ID
 	})
 
+	if err != nil {
+		return vswitches, fmt.Errorf("error fetching vSwitches: %w", err)
+	}
+
 	return vswitches, nil
 }
 
 // FetchAllVSwitches returns all available vSwitches in the account.
 func (c *HetznerRobotClient) FetchAllVSwitches(ctx context.Context) ([]VSwitch, error) {
-	resp, err := c.DoRequest(ctx, "GET", "/vswitch", nil, "")
+	resp, err := c.cachedGet(ctx, "/vswitch")
 	if err != nil {
 		return nil, fmt.Errorf("error fetching all vSwitches: %w", err)
 	}
@@ -205,7 +215,8 @@ func (c *HetznerRobotClient) UpdateVSwitch(
 	return nil
 }
 
-// DeleteVSwitch deletes a VSwitch.
+// DeleteVSwitch cancels a VSwitch at cancellationDate ("now" or YYYY-MM-DD).
+// A 404 is treated as success.
 func (c *HetznerRobotClient) DeleteVSwitch(
 	ctx context.Context,
 	id string,
@@ -227,7 +238,8 @@ func (c *HetznerRobotClient) DeleteVSwitch(
 
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK &&
+		resp.StatusCode != http.StatusNotFound {
 		data, err := io.ReadAll(resp.Body)
 		if err != nil {
 			return fmt.Errorf("unable to read response body: %w", err)
@@ -319,17 +331,53 @@ func (c *HetznerRobotClient) RemoveVSwitchServers(
 	return nil
 }
 
-func isVSwitchReady(servers []VSwitchServer) bool {
+// ErrVSwitchServerFailed is returned when servers failed to connect to a vSwitch.
+var ErrVSwitchServerFailed = errors.New("vswitch server failed to connect")
+
+// VSwitchServerFailedError lists the servers that failed to connect to a vSwitch.
+type VSwitchServerFailedError struct {
+	VSwitchID string
+	Servers   []int
+}
+
+func (e *VSwitchServerFailedError) Error() string {
+	servers := make([]string, 0, len(e.Servers))
+	for _, server := range e.Servers {
+		servers = append(servers, strconv.Itoa(server))
+	}
+
+	return fmt.Sprintf(
+		"server(s) %s failed to connect to vSwitch %s",
+		strings.Join(servers, ", "),
+		e.VSwitchID,
+	)
+}
+
+func (e *VSwitchServerFailedError) Unwrap() error {
+	return ErrVSwitchServerFailed
+}
+
+// vswitchStatus reports whether no server is still processing and which
+// servers ended up in the "failed" state.
+func vswitchStatus(servers []VSwitchServer) (bool, []int) {
+	var failed []int
+
 	for _, server := range servers {
-		if server.Status == "processing" {
-			return false
+		switch server.Status {
+		case "processing":
+			return false, nil
+		case "failed":
+			failed = append(failed, server.ServerNumber)
 		}
 	}
 
-	return true
+	sort.Ints(failed)
+
+	return true, failed
 }
 
 // WaitForVSwitchReady wait for a VSwitch until ready after and update.
+// A *VSwitchServerFailedError is returned if any server ends up failed.
 func (c *HetznerRobotClient) WaitForVSwitchReady(
 	ctx context.Context,
 	id string,
@@ -340,7 +388,12 @@ func (c *HetznerRobotClient) WaitForVSwitchReady(
 			return fmt.Errorf("error fetching VSwitch while waiting: %w", err)
 		}
 
-		if isVSwitchReady(vsw.Servers) {
+		ready, failed := vswitchStatus(vsw.Servers)
+		if ready && len(failed) > 0 {
+			return &VSwitchServerFailedError{VSwitchID: id, Servers: failed}
+		}
+
+		if ready {
 			return nil
 		}
 
@@ -349,3 +402,94 @@ func (c *HetznerRobotClient) WaitForVSwitchReady(
 
 	return fmt.Errorf("timeout waiting for vSwitch %s to become ready", id)
 }
+
+// WaitForVSwitchServers waits for a vSwitch like WaitForVSwitchReady, only
+// reporting the failures of the given servers: other servers may have failed
+// long before, and the failures of removed servers do not matter.
+func (c *HetznerRobotClient) WaitForVSwitchServers(
+	ctx context.Context,
+	id string,
+	servers []int,
+) error {
+	err := c.WaitForVSwitchReady(ctx, id)
+
+	var failedErr *VSwitchServerFailedError
+	if !errors.As(err, &failedErr) {
+		return err
+	}
+
+	failed := intersectInts(failedErr.Servers, servers)
+	if len(failed) == 0 {
+		return nil
+	}
+
+	return &VSwitchServerFailedError{VSwitchID: id, Servers: failed}
+}
+
+// RetryFailedVSwitchServers removes and re-adds failed servers of a vSwitch up
+// to retries times, waiting for the vSwitch after each step. The caller must
+// hold the vSwitch lock.
+func (c *HetznerRobotClient) RetryFailedVSwitchServers(
+	ctx context.Context,
+	id string,
+	failed []int,
+	retries int,
+) error {
+	var err error
+
+	for attempt := range retries {
+		ctx := withAttempt(ctx, attempt+1)
+
+		servers := make([]VSwitchServer, 0, len(failed))
+		for _, number := range failed {
+			//exhaustruct:ignore
+			servers = append(servers, VSwitchServer{ServerNumber: number})
+		}
+
+		err = c.RemoveVSwitchServers(ctx, id, servers)
+		if err != nil {
+			return fmt.Errorf("error removing failed servers: %w", err)
+		}
+
+		err = c.WaitForVSwitchReady(ctx, id)
+		if err != nil && !errors.Is(err, ErrVSwitchServerFailed) {
+			return err
+		}
+
+		err = c.AddVSwitchServers(ctx, id, servers)
+		if err != nil {
+			return fmt.Errorf("error re-adding failed servers: %w", err)
+		}
+
+		err = c.WaitForVSwitchReady(ctx, id)
+
+		var failedErr *VSwitchServerFailedError
+		if !errors.As(err, &failedErr) {
+			return err
+		}
+
+		failed = intersectInts(failed, failedErr.Servers)
+		if len(failed) == 0 {
+			return nil
+		}
+	}
+
+	return &VSwitchServerFailedError{VSwitchID: id, Servers: failed}
+}
+
+func intersectInts(a, b []int) []int {
+	set := make(map[int]bool, len(b))
+	for _, v := range b {
+		set[v] = true
+	}
+
+	var result []int
+
+	for _, v := range a {
+		if set[v] {
+			result = append(result, v)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,178 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	vswitchBatchWindow = 2 * time.Second
+	// Bounds a batch, which no longer depends on the calls that joined it.
+	vswitchFlushTimeout = waitMaxRetries*waitDuration + 5*time.Minute
+	vswitchOpAdd        = "add"
+	vswitchOpRemove     = "remove"
+)
+
+// vswitchBatch collects the servers of concurrent attach or detach calls
+// targeting the same vSwitch.
+type vswitchBatch struct {
+	servers []VSwitchServer
+	done    chan struct{}
+	err     error
+}
+
+// vswitchCoordinator serialises changes per vSwitch and merges concurrent
+// server attachments into a single API call.
+type vswitchCoordinator struct {
+	mu      sync.Mutex
+	locks   *keyedMutex
+	pending map[string]*vswitchBatch
+	window  time.Duration
+}
+
+func newVSwitchCoordinator() *vswitchCoordinator {
+	return &vswitchCoordinator{
+		mu:      sync.Mutex{},
+		locks:   newKeyedMutex(),
+		pending: make(map[string]*vswitchBatch),
+		window:  vswitchBatchWindow,
+	}
+}
+
+// LockVSwitch acquires the provider-wide lock of a vSwitch and returns the
+// function releasing it. Every change to the servers of a vSwitch must hold it.
+func (c *HetznerRobotClient) LockVSwitch(id string) func() {
+	return c.vswitches.locks.lock(id)
+}
+
+// AttachVSwitchServer adds a server to a vSwitch. Concurrent calls for the
+// same vSwitch are merged into a single request followed by a single wait.
+func (c *HetznerRobotClient) AttachVSwitchServer(
+	ctx context.Context,
+	id string,
+	serverNumber int,
+) error {
+	return c.batchVSwitchServer(ctx, id, vswitchOpAdd, serverNumber)
+}
+
+// DetachVSwitchServer removes a server from a vSwitch. Concurrent calls for
+// the same vSwitch are merged into a single request followed by a single wait.
+func (c *HetznerRobotClient) DetachVSwitchServer(
+	ctx context.Context,
+	id string,
+	serverNumber int,
+) error {
+	return c.batchVSwitchServer(ctx, id, vswitchOpRemove, serverNumber)
+}
+
+func (c *HetznerRobotClient) batchVSwitchServer(
+	ctx context.Context,
+	id string,
+	operation string,
+	serverNumber int,
+) error {
+	coordinator := c.vswitches
+	key := operation + "/" + id
+
+	coordinator.mu.Lock()
+
+	batch, ok := coordinator.pending[key]
+	if !ok {
+		batch = &vswitchBatch{servers: nil, done: make(chan struct{}), err: nil}
+		coordinator.pending[key] = batch
+
+		// The batch outlives the call starting it: the other calls of the
+		// batch must not fail when its context is cancelled.
+		flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), vswitchFlushTimeout)
+
+		go func() {
+			defer cancel()
+
+			c.flushVSwitchBatch(flushCtx, id, operation, key, batch)
+		}()
+	}
+
+	//exhaustruct:ignore
+	batch.servers = append(batch.servers, VSwitchServer{ServerNumber: serverNumber})
+
+	coordinator.mu.Unlock()
+
+	select {
+	case <-batch.done:
+		return batch.err
+	case <-ctx.Done():
+		coordinator.withdraw(key, batch, serverNumber)
+
+		return fmt.Errorf("waiting for vSwitch %s batch: %w", id, ctx.Err())
+	}
+}
+
+// withdraw removes a server from a batch that is still collecting servers.
+// Once the batch is flushed, the change is sent regardless.
+func (v *vswitchCoordinator) withdraw(key string, batch *vswitchBatch, serverNumber int) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.pending[key] != batch {
+		return
+	}
+
+	batch.servers = slices.DeleteFunc(batch.servers, func(server VSwitchServer) bool {
+		return server.ServerNumber == serverNumber
+	})
+}
+
+func (c *HetznerRobotClient) flushVSwitchBatch(
+	ctx context.Context,
+	id string,
+	operation string,
+	key string,
+	batch *vswitchBatch,
+) {
+	coordinator := c.vswitches
+
+	defer close(batch.done)
+
+	time.Sleep(coordinator.window)
+
+	coordinator.mu.Lock()
+	delete(coordinator.pending, key)
+	servers := batch.servers
+	coordinator.mu.Unlock()
+
+	// Every call of the batch was cancelled.
+	if len(servers) == 0 {
+		return
+	}
+
+	unlock := c.LockVSwitch(id)
+	defer unlock()
+
+	// Only the failures of the servers of the batch are reported, and none
+	// on removal.
+	var (
+		err     error
+		watched []int
+	)
+
+	if operation == vswitchOpAdd {
+		err = c.AddVSwitchServers(ctx, id, servers)
+
+		for _, server := range servers {
+			watched = append(watched, server.ServerNumber)
+		}
+	} else {
+		err = c.RemoveVSwitchServers(ctx, id, servers)
+	}
+
+	if err != nil {
+		batch.err = err
+
+		return
+	}
+
+	batch.err = c.WaitForVSwitchServers(ctx, id, watched)
+}
@@ -0,0 +1,193 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAttachVSwitchServerBatching(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu       sync.Mutex
+		posts    int
+		attached []string
+	)
+
+	server := httptest.NewServer(
+		http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+			switch {
+			case req.Method == http.MethodPost && req.URL.Path == "/vswitch/1/server":
+				err := req.ParseForm()
+				if err != nil {
+					http.Error(writer, err.Error(), http.StatusBadRequest)
+
+					return
+				}
+
+				mu.Lock()
+				posts++
+				attached = append(attached, req.PostForm["server[]"]...)
+				mu.Unlock()
+			case req.Method == http.MethodGet && req.URL.Path == "/vswitch/1":
+				_, _ = writer.Write([]byte(`{"id":1,"server":[{"server_number":1,"status":"ready"}]}`))
+			default:
+				http.Error(writer, "No route found", http.StatusBadRequest)
+			}
+		}),
+	)
+	defer server.Close()
+
+	client := newTestClient(t, &ProviderConfig{Username: "foo", Password: "bar", BaseURL: server.URL})
+	client.vswitches.window = 100 * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+
+	errs := make([]error, 3)
+
+	for i := range errs {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			errs[i] = client.AttachVSwitchServer(ctx, "1", i+1)
+		}(i)
+	}
+
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("AttachVSwitchServer(%d): %v", i+1, err)
+		}
+	}
+
+	if posts != 1 {
+		t.Errorf("requests: want 1, got %d", posts)
+	}
+
+	slices.Sort(attached)
+
+	if !slices.Equal(attached, []string{"1", "2", "3"}) {
+		t.Errorf("attached servers: want [1 2 3], got %v", attached)
+	}
+}
+
+func TestVSwitchBatchFailures(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(
+		http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+			switch {
+			case req.URL.Path == "/vswitch/1/server":
+			case req.Method == http.MethodGet && req.URL.Path == "/vswitch/1":
+				_, _ = writer.Write([]byte(`{"id":1,"server":[{"server_number":1,"status":"ready"},` +
+					`{"server_number":2,"status":"failed"},{"server_number":9,"status":"failed"}]}`))
+			default:
+				http.Error(writer, "No route found", http.StatusBadRequest)
+			}
+		}),
+	)
+	defer server.Close()
+
+	client := newTestClient(t, &ProviderConfig{Username: "foo", Password: "bar", BaseURL: server.URL})
+	client.vswitches.window = 10 * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Server 9 failed on its own, it does not concern server 1.
+	err := client.AttachVSwitchServer(ctx, "1", 1)
+	if err != nil {
+		t.Errorf("AttachVSwitchServer(1): %v", err)
+	}
+
+	var failedErr *VSwitchServerFailedError
+
+	err = client.AttachVSwitchServer(ctx, "1", 2)
+	if !errors.As(err, &failedErr) || !slices.Equal(failedErr.Servers, []int{2}) {
+		t.Errorf("AttachVSwitchServer(2): want server 2 failed, got %v", err)
+	}
+
+	// A failed server being removed is not an error.
+	err = client.DetachVSwitchServer(ctx, "1", 2)
+	if err != nil {
+		t.Errorf("DetachVSwitchServer(2): %v", err)
+	}
+}
+
+func TestVSwitchBatchCancelledCall(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu       sync.Mutex
+		attached []string
+	)
+
+	server := httptest.NewServer(
+		http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+			switch {
+			case req.Method == http.MethodPost && req.URL.Path == "/vswitch/1/server":
+				_ = req.ParseForm()
+
+				mu.Lock()
+				attached = append(attached, req.PostForm["server[]"]...)
+				mu.Unlock()
+			case req.Method == http.MethodGet && req.URL.Path == "/vswitch/1":
+				_, _ = writer.Write([]byte(`{"id":1,"server":[{"server_number":2,"status":"ready"}]}`))
+			default:
+				http.Error(writer, "No route found", http.StatusBadRequest)
+			}
+		}),
+	)
+	defer server.Close()
+
+	client := newTestClient(t, &ProviderConfig{Username: "foo", Password: "bar", BaseURL: server.URL})
+	client.vswitches.window = 200 * time.Millisecond
+
+	// The call starting the batch is cancelled while the batch collects
+	// servers: its server is withdrawn, the other call goes through.
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+
+	leaderErr := make(chan error, 1)
+
+	go func() {
+		leaderErr <- client.AttachVSwitchServer(leaderCtx, "1", 1)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+
+	followerErr := make(chan error, 1)
+
+	go func() {
+		followerErr <- client.AttachVSwitchServer(context.Background(), "1", 2)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	cancelLeader()
+
+	if err := <-leaderErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled AttachVSwitchServer(1): want context.Canceled, got %v", err)
+	}
+
+	if err := <-followerErr; err != nil {
+		t.Errorf("AttachVSwitchServer(2): %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if !slices.Equal(attached, []string{"2"}) {
+		t.Errorf("attached servers: want [2], got %v", attached)
+	}
+}
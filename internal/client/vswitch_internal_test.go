@@ -0,0 +1,67 @@
+package client
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestVSwitchStatus(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		servers    []VSwitchServer
+		wantReady  bool
+		wantFailed []int
+	}{
+		{
+			name:       "No servers",
+			servers:    nil,
+			wantReady:  true,
+			wantFailed: nil,
+		},
+		{
+			name: "All ready",
+			servers: []VSwitchServer{
+				{ServerNumber: 1, ServerIP: "", ServerIPv6Net: "", Status: "ready"},
+				{ServerNumber: 2, ServerIP: "", ServerIPv6Net: "", Status: "ready"},
+			},
+			wantReady:  true,
+			wantFailed: nil,
+		},
+		{
+			name: "Processing",
+			servers: []VSwitchServer{
+				{ServerNumber: 1, ServerIP: "", ServerIPv6Net: "", Status: "failed"},
+				{ServerNumber: 2, ServerIP: "", ServerIPv6Net: "", Status: "processing"},
+			},
+			wantReady:  false,
+			wantFailed: nil,
+		},
+		{
+			name: "Failed",
+			servers: []VSwitchServer{
+				{ServerNumber: 3, ServerIP: "", ServerIPv6Net: "", Status: "failed"},
+				{ServerNumber: 2, ServerIP: "", ServerIPv6Net: "", Status: "ready"},
+				{ServerNumber: 1, ServerIP: "", ServerIPv6Net: "", Status: "failed"},
+			},
+			wantReady:  true,
+			wantFailed: []int{1, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ready, failed := vswitchStatus(tt.servers)
+			if ready != tt.wantReady {
+				t.Errorf("ready: want %t, got %t", tt.wantReady, ready)
+			}
+
+			if !slices.Equal(failed, tt.wantFailed) {
+				t.Errorf("failed: want %v, got %v", tt.wantFailed, failed)
+			}
+		})
+	}
+}
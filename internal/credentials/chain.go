@@ -0,0 +1,75 @@
+package credentials
+
+import (
+	neturl "net/url"
+	"os"
+	"path/filepath"
+)
+
+// Options are the explicit settings of the default chain, e.g. provider
+// attributes or command-line flags. Empty values are skipped.
+type Options struct {
+	// Name identifies Username and Password, e.g. "provider configuration".
+	Name     string
+	Username string
+	Password string
+	// Command prints the credentials as JSON.
+	Command []string
+	// File holds the credentials of Profile, in INI or JSON format.
+	File    string
+	Profile string
+	// URL is the Robot API, its host is looked up in the netrc file.
+	URL string
+}
+
+// Chain returns the sources of the provider and the command-line tools, in
+// order: the explicit values, HETZNERROBOT_USERNAME/HETZNERROBOT_PASSWORD,
+// HETZNERROBOT_PASSWORD_FILE, the command, the profile and the netrc file.
+func Chain(opts Options) []Source {
+	sources := []Source{
+		Static(opts.Name, opts.Username, opts.Password),
+		Static(
+			"HETZNERROBOT_USERNAME/HETZNERROBOT_PASSWORD",
+			os.Getenv("HETZNERROBOT_USERNAME"),
+			os.Getenv("HETZNERROBOT_PASSWORD"),
+		),
+	}
+
+	if path := os.Getenv("HETZNERROBOT_PASSWORD_FILE"); path != "" {
+		sources = append(sources, PasswordFile(path))
+	}
+
+	if len(opts.Command) > 0 {
+		sources = append(sources, Command(opts.Command))
+	}
+
+	if opts.File != "" {
+		sources = append(sources, Profile(opts.File, opts.Profile))
+	}
+
+	if path := netrcPath(); path != "" {
+		host := opts.URL
+
+		if parsed, err := neturl.Parse(opts.URL); err == nil && parsed.Hostname() != "" {
+			host = parsed.Hostname()
+		}
+
+		sources = append(sources, Netrc(path, host))
+	}
+
+	return sources
+}
+
+// netrcPath returns the netrc file, NETRC overriding ~/.netrc like curl.
+func netrcPath() string {
+	if path := os.Getenv("NETRC"); path != "" {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(home, ".netrc")
+}
@@ -0,0 +1,345 @@
+// Package credentials resolves the Hetzner Robot credentials from a chain of
+// sources: provider attributes, environment variables, files and commands.
+package credentials
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ErrMissingCredentials is returned when no source provides both the username
+// and the password.
+var ErrMissingCredentials = errors.New("missing credentials")
+
+// Source provides the username, the password or both. Empty values are
+// looked up in the next sources.
+type Source struct {
+	// Name identifies the source in logs and errors, never its values.
+	Name  string
+	Fetch func(ctx context.Context) (username, password string, err error)
+}
+
+// Credentials are the resolved credentials and the name of the source each
+// value comes from.
+type Credentials struct {
+	Username       string
+	Password       string
+	UsernameSource string
+	PasswordSource string
+}
+
+// Resolve walks sources in order, taking each value from the first source
+// providing it. Later sources are not fetched once both values are known.
+func Resolve(ctx context.Context, sources []Source) (Credentials, error) {
+	var creds Credentials
+
+	names := make([]string, 0, len(sources))
+
+	for _, source := range sources {
+		if creds.Username != "" && creds.Password != "" {
+			break
+		}
+
+		names = append(names, source.Name)
+
+		username, password, err := source.Fetch(ctx)
+		if err != nil {
+			return creds, fmt.Errorf("%s: %w", source.Name, err)
+		}
+
+		if creds.Username == "" && username != "" {
+			creds.Username = username
+			creds.UsernameSource = source.Name
+		}
+
+		if creds.Password == "" && password != "" {
+			creds.Password = password
+			creds.PasswordSource = source.Name
+		}
+	}
+
+	if creds.Username == "" || creds.Password == "" {
+		return creds, fmt.Errorf(
+			"%w: username and password not found in %s",
+			ErrMissingCredentials,
+			strings.Join(names, ", "),
+		)
+	}
+
+	return creds, nil
+}
+
+// Static returns a source of fixed values, e.g. provider attributes.
+func Static(name, username, password string) Source {
+	return Source{
+		Name: name,
+		Fetch: func(context.Context) (string, string, error) {
+			return username, password, nil
+		},
+	}
+}
+
+// PasswordFile returns a source reading the password from a file, e.g. a
+// Docker or Kubernetes secret. Trailing newlines are trimmed.
+func PasswordFile(path string) Source {
+	return Source{
+		Name: "password file " + path,
+		Fetch: func(context.Context) (string, string, error) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return "", "", fmt.Errorf("reading password: %w", err)
+			}
+
+			return "", strings.TrimRight(string(data), "\r\n"), nil
+		},
+	}
+}
+
+// storedCredentials are the values printed by a credentials command or stored
+// in a profile.
+type storedCredentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// Command returns a source running an external helper, like the AWS
+// credential_process, which prints {"username": "...", "password": "..."}.
+func Command(args []string) Source {
+	return Source{
+		Name: "credentials command",
+		Fetch: func(ctx context.Context) (string, string, error) {
+			if len(args) == 0 {
+				return "", "", errors.New("empty command")
+			}
+
+			var stdout, stderr bytes.Buffer
+
+			// The command is configured by the user on purpose.
+			cmd := exec.CommandContext(ctx, args[0], args[1:]...) //nolint:gosec
+			cmd.Stdout = &stdout
+			cmd.Stderr = &stderr
+
+			err := cmd.Run()
+			if err != nil {
+				return "", "", fmt.Errorf(
+					"running %s: %w: %s",
+					args[0],
+					err,
+					strings.TrimSpace(stderr.String()),
+				)
+			}
+
+			var output storedCredentials
+
+			// The output holds the password, do not include it in the error.
+			if json.Unmarshal(stdout.Bytes(), &output) != nil {
+				return "", "", fmt.Errorf(
+					"%s did not print a JSON object with username and password",
+					args[0],
+				)
+			}
+
+			return output.Username, output.Password, nil
+		},
+	}
+}
+
+// Profile returns a source reading a named profile of a credentials file, in
+// INI format:
+//
+//	[default]
+//	username = robot-user
+//	password = secret
+//
+// or in JSON format:
+//
+//	{"default": {"username": "robot-user", "password": "secret"}}
+func Profile(path, profile string) Source {
+	return Source{
+		Name: fmt.Sprintf("profile %q of credentials file %s", profile, path),
+		Fetch: func(context.Context) (string, string, error) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return "", "", fmt.Errorf("reading credentials file: %w", err)
+			}
+
+			var profiles map[string]storedCredentials
+
+			if strings.HasPrefix(strings.TrimSpace(string(data)), "{") {
+				profiles, err = parseJSONProfiles(data)
+			} else {
+				profiles, err = parseINIProfiles(data)
+			}
+
+			if err != nil {
+				return "", "", err
+			}
+
+			values, ok := profiles[profile]
+			if !ok {
+				return "", "", fmt.Errorf("profile %q not found", profile)
+			}
+
+			return values.Username, values.Password, nil
+		},
+	}
+}
+
+func parseJSONProfiles(data []byte) (map[string]storedCredentials, error) {
+	var profiles map[string]storedCredentials
+
+	// The syntax error may quote the file, do not include it.
+	if json.Unmarshal(data, &profiles) != nil {
+		return nil, errors.New("invalid JSON credentials file")
+	}
+
+	return profiles, nil
+}
+
+func parseINIProfiles(data []byte) (map[string]storedCredentials, error) {
+	profiles := map[string]storedCredentials{}
+
+	var section string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";"):
+			continue
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			profiles[section] = storedCredentials{Username: "", Password: ""}
+
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if !found || section == "" {
+			return nil, fmt.Errorf("invalid INI credentials file: line %d", number)
+		}
+
+		values := profiles[section]
+
+		switch strings.TrimSpace(key) {
+		case "username":
+			values.Username = strings.TrimSpace(value)
+		case "password":
+			values.Password = strings.TrimSpace(value)
+		}
+
+		profiles[section] = values
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return nil, fmt.Errorf("reading credentials file: %w", err)
+	}
+
+	return profiles, nil
+}
+
+// Netrc returns a source reading the login and password of host from a netrc
+// file, falling back to its default entry. A missing file provides nothing.
+func Netrc(path, host string) Source {
+	return Source{
+		Name: "netrc file " + path,
+		Fetch: func(context.Context) (string, string, error) {
+			data, err := os.ReadFile(path)
+			if errors.Is(err, fs.ErrNotExist) {
+				return "", "", nil
+			}
+
+			if err != nil {
+				return "", "", fmt.Errorf("reading netrc: %w", err)
+			}
+
+			username, password := parseNetrc(data, host)
+
+			return username, password, nil
+		},
+	}
+}
+
+// netrcEntry is a machine or default entry of a netrc file.
+type netrcEntry struct {
+	machine  string
+	login    string
+	password string
+}
+
+func parseNetrc(data []byte, host string) (string, string) {
+	var (
+		entries  []netrcEntry
+		inMacro  bool
+		previous string
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		// A macro definition ends with an empty line.
+		if inMacro {
+			inMacro = line != ""
+
+			continue
+		}
+
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		for _, token := range strings.Fields(line) {
+			switch {
+			case previous == "macdef":
+				inMacro = true
+			case previous == "machine":
+				entries = append(entries, netrcEntry{machine: token, login: "", password: ""})
+			case previous == "login" && len(entries) > 0:
+				entries[len(entries)-1].login = token
+			case previous == "password" && len(entries) > 0:
+				entries[len(entries)-1].password = token
+			case previous == "account":
+			case token == "default":
+				entries = append(entries, netrcEntry{machine: "", login: "", password: ""})
+			default:
+				previous = token
+
+				continue
+			}
+
+			// The token was a value, or default which takes none.
+			previous = ""
+		}
+	}
+
+	var fallback *netrcEntry
+
+	for i := range entries {
+		if entries[i].machine == host {
+			return entries[i].login, entries[i].password
+		}
+
+		if entries[i].machine == "" && fallback == nil {
+			fallback = &entries[i]
+		}
+	}
+
+	if fallback != nil {
+		return fallback.login, fallback.password
+	}
+
+	return "", ""
+}
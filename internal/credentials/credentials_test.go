@@ -0,0 +1,249 @@
+package credentials_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/credentials"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+
+	err := os.WriteFile(path, []byte(content), 0o600)
+	if err != nil {
+		t.Fatalf("writing %s: %v", name, err)
+	}
+
+	return path
+}
+
+func TestResolvePrecedence(t *testing.T) {
+	t.Parallel()
+
+	fetched := false
+	unused := credentials.Source{
+		Name: "unused",
+		Fetch: func(context.Context) (string, string, error) {
+			fetched = true
+
+			return "", "", nil
+		},
+	}
+
+	creds, err := credentials.Resolve(context.Background(), []credentials.Source{
+		credentials.Static("attributes", "", ""),
+		credentials.Static("environment", "env-user", ""),
+		credentials.PasswordFile(writeFile(t, "password", "file-secret\n")),
+		credentials.Static("netrc", "netrc-user", "netrc-secret"),
+		unused,
+	})
+	if err != nil {
+		t.Fatalf("Resolve() error: %v", err)
+	}
+
+	if creds.Username != "env-user" || creds.UsernameSource != "environment" {
+		t.Errorf("username %q from %q, want env-user from environment", creds.Username, creds.UsernameSource)
+	}
+
+	if creds.Password != "file-secret" || !strings.HasPrefix(creds.PasswordSource, "password file") {
+		t.Errorf("password %q from %q, want file-secret from the password file", creds.Password, creds.PasswordSource)
+	}
+
+	if fetched {
+		t.Error("source fetched after both values were resolved")
+	}
+}
+
+func TestResolveMissing(t *testing.T) {
+	t.Parallel()
+
+	_, err := credentials.Resolve(context.Background(), []credentials.Source{
+		credentials.Static("attributes", "user", ""),
+		credentials.Netrc(filepath.Join(t.TempDir(), "missing"), "robot-ws.your-server.de"),
+	})
+	if !errors.Is(err, credentials.ErrMissingCredentials) {
+		t.Errorf("Resolve() error = %v, want ErrMissingCredentials", err)
+	}
+}
+
+func TestProfile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		content      string
+		profile      string
+		wantUsername string
+		wantPassword string
+		wantErr      bool
+	}{
+		{
+			name:         "INI",
+			content:      "# comment\n[default]\nusername = foo\npassword = bar\n\n[prod]\nusername=prod\npassword=s3cret\n",
+			profile:      "prod",
+			wantUsername: "prod",
+			wantPassword: "s3cret",
+			wantErr:      false,
+		},
+		{
+			name:         "JSON",
+			content:      `{"default": {"username": "foo", "password": "bar"}}`,
+			profile:      "default",
+			wantUsername: "foo",
+			wantPassword: "bar",
+			wantErr:      false,
+		},
+		{
+			name:         "Missing profile",
+			content:      "[default]\nusername = foo\n",
+			profile:      "prod",
+			wantUsername: "",
+			wantPassword: "",
+			wantErr:      true,
+		},
+		{
+			name:         "Invalid INI",
+			content:      "username = foo\n",
+			profile:      "default",
+			wantUsername: "",
+			wantPassword: "",
+			wantErr:      true,
+		},
+		{
+			name:         "Invalid JSON",
+			content:      `{"default": {"password": s3cret}}`,
+			profile:      "default",
+			wantUsername: "",
+			wantPassword: "",
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			source := credentials.Profile(writeFile(t, "credentials", tt.content), tt.profile)
+
+			username, password, err := source.Fetch(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Fetch() error = %v, wantErr %t", err, tt.wantErr)
+			}
+
+			if err != nil && strings.Contains(err.Error(), "s3cret") {
+				t.Errorf("error leaks the password: %v", err)
+			}
+
+			if username != tt.wantUsername || password != tt.wantPassword {
+				t.Errorf("Fetch() = %q, %q, want %q, %q", username, password, tt.wantUsername, tt.wantPassword)
+			}
+		})
+	}
+}
+
+func TestCommand(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		script       string
+		wantUsername string
+		wantErr      bool
+	}{
+		{
+			name:         "JSON output",
+			script:       `echo '{"username": "foo", "password": "bar"}'`,
+			wantUsername: "foo",
+			wantErr:      false,
+		},
+		{name: "Failure", script: "echo denied >&2; exit 1", wantUsername: "", wantErr: true},
+		{name: "Invalid output", script: "echo s3cret", wantUsername: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			source := credentials.Command([]string{"sh", "-c", tt.script})
+
+			username, _, err := source.Fetch(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Fetch() error = %v, wantErr %t", err, tt.wantErr)
+			}
+
+			if err != nil && strings.Contains(err.Error(), "s3cret") {
+				t.Errorf("error leaks the output: %v", err)
+			}
+
+			if username != tt.wantUsername {
+				t.Errorf("Fetch() username = %q, want %q", username, tt.wantUsername)
+			}
+		})
+	}
+}
+
+func TestNetrc(t *testing.T) {
+	t.Parallel()
+
+	path := writeFile(t, "netrc", `machine example.com login other password other
+macdef init
+cd /pub
+machine robot-ws.your-server.de
+
+machine robot-ws.your-server.de
+	login foo
+	password bar
+default login anonymous password guest
+`)
+
+	tests := []struct {
+		host         string
+		wantUsername string
+		wantPassword string
+	}{
+		{host: "robot-ws.your-server.de", wantUsername: "foo", wantPassword: "bar"},
+		{host: "unknown.example.com", wantUsername: "anonymous", wantPassword: "guest"},
+	}
+
+	for _, tt := range tests {
+		username, password, err := credentials.Netrc(path, tt.host).Fetch(context.Background())
+		if err != nil {
+			t.Fatalf("Fetch() error: %v", err)
+		}
+
+		if username != tt.wantUsername || password != tt.wantPassword {
+			t.Errorf("%s: Fetch() = %q, %q, want %q, %q", tt.host, username, password, tt.wantUsername, tt.wantPassword)
+		}
+	}
+}
+
+func TestChain(t *testing.T) {
+	t.Setenv("HETZNERROBOT_USERNAME", "env-user")
+	t.Setenv("HETZNERROBOT_PASSWORD", "")
+	t.Setenv("HETZNERROBOT_PASSWORD_FILE", writeFile(t, "password", "file-secret\n"))
+	t.Setenv("NETRC", filepath.Join(t.TempDir(), "missing"))
+
+	creds, err := credentials.Resolve(context.Background(), credentials.Chain(credentials.Options{
+		Name:     "command line",
+		Username: "",
+		Password: "",
+		Command:  nil,
+		File:     writeFile(t, "credentials", "[default]\nusername = file-user\npassword = profile-secret\n"),
+		Profile:  "default",
+		URL:      "https://robot-ws.your-server.de",
+	}))
+	if err != nil {
+		t.Fatalf("Resolve() error: %v", err)
+	}
+
+	if creds.Username != "env-user" || creds.Password != "file-secret" {
+		t.Errorf("credentials %q/%q, want env-user and the password file", creds.Username, creds.Password)
+	}
+}
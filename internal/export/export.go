@@ -0,0 +1,202 @@
+// Package export dumps the objects of a Robot account, as JSON or as
+// Terraform configuration with import blocks to bring them under management.
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/cli"
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/client"
+)
+
+const (
+	formatHCL  = "hcl"
+	formatJSON = "json"
+)
+
+// ErrUnknownFormat is returned for a format other than hcl and json.
+var ErrUnknownFormat = errors.New("unknown format")
+
+// Inventory holds the objects of an account. Cancelled servers and vSwitches
+// are left out, they cannot be managed anymore.
+type Inventory struct {
+	Servers   []client.Server   `json:"servers"`
+	VSwitches []client.VSwitch  `json:"vswitches"`
+	Firewalls []ServerFirewall  `json:"firewalls"`
+	Failovers []client.Failover `json:"failovers"`
+	SSHKeys   []client.SSHKey   `json:"ssh_keys"`
+}
+
+// ServerFirewall is the firewall of a server.
+type ServerFirewall struct {
+	ServerNumber int    `json:"server_number"`
+	ServerName   string `json:"server_name"`
+	client.Firewall
+}
+
+// Run runs the export subcommand with args, writing the inventory to stdout
+// and usage and warnings to stderr.
+func Run(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	var config cli.Config
+
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	format := flags.String("format", formatHCL, "Output format, hcl or json")
+	config.RegisterFlags(flags)
+
+	err := flags.Parse(args)
+	if err != nil {
+		return fmt.Errorf("error parsing flags: %w", err)
+	}
+
+	if *format != formatHCL && *format != formatJSON {
+		return fmt.Errorf("%w %q, want %s or %s", ErrUnknownFormat, *format, formatHCL, formatJSON)
+	}
+
+	hClient, err := config.Client(ctx)
+	if err != nil {
+		return err
+	}
+
+	inventory, err := Fetch(ctx, hClient, func(msg string) {
+		_, _ = fmt.Fprintln(stderr, "warning: "+msg)
+	})
+	if err != nil {
+		return err
+	}
+
+	if *format == formatJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+
+		err = encoder.Encode(inventory)
+		if err != nil {
+			return fmt.Errorf("error writing inventory: %w", err)
+		}
+
+		return nil
+	}
+
+	return WriteHCL(stdout, inventory)
+}
+
+// Fetch returns the inventory of the account. A server whose firewall Robot
+// refuses to return, e.g. because its product has none, is reported to warn
+// and left out of the firewalls.
+func Fetch(
+	ctx context.Context,
+	hClient *client.HetznerRobotClient,
+	warn func(msg string),
+) (Inventory, error) {
+	var inventory Inventory
+
+	servers, err := hClient.FetchAllServers(ctx)
+	if err != nil {
+		return inventory, fmt.Errorf("error fetching servers: %w", err)
+	}
+
+	for _, server := range servers {
+		if !server.Cancelled {
+			inventory.Servers = append(inventory.Servers, server)
+		}
+	}
+
+	inventory.VSwitches, err = fetchVSwitches(ctx, hClient)
+	if err != nil {
+		return inventory, err
+	}
+
+	inventory.Firewalls, err = fetchFirewalls(ctx, hClient, inventory.Servers, warn)
+	if err != nil {
+		return inventory, err
+	}
+
+	inventory.Failovers, err = hClient.FetchAllFailovers(ctx)
+	if err != nil {
+		return inventory, fmt.Errorf("error fetching failover IPs: %w", err)
+	}
+
+	inventory.SSHKeys, err = hClient.FetchAllSSHKeys(ctx)
+	if err != nil {
+		return inventory, fmt.Errorf("error fetching SSH keys: %w", err)
+	}
+
+	return inventory, nil
+}
+
+// fetchVSwitches returns the vSwitches with their servers, which the listing
+// of Robot does not include.
+func fetchVSwitches(ctx context.Context, hClient *client.HetznerRobotClient) ([]client.VSwitch, error) {
+	all, err := hClient.FetchAllVSwitches(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching vSwitches: %w", err)
+	}
+
+	ids := make([]string, 0, len(all))
+
+	for _, vsw := range all {
+		if !vsw.Cancelled {
+			ids = append(ids, strconv.Itoa(vsw.ID))
+		}
+	}
+
+	vswitches, err := hClient.FetchVSwitchesByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching vSwitches: %w", err)
+	}
+
+	return vswitches, nil
+}
+
+// fetchFirewalls returns the firewalls of servers, warning about the ones
+// Robot refused to return. Other failures, e.g. a cancelled context or a
+// network error, are returned, as they say nothing about the server.
+func fetchFirewalls(
+	ctx context.Context,
+	hClient *client.HetznerRobotClient,
+	servers []client.Server,
+	warn func(msg string),
+) ([]ServerFirewall, error) {
+	ips := make([]string, len(servers))
+	for i, server := range servers {
+		ips[i] = server.IP
+	}
+
+	firewalls, err := hClient.FetchFirewallsByIPs(ctx, ips)
+
+	var failed client.TaskErrors
+	if err != nil && !errors.As(err, &failed) {
+		return nil, err
+	}
+
+	for _, ip := range failed.IDs() {
+		if !errors.Is(failed[ip], client.ErrUnexpectedStatus) {
+			return nil, fmt.Errorf("error fetching firewall of %s: %w", ip, failed[ip])
+		}
+	}
+
+	// The firewalls that could be read, in the order of servers.
+	result := make([]ServerFirewall, 0, len(firewalls))
+
+	for _, server := range servers {
+		if taskErr, ok := failed[server.IP]; ok {
+			warn(fmt.Sprintf("skipping firewall of server %d: %v", server.Number, taskErr))
+
+			continue
+		}
+
+		result = append(result, ServerFirewall{
+			ServerNumber: server.Number,
+			ServerName:   server.ServerName,
+			Firewall:     firewalls[len(result)],
+		})
+	}
+
+	return result, nil
+}
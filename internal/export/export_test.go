@@ -0,0 +1,202 @@
+package export_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/export"
+)
+
+// mockRobot serves an account whose firewall of server 2 cannot be read,
+// dropping the connection instead if dropFirewall is true.
+func mockRobot(t *testing.T, dropFirewall bool) *httptest.Server {
+	t.Helper()
+
+	responses := map[string]string{
+		"/server": `[` +
+			`{"server":{"server_ip":"1.2.3.4","server_number":1,"server_name":"web-1","dc":"FSN1-DC14"}},` +
+			`{"server":{"server_ip":"5.6.7.8","server_number":2,"server_name":"","dc":"HEL1-DC2"}},` +
+			`{"server":{"server_ip":"9.8.7.6","server_number":3,"server_name":"old-1","cancelled":true}}]`,
+		"/key": `[{"key":{"name":"laptop","fingerprint":"c3:c9:0a:0f:b8:2e:b4:51:9f:5a:39:24:db:ae:16:95",` +
+			`"type":"ED25519","size":256,"data":"ssh-ed25519 AAAA","created_at":"2024-01-01 00:00:00"}}]`,
+		"/failover": `[{"failover":{"ip":"9.9.9.9","netmask":"255.255.255.255","server_ip":"5.6.7.8",` +
+			`"server_number":2,"active_server_ip":"1.2.3.4"}}]`,
+		"/vswitch": `[{"id":4321,"name":"private net","vlan":4000,"cancelled":false},` +
+			`{"id":4322,"name":"gone","vlan":4001,"cancelled":true}]`,
+		"/vswitch/4321": `{"id":4321,"name":"private net","vlan":4000,"cancelled":false,` +
+			`"server":[{"server_number":1,"server_ip":"1.2.3.4","status":"ready"},` +
+			`{"server_number":2,"server_ip":"5.6.7.8","status":"ready"}],"subnets":[],"cloud_networks":[]}`,
+		"/firewall/1.2.3.4": `{"firewall":{"server_ip":"1.2.3.4","status":"active","whitelist_hos":true,` +
+			`"rules":{"input":[{"ip_version":"ipv4","name":"ssh","dst_port":"22","protocol":"tcp","action":"accept"},` +
+			`{"name":"Deny others","action":"discard"}]}}}`,
+	}
+
+	robot := httptest.NewServer(
+		http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+			if dropFirewall && req.URL.Path == "/firewall/5.6.7.8" {
+				panic(http.ErrAbortHandler)
+			}
+
+			body, ok := responses[req.URL.Path]
+			if !ok {
+				http.NotFound(writer, req)
+
+				return
+			}
+
+			_, _ = writer.Write([]byte(body))
+		}),
+	)
+	t.Cleanup(robot.Close)
+
+	t.Setenv("HETZNERROBOT_USERNAME", "foo")
+	t.Setenv("HETZNERROBOT_PASSWORD", "bar")
+
+	return robot
+}
+
+func TestRunHCL(t *testing.T) {
+	robot := mockRobot(t, false)
+
+	var stdout, stderr bytes.Buffer
+
+	err := export.Run(context.Background(), []string{"--url", robot.URL}, &stdout, &stderr)
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	want := `resource "hetznerrobot_vswitch" "private_net" {
+  name    = "private net"
+  vlan    = 4000
+  servers = [1, 2]
+}
+
+import {
+  to = hetznerrobot_vswitch.private_net
+  id = "4321"
+}
+
+# Server 1 web-1 (1.2.3.4)
+resource "hetznerrobot_firewall" "web-1" {
+  server_id     = "1"
+  active        = true
+  whitelist_hos = true
+
+  rule {
+    name     = "ssh"
+    dst_port = "22"
+    protocol = "tcp"
+    action   = "accept"
+  }
+
+  rule {
+    name   = "Deny others"
+    action = "discard"
+  }
+}
+
+import {
+  to = hetznerrobot_firewall.web-1
+  id = "1"
+}
+
+resource "hetznerrobot_failover" "failover_9_9_9_9" {
+  ip               = "9.9.9.9"
+  active_server_ip = "1.2.3.4"
+}
+
+import {
+  to = hetznerrobot_failover.failover_9_9_9_9
+  id = "9.9.9.9"
+}
+
+resource "hetznerrobot_ssh_key" "laptop" {
+  name = "laptop"
+  data = "ssh-ed25519 AAAA"
+}
+
+import {
+  to = hetznerrobot_ssh_key.laptop
+  id = "c3:c9:0a:0f:b8:2e:b4:51:9f:5a:39:24:db:ae:16:95"
+}
+`
+	if stdout.String() != want {
+		t.Errorf("Run() output:\n%s\nwant:\n%s", stdout.String(), want)
+	}
+
+	if !strings.Contains(stderr.String(), "skipping firewall of server 2") {
+		t.Errorf("Run() warnings = %q, want the unreadable firewall of server 2", stderr.String())
+	}
+}
+
+func TestRunJSON(t *testing.T) {
+	robot := mockRobot(t, false)
+
+	var stdout, stderr bytes.Buffer
+
+	err := export.Run(
+		context.Background(),
+		[]string{"--url", robot.URL, "--format", "json"},
+		&stdout,
+		&stderr,
+	)
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	var inventory export.Inventory
+
+	err = json.Unmarshal(stdout.Bytes(), &inventory)
+	if err != nil {
+		t.Fatalf("decoding output: %v", err)
+	}
+
+	if len(inventory.Servers) != 2 {
+		t.Errorf("servers = %d, want the 2 not cancelled", len(inventory.Servers))
+	}
+
+	if len(inventory.VSwitches) != 1 || len(inventory.VSwitches[0].Servers) != 2 {
+		t.Errorf("vSwitches = %+v, want private net with its 2 servers", inventory.VSwitches)
+	}
+
+	if len(inventory.Firewalls) != 1 || inventory.Firewalls[0].ServerNumber != 1 {
+		t.Errorf("firewalls = %+v, want the firewall of server 1", inventory.Firewalls)
+	}
+
+	if len(inventory.Failovers) != 1 || len(inventory.SSHKeys) != 1 {
+		t.Errorf("failovers = %+v, SSH keys = %+v, want one each", inventory.Failovers, inventory.SSHKeys)
+	}
+}
+
+func TestRunFirewallNetworkError(t *testing.T) {
+	robot := mockRobot(t, true)
+
+	var stdout, stderr bytes.Buffer
+
+	// Only the firewalls Robot refused to return are skipped.
+	err := export.Run(context.Background(), []string{"--url", robot.URL}, &stdout, &stderr)
+	if err == nil || !strings.Contains(err.Error(), "5.6.7.8") {
+		t.Errorf("Run() error = %v, want the failed request of 5.6.7.8", err)
+	}
+
+	if strings.Contains(stderr.String(), "skipping firewall") {
+		t.Errorf("Run() warnings = %q, want none", stderr.String())
+	}
+}
+
+func TestRunUnknownFormat(t *testing.T) {
+	t.Parallel()
+
+	var stdout, stderr bytes.Buffer
+
+	err := export.Run(context.Background(), []string{"--format", "yaml"}, &stdout, &stderr)
+	if !errors.Is(err, export.ErrUnknownFormat) {
+		t.Errorf("Run() error = %v, want ErrUnknownFormat", err)
+	}
+}
@@ -0,0 +1,236 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const ipVersion4 = "ipv4"
+
+// block is an HCL block, attributes are rendered expressions.
+type block struct {
+	header string
+	attrs  [][2]string
+	blocks []block
+}
+
+// attr appends the attribute name with the rendered value.
+func (b *block) attr(name, value string) {
+	b.attrs = append(b.attrs, [2]string{name, value})
+}
+
+// write writes the block at indent, aligning the attributes like terraform fmt.
+func (b block) write(out *strings.Builder, indent string) {
+	out.WriteString(indent + b.header + " {\n")
+
+	width := 0
+	for _, attr := range b.attrs {
+		width = max(width, len(attr[0]))
+	}
+
+	for _, attr := range b.attrs {
+		fmt.Fprintf(out, "%s  %-*s = %s\n", indent, width, attr[0], attr[1])
+	}
+
+	for i, nested := range b.blocks {
+		if i > 0 || len(b.attrs) > 0 {
+			out.WriteString("\n")
+		}
+
+		nested.write(out, indent+"  ")
+	}
+
+	out.WriteString(indent + "}\n")
+}
+
+// labeler returns unique resource names per resource type, remembering the
+// addresses already taken.
+type labeler map[string]bool
+
+// label returns a valid resource name of resourceType derived from name,
+// prefixed with fallback when name does not start with a letter.
+func (l labeler) label(resourceType, name, fallback string) string {
+	var builder strings.Builder
+
+	for _, char := range strings.ToLower(name) {
+		if char <= unicode.MaxASCII && (unicode.IsLetter(char) || unicode.IsDigit(char) || char == '-') {
+			builder.WriteRune(char)
+		} else {
+			builder.WriteRune('_')
+		}
+	}
+
+	label := strings.Trim(builder.String(), "_-")
+	if label == "" || !unicode.IsLetter(rune(label[0])) {
+		label = strings.TrimSuffix(fallback+"_"+label, "_")
+	}
+
+	unique := label
+	for count := 2; l[resourceType+"."+unique]; count++ {
+		unique = label + "_" + strconv.Itoa(count)
+	}
+
+	l[resourceType+"."+unique] = true
+
+	return unique
+}
+
+// WriteHCL writes the inventory as resources, each followed by the import
+// block bringing it under management on the next apply. Firewalls without
+// rules are left out, the resource requires at least one.
+func WriteHCL(w io.Writer, inventory Inventory) error {
+	var (
+		out    strings.Builder
+		labels = labeler{}
+	)
+
+	for _, vsw := range inventory.VSwitches {
+		label := labels.label("hetznerrobot_vswitch", vsw.Name, "vswitch")
+
+		servers := make([]string, len(vsw.Servers))
+		for i, server := range vsw.Servers {
+			servers[i] = strconv.Itoa(server.ServerNumber)
+		}
+
+		//exhaustruct:ignore
+		resource := block{header: `resource "hetznerrobot_vswitch" ` + quote(label)}
+		resource.attr("name", quote(vsw.Name))
+		resource.attr("vlan", strconv.Itoa(vsw.VLAN))
+		resource.attr("servers", "["+strings.Join(servers, ", ")+"]")
+
+		writeResource(&out, "", resource, "hetznerrobot_vswitch."+label, strconv.Itoa(vsw.ID))
+	}
+
+	for _, firewall := range inventory.Firewalls {
+		if len(firewall.Rules.Input) == 0 {
+			continue
+		}
+
+		serverID := strconv.Itoa(firewall.ServerNumber)
+		label := labels.label("hetznerrobot_firewall", firewall.ServerName, "server_"+serverID)
+
+		writeResource(
+			&out,
+			fmt.Sprintf("# Server %s %s (%s)\n", serverID, firewall.ServerName, firewall.IP),
+			firewallBlock(label, serverID, firewall),
+			"hetznerrobot_firewall."+label,
+			serverID,
+		)
+	}
+
+	for _, failover := range inventory.Failovers {
+		label := labels.label("hetznerrobot_failover", failover.IP, "failover")
+
+		//exhaustruct:ignore
+		resource := block{header: `resource "hetznerrobot_failover" ` + quote(label)}
+		resource.attr("ip", quote(failover.IP))
+		resource.attr("active_server_ip", quote(failover.ActiveServerIP))
+
+		writeResource(&out, "", resource, "hetznerrobot_failover."+label, failover.IP)
+	}
+
+	for _, key := range inventory.SSHKeys {
+		label := labels.label("hetznerrobot_ssh_key", key.Name, "ssh_key")
+
+		//exhaustruct:ignore
+		resource := block{header: `resource "hetznerrobot_ssh_key" ` + quote(label)}
+		resource.attr("name", quote(key.Name))
+		resource.attr("data", quote(key.Data))
+
+		writeResource(&out, "", resource, "hetznerrobot_ssh_key."+label, key.Fingerprint)
+	}
+
+	_, err := io.WriteString(w, strings.TrimSuffix(out.String(), "\n"))
+	if err != nil {
+		return fmt.Errorf("error writing configuration: %w", err)
+	}
+
+	return nil
+}
+
+// firewallBlock returns the resource of firewall, leaving out the rule
+// attributes that are empty or default.
+func firewallBlock(label, serverID string, firewall ServerFirewall) block {
+	//exhaustruct:ignore
+	resource := block{header: `resource "hetznerrobot_firewall" ` + quote(label)}
+	resource.attr("server_id", quote(serverID))
+	resource.attr("active", strconv.FormatBool(firewall.Status == "active"))
+	resource.attr("whitelist_hos", strconv.FormatBool(firewall.WhitelistHetznerServices))
+
+	for _, rule := range firewall.Rules.Input {
+		//exhaustruct:ignore
+		nested := block{header: "rule"}
+
+		if rule.IPVersion != "" && rule.IPVersion != ipVersion4 {
+			nested.attr("ip_version", quote(rule.IPVersion))
+		}
+
+		for _, attr := range [][2]string{
+			{"name", rule.Name},
+			{"src_ip", rule.SrcIP},
+			{"src_port", rule.SrcPort},
+			{"dst_ip", rule.DstIP},
+			{"dst_port", rule.DstPort},
+			{"protocol", rule.Protocol},
+			{"tcp_flags", rule.TCPFlags},
+			{"action", rule.Action},
+		} {
+			if attr[1] != "" {
+				nested.attr(attr[0], quote(attr[1]))
+			}
+		}
+
+		resource.blocks = append(resource.blocks, nested)
+	}
+
+	return resource
+}
+
+// writeResource writes the comment, the resource and its import block.
+func writeResource(out *strings.Builder, comment string, resource block, address, id string) {
+	out.WriteString(comment)
+	resource.write(out, "")
+	out.WriteString("\n")
+
+	//exhaustruct:ignore
+	imp := block{header: "import"}
+	imp.attr("to", address)
+	imp.attr("id", quote(id))
+	imp.write(out, "")
+	out.WriteString("\n")
+}
+
+// quote returns s as an HCL string, escaping the template sequences.
+func quote(s string) string {
+	var builder strings.Builder
+
+	builder.WriteByte('"')
+
+	for i, char := range s {
+		switch {
+		case char == '"' || char == '\\':
+			builder.WriteRune('\\')
+			builder.WriteRune(char)
+		case char == '\n':
+			builder.WriteString(`\n`)
+		case char == '\t':
+			builder.WriteString(`\t`)
+		case char == '\r':
+			builder.WriteString(`\r`)
+		case unicode.IsControl(char):
+			fmt.Fprintf(&builder, `\u%04x`, char)
+		case (char == '$' || char == '%') && strings.HasPrefix(s[i+1:], "{"):
+			builder.WriteRune(char)
+			builder.WriteRune(char)
+		default:
+			builder.WriteRune(char)
+		}
+	}
+
+	builder.WriteByte('"')
+
+	return builder.String()
+}
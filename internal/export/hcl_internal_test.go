@@ -0,0 +1,52 @@
+package export
+
+import "testing"
+
+func TestQuote(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"web-1":         `"web-1"`,
+		`say "hi"\`:     `"say \"hi\"\\"`,
+		"line\nbreak":   `"line\nbreak"`,
+		"${var} %{if}":  `"$${var} %%{if}"`,
+		"$5 100%":       `"$5 100%"`,
+		"bell\a":        `"bell\u0007"`,
+		"Büro Nürnberg": `"Büro Nürnberg"`,
+	}
+
+	for input, want := range tests {
+		if got := quote(input); got != want {
+			t.Errorf("quote(%q) = %s, want %s", input, got, want)
+		}
+	}
+}
+
+func TestLabel(t *testing.T) {
+	t.Parallel()
+
+	labels := labeler{}
+
+	tests := []struct {
+		name     string
+		fallback string
+		want     string
+	}{
+		{name: "web-1", fallback: "server_1", want: "web-1"},
+		{name: "Web 1", fallback: "server_2", want: "web_1"},
+		{name: "web-1", fallback: "server_3", want: "web-1_2"},
+		{name: "", fallback: "server_4", want: "server_4"},
+		{name: "1.2.3.4", fallback: "failover", want: "failover_1_2_3_4"},
+		{name: "Büro", fallback: "server_5", want: "b_ro"},
+	}
+
+	for _, tt := range tests {
+		if got := labels.label("hetznerrobot_firewall", tt.name, tt.fallback); got != tt.want {
+			t.Errorf("label(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	if got := labels.label("hetznerrobot_vswitch", "web-1", "vswitch"); got != "web-1" {
+		t.Errorf("label() of another type = %q, want web-1", got)
+	}
+}
@@ -0,0 +1,171 @@
+package failover
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/action"
+	"github.com/hashicorp/terraform-plugin-framework/action/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/client"
+)
+
+const (
+	// ActionSwitchType is the type name of the Hetzner Robot failover switch action.
+	ActionSwitchType = "hetznerrobot_failover_switch"
+
+	switchTimeout  = 2 * time.Minute
+	switchInterval = 5 * time.Second
+)
+
+// switchAction defines the failover_switch action.
+type switchAction struct {
+	client *client.HetznerRobotClient
+}
+
+// switchModel maps the failover_switch action schema.
+type switchModel struct {
+	IP             types.String `tfsdk:"ip"`
+	ActiveServerIP types.String `tfsdk:"active_server_ip"`
+}
+
+// NewSwitchAction returns the failover_switch action.
+//
+//nolint:ireturn
+func NewSwitchAction() action.Action {
+	return &switchAction{client: nil}
+}
+
+func (a *switchAction) Metadata(
+	_ context.Context,
+	_ action.MetadataRequest,
+	resp *action.MetadataResponse,
+) {
+	resp.TypeName = ActionSwitchType
+}
+
+func (a *switchAction) Schema(
+	_ context.Context,
+	_ action.SchemaRequest,
+	resp *action.SchemaResponse,
+) {
+	resp.Schema = schema.Schema{
+		Description: "Routes a failover IP to another server and waits for Robot to report the new " +
+			"route. Unlike `hetznerrobot_failover`, the routing is not tracked in state, e.g. to " +
+			"switch over from a lifecycle trigger. Requires Terraform 1.14 or later.",
+		Attributes: map[string]schema.Attribute{
+			"ip": schema.StringAttribute{
+				Required:    true,
+				Description: "The failover IP to route.",
+			},
+			"active_server_ip": schema.StringAttribute{
+				Required:    true,
+				Description: "The main IP of the server the failover IP should route to.",
+			},
+		},
+	}
+}
+
+func (a *switchAction) Configure(
+	_ context.Context,
+	req action.ConfigureRequest,
+	resp *action.ConfigureResponse,
+) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	hClient, ok := req.ProviderData.(*client.HetznerRobotClient)
+	if !ok {
+		resp.Diagnostics.AddError("invalid client type", fmt.Sprintf("got %T", req.ProviderData))
+
+		return
+	}
+
+	a.client = hClient
+}
+
+func (a *switchAction) Invoke(
+	ctx context.Context,
+	req action.InvokeRequest,
+	resp *action.InvokeResponse,
+) {
+	var model switchModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &model)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ip := model.IP.ValueString()
+	target := model.ActiveServerIP.ValueString()
+
+	rec, err := a.client.FetchFailover(ctx, ip)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to read failover "+ip, err.Error())
+
+		return
+	}
+
+	if rec.ActiveServerIP == target {
+		resp.SendProgress(action.InvokeProgressEvent{
+			Message: fmt.Sprintf("Failover %s already routes to %s", ip, target),
+		})
+
+		return
+	}
+
+	resp.SendProgress(action.InvokeProgressEvent{
+		Message: fmt.Sprintf("Routing failover %s from %s to %s", ip, rec.ActiveServerIP, target),
+	})
+
+	err = a.client.SetFailover(ctx, ip, target)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to route failover "+ip, err.Error())
+
+		return
+	}
+
+	err = WaitForRoute(ctx, a.client, ip, target, func(active string) {
+		resp.SendProgress(action.InvokeProgressEvent{
+			Message: fmt.Sprintf("Failover %s still routes to %s", ip, active),
+		})
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("failed to route failover "+ip, err.Error())
+	}
+}
+
+// WaitForRoute polls a failover IP until it routes to target, calling
+// progress with the active server IP after each mismatch.
+func WaitForRoute(
+	ctx context.Context,
+	hClient *client.HetznerRobotClient,
+	ip string,
+	target string,
+	progress func(active string),
+) error {
+	ctx, cancel := context.WithTimeout(ctx, switchTimeout)
+	defer cancel()
+
+	for {
+		rec, err := hClient.FetchFailover(ctx, ip)
+		if err != nil {
+			return fmt.Errorf("error waiting for failover %s: %w", ip, err)
+		}
+
+		if rec.ActiveServerIP == target {
+			return nil
+		}
+
+		progress(rec.ActiveServerIP)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("failover %s still routes to %s after %s", ip, rec.ActiveServerIP, switchTimeout)
+		case <-time.After(switchInterval):
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package failover
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/list"
+	"github.com/hashicorp/terraform-plugin-framework/list/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/client"
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/query"
+)
+
+// failoverList lists the failover IPs of the account. The resource is served
+// by the SDKv2 provider, so its schemas are converted for the framework.
+type failoverList struct {
+	client *client.HetznerRobotClient
+}
+
+// listModel maps the failover list configuration.
+type listModel struct {
+	Name       types.String `tfsdk:"name"`
+	Datacenter types.String `tfsdk:"datacenter"`
+}
+
+// NewList returns the failover list resource.
+//
+//nolint:ireturn
+func NewList() list.ListResource {
+	return &failoverList{client: nil}
+}
+
+func (l *failoverList) Metadata(
+	_ context.Context,
+	_ resource.MetadataRequest,
+	resp *resource.MetadataResponse,
+) {
+	resp.TypeName = ResourceType
+}
+
+func (l *failoverList) RawV6Schemas(
+	ctx context.Context,
+	_ list.RawV6SchemaRequest,
+	resp *list.RawV6SchemaResponse,
+) {
+	query.SDKSchemas(ctx, Resource(), resp)
+}
+
+func (l *failoverList) ListResourceConfigSchema(
+	_ context.Context,
+	_ list.ListResourceSchemaRequest,
+	resp *list.ListResourceSchemaResponse,
+) {
+	resp.Schema = schema.Schema{
+		Description: "Lists the failover IPs of the account. The filters apply to their primary server.",
+		Attributes: map[string]schema.Attribute{
+			"name":       query.NameAttribute(),
+			"datacenter": query.DatacenterAttribute(),
+		},
+	}
+}
+
+func (l *failoverList) Configure(
+	_ context.Context,
+	req resource.ConfigureRequest,
+	resp *resource.ConfigureResponse,
+) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	hClient, ok := req.ProviderData.(*client.HetznerRobotClient)
+	if !ok {
+		resp.Diagnostics.AddError("invalid client type", fmt.Sprintf("got %T", req.ProviderData))
+
+		return
+	}
+
+	l.client = hClient
+}
+
+func (l *failoverList) List(
+	ctx context.Context,
+	req list.ListRequest,
+	stream *list.ListResultsStream,
+) {
+	var config listModel
+
+	diags := req.Config.Get(ctx, &config)
+	if diags.HasError() {
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+
+		return
+	}
+
+	failovers, err := l.client.FetchAllFailovers(ctx)
+	if err != nil {
+		diags.AddError("error fetching failover IPs", err.Error())
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+
+		return
+	}
+
+	// The primary servers are only needed to filter.
+	servers := map[int]client.Server{}
+
+	if !config.Name.IsNull() || !config.Datacenter.IsNull() {
+		all, err := l.client.FetchAllServers(ctx)
+		if err != nil {
+			diags.AddError("error fetching servers", err.Error())
+			stream.Results = list.ListResultsStreamDiagnostics(diags)
+
+			return
+		}
+
+		for _, server := range all {
+			servers[server.Number] = server
+		}
+	}
+
+	stream.Results = func(push func(list.ListResult) bool) {
+		for _, failover := range failovers {
+			server := servers[failover.ServerNumber]
+
+			if !query.MatchName(config.Name.ValueString(), server.ServerName) ||
+				!query.MatchDatacenter(config.Datacenter.ValueString(), server.Datacenter) {
+				continue
+			}
+
+			if !push(listResult(ctx, req, failover)) {
+				return
+			}
+		}
+	}
+}
+
+// listResult returns the list result of a failover IP, mirroring resourceRead.
+func listResult(ctx context.Context, req list.ListRequest, failover client.Failover) list.ListResult {
+	result := req.NewListResult(ctx)
+	result.DisplayName = failover.IP
+
+	result.Diagnostics.Append(
+		result.Identity.SetAttribute(ctx, path.Root("ip"), failover.IP)...,
+	)
+
+	if !req.IncludeResource {
+		return result
+	}
+
+	for key, value := range map[string]any{
+		"id":               failover.IP,
+		"ip":               failover.IP,
+		"active_server_ip": failover.ActiveServerIP,
+		"server_ip":        failover.ServerIP,
+		"server_number":    int64(failover.ServerNumber),
+		"netmask":          failover.Netmask,
+	} {
+		result.Diagnostics.Append(result.Resource.SetAttribute(ctx, path.Root(key), value)...)
+	}
+
+	return result
+}
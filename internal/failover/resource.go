@@ -26,7 +26,18 @@ func Resource() *schema.Resource {
 		UpdateContext: resourceUpdate,
 		DeleteContext: resourceDelete,
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceImportState,
+		},
+		Identity: &schema.ResourceIdentity{
+			SchemaFunc: func() map[string]*schema.Schema {
+				return map[string]*schema.Schema{
+					"ip": {
+						Type:              schema.TypeString,
+						RequiredForImport: true,
+						Description:       "The failover IP.",
+					},
+				}
+			},
 		},
 		Schema: map[string]*schema.Schema{
 			"ip": {
@@ -108,6 +119,11 @@ func resourceRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Di
 		}
 	}
 
+	err = setIdentity(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
 	return nil
 }
 
@@ -140,3 +156,40 @@ func resourceDelete(ctx context.Context, d *schema.ResourceData, meta any) diag.
 
 	return nil
 }
+
+// resourceImportState imports a failover by IP, given as import ID or identity.
+func resourceImportState(
+	_ context.Context,
+	d *schema.ResourceData,
+	_ any,
+) ([]*schema.ResourceData, error) {
+	if d.Id() == "" {
+		identity, err := d.Identity()
+		if err != nil {
+			return nil, fmt.Errorf("error getting identity: %w", err)
+		}
+
+		d.SetId(identity.Get("ip").(string))
+	}
+
+	err := setIdentity(d)
+	if err != nil {
+		return nil, err
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func setIdentity(d *schema.ResourceData) error {
+	identity, err := d.Identity()
+	if err != nil {
+		return fmt.Errorf("error getting identity: %w", err)
+	}
+
+	err = identity.Set("ip", d.Id())
+	if err != nil {
+		return fmt.Errorf("error setting ip identity: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,278 @@
+package firewall
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/client"
+)
+
+// maxRuleNameLength is the longest rule name Robot accepts.
+const maxRuleNameLength = 30
+
+// compactedRule is a rule sent to Robot together with the indexes of the
+// configured rules it was built from.
+type compactedRule struct {
+	rule    client.FirewallRule
+	sources []int
+}
+
+// portRange is an inclusive range of ports.
+type portRange struct {
+	from, to int
+}
+
+// compactRules merges the rules sharing action, ip version, protocol, tcp
+// flags, source and destination address into as few destination port ranges
+// as possible. Only rules within the same run of consecutive rules sharing
+// an action are merged, as reordering those cannot change what the firewall
+// lets through. Robot accepts a single port or range per rule, so ports that
+// are not contiguous stay in separate rules.
+func compactRules(rules []client.FirewallRule) []compactedRule {
+	result := make([]compactedRule, 0, len(rules))
+
+	start := 0
+	for index := 1; index <= len(rules); index++ {
+		if index < len(rules) && rules[index].Action == rules[start].Action {
+			continue
+		}
+
+		result = append(result, compactRun(rules, start, index)...)
+		start = index
+	}
+
+	return result
+}
+
+// compactRun compacts rules[start:end], which share the same action.
+func compactRun(rules []client.FirewallRule, start, end int) []compactedRule {
+	type group struct {
+		key     client.FirewallRule
+		indexes []int
+	}
+
+	var groups []*group
+
+	byKey := make(map[client.FirewallRule]*group)
+
+	for index := start; index < end; index++ {
+		key := rules[index]
+		key.Name = ""
+		key.DstPort = ""
+
+		current, ok := byKey[key]
+		if !ok {
+			current = &group{key: key, indexes: nil}
+			byKey[key] = current
+			groups = append(groups, current)
+		}
+
+		current.indexes = append(current.indexes, index)
+	}
+
+	var result []compactedRule
+
+	for _, current := range groups {
+		result = append(result, compactGroup(rules, current.key, current.indexes)...)
+	}
+
+	// Keep the order of the configured rules.
+	slices.SortStableFunc(result, func(a, b compactedRule) int {
+		return cmp.Compare(a.sources[0], b.sources[0])
+	})
+
+	return result
+}
+
+// compactGroup merges the destination ports of rules that only differ by
+// name and destination port.
+func compactGroup(
+	rules []client.FirewallRule,
+	key client.FirewallRule,
+	indexes []int,
+) []compactedRule {
+	type source struct {
+		ports portRange
+		index int
+	}
+
+	sources := make([]source, 0, len(indexes))
+
+	for _, index := range indexes {
+		ports, ok := parsePortRange(rules[index].DstPort)
+		if !ok {
+			// A rule without destination port matches every port.
+			ports = portRange{from: 0, to: maxPort}
+		}
+
+		sources = append(sources, source{ports: ports, index: index})
+	}
+
+	slices.SortStableFunc(sources, func(a, b source) int {
+		return cmp.Or(cmp.Compare(a.ports.from, b.ports.from), cmp.Compare(a.ports.to, b.ports.to))
+	})
+
+	var result []compactedRule
+
+	var current portRange
+
+	var members []int
+
+	flush := func() {
+		rule := key
+		rule.DstPort = formatPortRange(current)
+
+		slices.Sort(members)
+
+		var names []string
+
+		for _, index := range members {
+			if rules[index].Name != "" && !slices.Contains(names, rules[index].Name) {
+				names = append(names, rules[index].Name)
+			}
+		}
+
+		rule.Name = mergeRuleNames(names)
+
+		result = append(result, compactedRule{rule: rule, sources: members})
+	}
+
+	for i, src := range sources {
+		if i > 0 && src.ports.from <= current.to+1 {
+			current.to = max(current.to, src.ports.to)
+			members = append(members, src.index)
+
+			continue
+		}
+
+		if i > 0 {
+			flush()
+		}
+
+		current = src.ports
+		members = []int{src.index}
+	}
+
+	if len(sources) > 0 {
+		flush()
+	}
+
+	return result
+}
+
+// mergeRuleNames joins the names of merged rules, falling back to the first
+// name and the count of the others, e.g. "ssh+3", when the result is longer
+// than Robot accepts.
+func mergeRuleNames(names []string) string {
+	merged := strings.Join(names, "+")
+	if len(names) < 2 || len(merged) <= maxRuleNameLength {
+		return merged
+	}
+
+	suffix := "+" + strconv.Itoa(len(names)-1)
+	first := []rune(names[0])
+
+	for len(string(first))+len(suffix) > maxRuleNameLength {
+		first = first[:len(first)-1]
+	}
+
+	return string(first) + suffix
+}
+
+func parsePortRange(value string) (portRange, bool) {
+	if value == "" {
+		return portRange{from: 0, to: 0}, false
+	}
+
+	first, last, isRange := strings.Cut(value, "-")
+	if !isRange {
+		last = first
+	}
+
+	from, err := strconv.Atoi(first)
+	if err != nil {
+		return portRange{from: 0, to: 0}, false
+	}
+
+	to, err := strconv.Atoi(last)
+	if err != nil {
+		return portRange{from: 0, to: 0}, false
+	}
+
+	return portRange{from: from, to: to}, true
+}
+
+func formatPortRange(ports portRange) string {
+	switch {
+	case ports.from == 0 && ports.to == maxPort:
+		return ""
+	case ports.from == ports.to:
+		return strconv.Itoa(ports.from)
+	default:
+		return fmt.Sprintf("%d-%d", ports.from, ports.to)
+	}
+}
+
+// budgetRules returns the rules to send to Robot, compacted if requested, and
+// fails naming the configured rules that do not fit in the Robot limit.
+func budgetRules(rules []client.FirewallRule, compact bool) ([]client.FirewallRule, error) {
+	compacted := make([]compactedRule, 0, len(rules))
+
+	if compact {
+		compacted = compactRules(rules)
+	} else {
+		for index, rule := range rules {
+			compacted = append(compacted, compactedRule{rule: rule, sources: []int{index}})
+		}
+	}
+
+	if len(compacted) > maxRulesPerFirewall {
+		var overflow []string
+
+		for _, rule := range compacted[maxRulesPerFirewall:] {
+			for _, index := range rule.sources {
+				label := fmt.Sprintf("rule[%d]", index)
+				if rules[index].Name != "" {
+					label += fmt.Sprintf(" (%s)", rules[index].Name)
+				}
+
+				overflow = append(overflow, label)
+			}
+		}
+
+		message := fmt.Sprintf(
+			"%d rules are configured but Robot allows at most %d per firewall",
+			len(rules),
+			maxRulesPerFirewall,
+		)
+		if compact {
+			message = fmt.Sprintf(
+				"%d rules are left after compaction but Robot allows at most %d per firewall",
+				len(compacted),
+				maxRulesPerFirewall,
+			)
+		}
+
+		hint := ""
+		if !compact {
+			hint = ". Set compact = true to merge rules differing only by destination port"
+		}
+
+		return nil, fmt.Errorf(
+			"%s, the following rules do not fit: %s%s",
+			message,
+			strings.Join(overflow, ", "),
+			hint,
+		)
+	}
+
+	result := make([]client.FirewallRule, 0, len(compacted))
+	for _, rule := range compacted {
+		result = append(result, rule.rule)
+	}
+
+	return result, nil
+}
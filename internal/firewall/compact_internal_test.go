@@ -0,0 +1,156 @@
+package firewall
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/client"
+)
+
+func TestCompactRules(t *testing.T) {
+	t.Parallel()
+
+	rule := func(name, port, action string) client.FirewallRule {
+		//exhaustruct:ignore
+		return client.FirewallRule{Name: name, DstPort: port, Protocol: "tcp", Action: action}
+	}
+
+	tests := []struct {
+		name  string
+		rules []client.FirewallRule
+		want  []client.FirewallRule
+	}{
+		{
+			name: "Adjacent ports",
+			rules: []client.FirewallRule{
+				rule("http", "80", "accept"),
+				rule("alt", "81-90", "accept"),
+				rule("deny", "", "discard"),
+			},
+			want: []client.FirewallRule{
+				rule("http+alt", "80-90", "accept"),
+				rule("deny", "", "discard"),
+			},
+		},
+		{
+			name: "Overlapping ports out of order",
+			rules: []client.FirewallRule{
+				rule("b", "1000-2000", "accept"),
+				rule("a", "500-1500", "accept"),
+			},
+			want: []client.FirewallRule{rule("b+a", "500-2000", "accept")},
+		},
+		{
+			name: "Gap between ports",
+			rules: []client.FirewallRule{
+				rule("ssh", "22", "accept"),
+				rule("https", "443", "accept"),
+			},
+			want: []client.FirewallRule{
+				rule("ssh", "22", "accept"),
+				rule("https", "443", "accept"),
+			},
+		},
+		{
+			name: "Different actions are not merged",
+			rules: []client.FirewallRule{
+				rule("a", "80", "accept"),
+				rule("b", "81", "discard"),
+				rule("c", "82", "accept"),
+			},
+			want: []client.FirewallRule{
+				rule("a", "80", "accept"),
+				rule("b", "81", "discard"),
+				rule("c", "82", "accept"),
+			},
+		},
+		{
+			name: "Any port absorbs the others",
+			rules: []client.FirewallRule{
+				rule("ssh", "22", "accept"),
+				rule("all", "", "accept"),
+			},
+			want: []client.FirewallRule{rule("ssh+all", "", "accept")},
+		},
+		{
+			name: "Long names",
+			rules: []client.FirewallRule{
+				rule("kubernetes-api", "6443", "accept"),
+				rule("kubernetes-etcd", "6444-6445", "accept"),
+				rule("kubernetes-kubelet", "6446", "accept"),
+			},
+			want: []client.FirewallRule{rule("kubernetes-api+2", "6443-6446", "accept")},
+		},
+		{
+			name: "Long first name",
+			rules: []client.FirewallRule{
+				rule(strings.Repeat("a", 40), "80", "accept"),
+				rule("alt", "81", "accept"),
+			},
+			want: []client.FirewallRule{rule(strings.Repeat("a", 28)+"+1", "80-81", "accept")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := budgetRules(tt.rules, true)
+			if err != nil {
+				t.Fatalf("budgetRules() error: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("budgetRules() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBudgetRulesOverflow(t *testing.T) {
+	t.Parallel()
+
+	rules := make([]client.FirewallRule, 0, maxRulesPerFirewall+2)
+	for index := range maxRulesPerFirewall + 2 {
+		//exhaustruct:ignore
+		rules = append(rules, client.FirewallRule{
+			Name:    fmt.Sprintf("r%d", index),
+			DstPort: fmt.Sprint(index * 10),
+			Action:  "accept",
+		})
+	}
+
+	_, err := budgetRules(rules, false)
+	if err == nil {
+		t.Fatal("budgetRules() expected error")
+	}
+
+	for _, want := range []string{"rule[10] (r10)", "rule[11] (r11)", "compact = true"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("budgetRules() error %q does not contain %q", err, want)
+		}
+	}
+
+	_, err = budgetRules(rules, true)
+	if err == nil || strings.Contains(err.Error(), "compact = true") {
+		t.Errorf("budgetRules() with compaction: unexpected error %v", err)
+	}
+
+	//exhaustruct:ignore
+	rules = append(rules[:maxRulesPerFirewall], client.FirewallRule{
+		Name:    "next",
+		DstPort: "91",
+		Action:  "accept",
+	})
+
+	got, err := budgetRules(rules, true)
+	if err != nil {
+		t.Fatalf("budgetRules() error: %v", err)
+	}
+
+	if len(got) != maxRulesPerFirewall {
+		t.Errorf("budgetRules() returned %d rules, want %d", len(got), maxRulesPerFirewall)
+	}
+}
@@ -0,0 +1,283 @@
+package firewall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/client"
+)
+
+// DataSourceType is the type name of the Hetzner Robot Firewall datasource.
+const DataSourceType = "hetznerrobot_firewall"
+
+// serverFilter selects servers by name, datacenter and product.
+type serverFilter struct {
+	nameRegex  *regexp.Regexp
+	datacenter string
+	product    string
+}
+
+// DataSource defines the firewall terraform datasource.
+func DataSource() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceRead,
+		Schema: map[string]*schema.Schema{
+			"allow_partial": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				Description: "Whether to return the firewalls that could be read, with a warning for " +
+					"each one that failed, instead of failing.",
+			},
+			"server_ids": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				ConflictsWith: []string{"filter"},
+				Description:   "IDs of the servers to read the firewall of.",
+			},
+			"filter": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				MaxItems:      1,
+				ConflictsWith: []string{"server_ids"},
+				Description: "Select the servers to read the firewall of. " +
+					"Without server_ids nor filter, every server of the account is read.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name_regex": {
+							Type:             schema.TypeString,
+							Optional:         true,
+							ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsValidRegExp),
+							Description:      "Regular expression matching the server name.",
+						},
+						"datacenter": {
+							Type:     schema.TypeString,
+							Optional: true,
+							Description: "Datacenter of the server, matched as a prefix " +
+								"(e.g., FSN1 matches FSN1-DC14).",
+						},
+						"product": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Product of the server (e.g., AX41-NVMe).",
+						},
+					},
+				},
+			},
+			"firewalls": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"server_id":     {Type: schema.TypeString, Computed: true},
+						"server_ip":     {Type: schema.TypeString, Computed: true},
+						"server_name":   {Type: schema.TypeString, Computed: true},
+						"status":        {Type: schema.TypeString, Computed: true},
+						"active":        {Type: schema.TypeBool, Computed: true},
+						"whitelist_hos": {Type: schema.TypeBool, Computed: true},
+						"filter_ipv6":   {Type: schema.TypeBool, Computed: true},
+						"port":          {Type: schema.TypeString, Computed: true},
+						"rule": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     computedRuleResource(),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	hClient, ok := meta.(*client.HetznerRobotClient)
+	if !ok {
+		return diag.Errorf("invalid client type")
+	}
+
+	rawIDs := d.Get("server_ids").([]any)
+	ids := make([]string, 0, len(rawIDs))
+
+	for _, v := range rawIDs {
+		ids = append(ids, v.(string))
+	}
+
+	var (
+		diags   diag.Diagnostics
+		servers []client.Server
+		err     error
+	)
+
+	err = hClient.CheckQuota(client.QuotaFamilyServer, len(ids))
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "Robot request quota",
+			Detail:   err.Error(),
+		})
+	}
+
+	if len(ids) == 0 {
+		servers, err = hClient.FetchAllServers(ctx)
+	} else {
+		servers, err = hClient.FetchServersByIDs(ctx, ids)
+	}
+
+	if err != nil {
+		var taskErrs client.TaskErrors
+		if !d.Get("allow_partial").(bool) || !errors.As(err, &taskErrs) {
+			return diag.FromErr(fmt.Errorf("failed to fetch servers: %w", err))
+		}
+
+		for _, id := range taskErrs.IDs() {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  "Skipped server " + id,
+				Detail:   taskErrs[id].Error(),
+			})
+		}
+	}
+
+	filter := expandServerFilter(d.Get("filter").([]any))
+
+	selected := make(map[string]client.Server, len(servers))
+	ips := make([]string, 0, len(servers))
+
+	for _, server := range servers {
+		if filter.match(server) {
+			selected[server.IP] = server
+			ips = append(ips, server.IP)
+		}
+	}
+
+	err = hClient.CheckQuota(client.QuotaFamilyFirewall, len(ips))
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "Robot request quota",
+			Detail:   err.Error(),
+		})
+	}
+
+	firewalls, err := hClient.FetchFirewallsByIPs(ctx, ips)
+	if err != nil {
+		var taskErrs client.TaskErrors
+		if !d.Get("allow_partial").(bool) || !errors.As(err, &taskErrs) {
+			return diag.FromErr(fmt.Errorf("failed to fetch firewalls: %w", err))
+		}
+
+		for _, id := range taskErrs.IDs() {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  "Skipped firewall of " + id,
+				Detail:   taskErrs[id].Error(),
+			})
+		}
+	}
+
+	firewallList := make([]map[string]any, 0, len(firewalls))
+	numbers := make([]string, 0, len(firewalls))
+
+	for _, firewall := range firewalls {
+		server := selected[firewall.IP]
+		number := strconv.Itoa(server.Number)
+
+		firewallList = append(firewallList, map[string]any{
+			"server_id":     number,
+			"server_ip":     server.IP,
+			"server_name":   server.ServerName,
+			"status":        firewall.Status,
+			"active":        firewall.Status == statusTrue,
+			"whitelist_hos": firewall.WhitelistHetznerServices,
+			"filter_ipv6":   firewall.FilterIPv6,
+			"port":          firewall.Port,
+			"rule":          flattenFirewallRules(firewall.Rules.Input),
+		})
+		numbers = append(numbers, number)
+	}
+
+	err = d.Set("firewalls", firewallList)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("error setting firewalls attribute: %w", err))
+	}
+
+	d.SetId("firewalls-" + strings.Join(numbers, "-"))
+
+	return diags
+}
+
+func expandServerFilter(filterList []any) serverFilter {
+	//exhaustruct:ignore
+	filter := serverFilter{}
+
+	if len(filterList) == 0 || filterList[0] == nil {
+		return filter
+	}
+
+	props := filterList[0].(map[string]any)
+
+	if nameRegex := props["name_regex"].(string); nameRegex != "" {
+		// Already validated at plan time.
+		filter.nameRegex = regexp.MustCompile(nameRegex)
+	}
+
+	filter.datacenter = props["datacenter"].(string)
+	filter.product = props["product"].(string)
+
+	return filter
+}
+
+func (f serverFilter) match(server client.Server) bool {
+	if f.nameRegex != nil && !f.nameRegex.MatchString(server.ServerName) {
+		return false
+	}
+
+	if f.datacenter != "" && !strings.HasPrefix(server.Datacenter, f.datacenter) {
+		return false
+	}
+
+	if f.product != "" && server.Product != f.product {
+		return false
+	}
+
+	return true
+}
+
+// computedRuleResource returns the read-only schema of a firewall rule.
+func computedRuleResource() *schema.Resource {
+	ruleSchema := make(map[string]*schema.Schema, len(ruleFields))
+	for _, field := range ruleFields {
+		ruleSchema[field] = &schema.Schema{Type: schema.TypeString, Computed: true}
+	}
+
+	return &schema.Resource{Schema: ruleSchema}
+}
+
+func flattenFirewallRules(rules []client.FirewallRule) []map[string]any {
+	result := make([]map[string]any, 0, len(rules))
+	for _, rule := range rules {
+		rule = normalizeRule(rule)
+
+		result = append(result, map[string]any{
+			"ip_version": rule.IPVersion,
+			"name":       rule.Name,
+			"src_ip":     rule.SrcIP,
+			"src_port":   rule.SrcPort,
+			"dst_ip":     rule.DstIP,
+			"dst_port":   rule.DstPort,
+			"protocol":   rule.Protocol,
+			"tcp_flags":  rule.TCPFlags,
+			"action":     rule.Action,
+		})
+	}
+
+	return result
+}
@@ -0,0 +1,57 @@
+package firewall
+
+import (
+	"testing"
+
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/client"
+)
+
+func TestServerFilterMatch(t *testing.T) {
+	t.Parallel()
+
+	//exhaustruct:ignore
+	server := client.Server{ServerName: "prod-db-1", Datacenter: "FSN1-DC14", Product: "AX41-NVMe"}
+
+	tests := []struct {
+		name   string
+		filter map[string]any
+		want   bool
+	}{
+		{name: "No filter", filter: nil, want: true},
+		{
+			name:   "All criteria",
+			filter: map[string]any{"name_regex": "^prod-", "datacenter": "FSN1", "product": "AX41-NVMe"},
+			want:   true,
+		},
+		{
+			name:   "Name mismatch",
+			filter: map[string]any{"name_regex": "^staging-", "datacenter": "", "product": ""},
+			want:   false,
+		},
+		{
+			name:   "Datacenter mismatch",
+			filter: map[string]any{"name_regex": "", "datacenter": "NBG1", "product": ""},
+			want:   false,
+		},
+		{
+			name:   "Product mismatch",
+			filter: map[string]any{"name_regex": "", "datacenter": "", "product": "EX44"},
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var filterList []any
+			if tt.filter != nil {
+				filterList = []any{tt.filter}
+			}
+
+			if got := expandServerFilter(filterList).match(server); got != tt.want {
+				t.Errorf("match() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package firewall
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/client"
+)
+
+// RuleFunctionName is the name of the firewall_rule provider function.
+const RuleFunctionName = "firewall_rule"
+
+// ruleFunction defines the firewall_rule provider function.
+type ruleFunction struct{}
+
+// NewRuleFunction returns the firewall_rule provider function.
+//
+//nolint:ireturn
+func NewRuleFunction() function.Function {
+	return &ruleFunction{}
+}
+
+func (f *ruleFunction) Metadata(
+	_ context.Context,
+	_ function.MetadataRequest,
+	resp *function.MetadataResponse,
+) {
+	resp.Name = RuleFunctionName
+}
+
+func (f *ruleFunction) Definition(
+	_ context.Context,
+	_ function.DefinitionRequest,
+	resp *function.DefinitionResponse,
+) {
+	//exhaustruct:ignore
+	resp.Definition = function.Definition{
+		Summary: "Build and validate a firewall rule",
+		Description: "Returns a `" + ResourceType + "` rule object from a map of its attributes " +
+			"(" + strings.Join(ruleFields, ", ") + "), validated as the resource does. " +
+			"Values are normalized as Robot reports them and unset attributes are null, " +
+			"so the result can be used in a `dynamic \"rule\"` block without diff.",
+		Parameters: []function.Parameter{
+			//exhaustruct:ignore
+			function.MapParameter{
+				Name:        "rule",
+				ElementType: types.StringType,
+				Description: "Rule attributes, e.g. `{ name = \"ssh\", protocol = \"tcp\", dst_port = 22, action = \"accept\" }`.",
+			},
+		},
+		//exhaustruct:ignore
+		Return: function.ObjectReturn{AttributeTypes: ruleObjectType().AttrTypes},
+	}
+}
+
+func (f *ruleFunction) Run(
+	ctx context.Context,
+	req function.RunRequest,
+	resp *function.RunResponse,
+) {
+	var values map[string]types.String
+
+	resp.Error = req.Arguments.Get(ctx, &values)
+	if resp.Error != nil {
+		return
+	}
+
+	rule, err := ruleFromMap(values)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+
+		return
+	}
+
+	rules, diags := flattenRules(ctx, []client.FirewallRule{rule}, true)
+	if diags.HasError() {
+		resp.Error = function.FuncErrorFromDiags(ctx, diags)
+
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, rules.Elements()[0])
+}
+
+// ruleFromMap returns the rule of a map of attributes, validated as the rules
+// of the resource.
+func ruleFromMap(values map[string]types.String) (client.FirewallRule, error) {
+	for key := range values {
+		if !slices.Contains(ruleFields, key) {
+			return client.FirewallRule{}, fmt.Errorf(
+				"unsupported rule attribute %q, expected one of %s",
+				key,
+				strings.Join(ruleFields, ", "),
+			)
+		}
+	}
+
+	rule := client.FirewallRule{
+		IPVersion: values["ip_version"].ValueString(),
+		Name:      values["name"].ValueString(),
+		SrcIP:     values["src_ip"].ValueString(),
+		SrcPort:   values["src_port"].ValueString(),
+		DstIP:     values["dst_ip"].ValueString(),
+		DstPort:   values["dst_port"].ValueString(),
+		Protocol:  values["protocol"].ValueString(),
+		TCPFlags:  values["tcp_flags"].ValueString(),
+		Action:    values["action"].ValueString(),
+	}
+
+	if rule.IPVersion != "" && !slices.Contains(validIPVersions, rule.IPVersion) {
+		return rule, fmt.Errorf(
+			"ip_version: %q is not one of %s",
+			rule.IPVersion,
+			strings.Join(validIPVersions, ", "),
+		)
+	}
+
+	if !slices.Contains(validActions, rule.Action) {
+		return rule, fmt.Errorf(
+			"action: %q is not one of %s",
+			rule.Action,
+			strings.Join(validActions, ", "),
+		)
+	}
+
+	err := validateRules([]client.FirewallRule{rule})
+	if err != nil {
+		return rule, err
+	}
+
+	return rule, nil
+}
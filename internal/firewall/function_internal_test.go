@@ -0,0 +1,108 @@
+package firewall
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestRuleFunction(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		rule    map[string]string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "Normalized",
+			rule: map[string]string{
+				"name":     "ssh",
+				"src_ip":   "10.1.2.3/8",
+				"dst_port": "22",
+				"protocol": "TCP",
+				"action":   "accept",
+			},
+			want: map[string]string{
+				"ip_version": ipVersion4,
+				"name":       "ssh",
+				"src_ip":     "10.0.0.0/8",
+				"dst_port":   "22",
+				"protocol":   protoTCP,
+				"action":     "accept",
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Unknown attribute",
+			rule:    map[string]string{"port": "22", "action": "accept"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "Invalid action",
+			rule:    map[string]string{"action": "drop"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "Invalid port",
+			rule:    map[string]string{"dst_port": "70000", "action": "accept"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "IP of another version",
+			rule:    map[string]string{"ip_version": ipVersion6, "src_ip": "1.2.3.4", "action": "accept"},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+
+	ctx := context.Background()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			elements := make(map[string]attr.Value, len(tt.rule))
+			for key, value := range tt.rule {
+				elements[key] = types.StringValue(value)
+			}
+
+			req := function.RunRequest{
+				Arguments: function.NewArgumentsData([]attr.Value{
+					types.MapValueMust(types.StringType, elements),
+				}),
+			}
+			//exhaustruct:ignore
+			resp := function.RunResponse{
+				Result: function.NewResultData(types.ObjectUnknown(ruleObjectType().AttrTypes)),
+			}
+
+			NewRuleFunction().Run(ctx, req, &resp)
+
+			if (resp.Error != nil) != tt.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %t", resp.Error, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			want := make(map[string]attr.Value, len(ruleFields))
+			for _, field := range ruleFields {
+				want[field] = nullableString(tt.want[field])
+			}
+
+			wantObject := types.ObjectValueMust(ruleObjectType().AttrTypes, want)
+			if got := resp.Result.Value(); !got.Equal(wantObject) {
+				t.Errorf("Run() = %s, want %s", got, wantObject)
+			}
+		})
+	}
+}
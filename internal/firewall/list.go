@@ -0,0 +1,152 @@
+package firewall
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework/list"
+	"github.com/hashicorp/terraform-plugin-framework/list/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/client"
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/query"
+)
+
+// firewallList lists the firewall of every server of the account.
+type firewallList struct {
+	client *client.HetznerRobotClient
+}
+
+// listModel maps the firewall list configuration.
+type listModel struct {
+	Name       types.String `tfsdk:"name"`
+	Datacenter types.String `tfsdk:"datacenter"`
+}
+
+// NewList returns the firewall list resource.
+//
+//nolint:ireturn
+func NewList() list.ListResource {
+	return &firewallList{client: nil}
+}
+
+func (l *firewallList) Metadata(
+	_ context.Context,
+	_ resource.MetadataRequest,
+	resp *resource.MetadataResponse,
+) {
+	resp.TypeName = ResourceType
+}
+
+func (l *firewallList) ListResourceConfigSchema(
+	_ context.Context,
+	_ list.ListResourceSchemaRequest,
+	resp *list.ListResourceSchemaResponse,
+) {
+	resp.Schema = schema.Schema{
+		Description: "Lists the firewall of every server of the account, cancelled servers excepted. " +
+			"The filters apply to the server.",
+		Attributes: map[string]schema.Attribute{
+			"name":       query.NameAttribute(),
+			"datacenter": query.DatacenterAttribute(),
+		},
+	}
+}
+
+func (l *firewallList) Configure(
+	_ context.Context,
+	req resource.ConfigureRequest,
+	resp *resource.ConfigureResponse,
+) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	hClient, ok := req.ProviderData.(*client.HetznerRobotClient)
+	if !ok {
+		resp.Diagnostics.AddError("invalid client type", fmt.Sprintf("got %T", req.ProviderData))
+
+		return
+	}
+
+	l.client = hClient
+}
+
+func (l *firewallList) List(
+	ctx context.Context,
+	req list.ListRequest,
+	stream *list.ListResultsStream,
+) {
+	var config listModel
+
+	diags := req.Config.Get(ctx, &config)
+	if diags.HasError() {
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+
+		return
+	}
+
+	servers, err := l.client.FetchAllServers(ctx)
+	if err != nil {
+		diags.AddError("error fetching servers", err.Error())
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+
+		return
+	}
+
+	stream.Results = func(push func(list.ListResult) bool) {
+		for _, server := range servers {
+			if server.Cancelled ||
+				!query.MatchName(config.Name.ValueString(), server.ServerName) ||
+				!query.MatchDatacenter(config.Datacenter.ValueString(), server.Datacenter) {
+				continue
+			}
+
+			if !push(l.listResult(ctx, req, server)) {
+				return
+			}
+		}
+	}
+}
+
+// listResult returns the list result of the firewall of server, fetching the
+// firewall only when the resource is requested.
+func (l *firewallList) listResult(
+	ctx context.Context,
+	req list.ListRequest,
+	server client.Server,
+) list.ListResult {
+	result := req.NewListResult(ctx)
+
+	serverID := strconv.Itoa(server.Number)
+
+	result.DisplayName = server.ServerName
+	if result.DisplayName == "" {
+		result.DisplayName = "server " + serverID
+	}
+
+	result.Diagnostics.Append(
+		result.Identity.Set(ctx, identityModel{ServerID: types.StringValue(serverID)})...,
+	)
+
+	if !req.IncludeResource {
+		return result
+	}
+
+	firewall, err := l.client.GetFirewall(ctx, server.IP)
+	if err != nil {
+		result.Diagnostics.AddError("error fetching firewall of server "+serverID, err.Error())
+
+		return result
+	}
+
+	model, diags := importedModel(ctx, server, *firewall)
+	result.Diagnostics.Append(diags...)
+
+	if !diags.HasError() {
+		result.Diagnostics.Append(result.Resource.Set(ctx, model)...)
+	}
+
+	return result
+}
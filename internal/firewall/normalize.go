@@ -0,0 +1,136 @@
+package firewall
+
+import (
+	"cmp"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/client"
+)
+
+// normalizeRule returns the canonical form of a rule, matching what Robot
+// returns on read: lower case protocol, flags and action, no catch-all
+// networks, bare host addresses and masked networks.
+func normalizeRule(rule client.FirewallRule) client.FirewallRule {
+	ipVersion := strings.ToLower(rule.IPVersion)
+	if ipVersion == "" {
+		ipVersion = ipVersion4
+	}
+
+	return client.FirewallRule{
+		IPVersion: ipVersion,
+		Name:      rule.Name,
+		SrcIP:     normalizeIP(rule.SrcIP),
+		SrcPort:   normalizePort(rule.SrcPort),
+		DstIP:     normalizeIP(rule.DstIP),
+		DstPort:   normalizePort(rule.DstPort),
+		Protocol:  strings.ToLower(rule.Protocol),
+		TCPFlags:  strings.ToLower(rule.TCPFlags),
+		Action:    strings.ToLower(rule.Action),
+	}
+}
+
+// normalizeIP drops catch-all networks, which Robot reports as 0.0.0.0/0,
+// turns host prefixes into plain addresses and masks the other prefixes.
+// Values that do not parse are returned as is for validation to report.
+func normalizeIP(value string) string {
+	if value == "" {
+		return ""
+	}
+
+	prefix, err := netip.ParsePrefix(value)
+	if err != nil {
+		addr, err := netip.ParseAddr(value)
+		if err != nil {
+			return value
+		}
+
+		return addr.String()
+	}
+
+	switch {
+	case prefix.Bits() == 0:
+		return ""
+	case prefix.IsSingleIP():
+		return prefix.Addr().String()
+	default:
+		return prefix.Masked().String()
+	}
+}
+
+// normalizePort turns a single port range ("22-22") into a port.
+func normalizePort(value string) string {
+	first, last, isRange := strings.Cut(value, "-")
+	if isRange && first == last {
+		return first
+	}
+
+	return value
+}
+
+// canonicalRules normalizes the rules and sorts them by name within each run
+// of consecutive rules sharing the same action. Reordering such rules cannot
+// change what the firewall lets through, so two rule sets with the same
+// canonical form are equivalent.
+func canonicalRules(rules []client.FirewallRule) []client.FirewallRule {
+	result := make([]client.FirewallRule, 0, len(rules))
+	for _, rule := range rules {
+		result = append(result, normalizeRule(rule))
+	}
+
+	compare := func(a, b client.FirewallRule) int {
+		return cmp.Or(
+			cmp.Compare(a.Name, b.Name),
+			cmp.Compare(a.IPVersion, b.IPVersion),
+			cmp.Compare(a.SrcIP, b.SrcIP),
+			cmp.Compare(a.SrcPort, b.SrcPort),
+			cmp.Compare(a.DstIP, b.DstIP),
+			cmp.Compare(a.DstPort, b.DstPort),
+			cmp.Compare(a.Protocol, b.Protocol),
+			cmp.Compare(a.TCPFlags, b.TCPFlags),
+		)
+	}
+
+	start := 0
+	for index := 1; index <= len(result); index++ {
+		if index < len(result) && result[index].Action == result[start].Action {
+			continue
+		}
+
+		slices.SortStableFunc(result[start:index], compare)
+		start = index
+	}
+
+	return result
+}
+
+// equivalentRules reports whether two rule lists only differ by ordering
+// within same-action runs or by representation.
+func equivalentRules(a, b []client.FirewallRule) bool {
+	return slices.Equal(canonicalRules(a), canonicalRules(b))
+}
+
+// matchRulesByName returns, for each rule, the index of the prior rule with
+// the same name and the same canonical form, or -1 when there is none.
+// Unnamed rules are never matched.
+func matchRulesByName(prior, rules []client.FirewallRule) []int {
+	byName := make(map[string]int, len(prior))
+	for index, rule := range prior {
+		if _, ok := byName[rule.Name]; rule.Name != "" && !ok {
+			byName[rule.Name] = index
+		}
+	}
+
+	matches := make([]int, len(rules))
+	for index, rule := range rules {
+		matches[index] = -1
+
+		match, ok := byName[rule.Name]
+		if ok && normalizeRule(rule) == normalizeRule(prior[match]) {
+			matches[index] = match
+		}
+	}
+
+	return matches
+}
@@ -0,0 +1,103 @@
+package firewall
+
+import (
+	"testing"
+
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/client"
+)
+
+func TestNormalizeIP(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "", want: ""},
+		{value: "0.0.0.0/0", want: ""},
+		{value: "::/0", want: ""},
+		{value: "1.2.3.4", want: "1.2.3.4"},
+		{value: "1.2.3.4/32", want: "1.2.3.4"},
+		{value: "10.1.2.3/8", want: "10.0.0.0/8"},
+		{value: "2001:0db8::0001/128", want: "2001:db8::1"},
+		{value: "not-an-ip", want: "not-an-ip"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Parallel()
+
+			if got := normalizeIP(tt.value); got != tt.want {
+				t.Errorf("normalizeIP(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEquivalentRules(t *testing.T) {
+	t.Parallel()
+
+	//exhaustruct:ignore
+	ssh := client.FirewallRule{Name: "ssh", DstPort: "22", Protocol: "tcp", Action: "accept"}
+	//exhaustruct:ignore
+	web := client.FirewallRule{Name: "web", DstPort: "443", Protocol: "tcp", Action: "accept"}
+	//exhaustruct:ignore
+	deny := client.FirewallRule{Name: "deny", Action: "discard"}
+	//exhaustruct:ignore
+	sshRobot := client.FirewallRule{
+		IPVersion: "ipv4",
+		Name:      "ssh",
+		SrcIP:     "0.0.0.0/0",
+		DstPort:   "22",
+		Protocol:  "TCP",
+		Action:    "accept",
+	}
+
+	tests := []struct {
+		name string
+		a    []client.FirewallRule
+		b    []client.FirewallRule
+		want bool
+	}{
+		{
+			name: "Identical",
+			a:    []client.FirewallRule{ssh, web, deny},
+			b:    []client.FirewallRule{ssh, web, deny},
+			want: true,
+		},
+		{
+			name: "Normalized by Robot",
+			a:    []client.FirewallRule{ssh, deny},
+			b:    []client.FirewallRule{sshRobot, deny},
+			want: true,
+		},
+		{
+			name: "Reordered within same action",
+			a:    []client.FirewallRule{ssh, web, deny},
+			b:    []client.FirewallRule{web, ssh, deny},
+			want: true,
+		},
+		{
+			name: "Reordered across actions",
+			a:    []client.FirewallRule{ssh, deny},
+			b:    []client.FirewallRule{deny, ssh},
+			want: false,
+		},
+		{
+			name: "Added rule",
+			a:    []client.FirewallRule{ssh, deny},
+			b:    []client.FirewallRule{web, ssh, deny},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := equivalentRules(tt.a, tt.b); got != tt.want {
+				t.Errorf("equivalentRules() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
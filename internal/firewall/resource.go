@@ -3,11 +3,26 @@ package firewall
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-
-	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"net"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/identityschema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/client"
 )
 
@@ -15,83 +30,219 @@ const (
 	// ResourceType is the type name of the Hetzner Robot Firewall resource.
 	ResourceType = "hetznerrobot_firewall"
 	statusTrue   = "active"
+	statusFalse  = "disabled"
+
+	onDestroyAllowAll        = "allow_all"
+	onDestroyDisable         = "disable"
+	onDestroyKeep            = "keep"
+	onDestroyRestorePrevious = "restore_previous"
+	// Private state key of the JSON encoded firewall configuration found when
+	// the resource was created or imported, restored by restore_previous.
+	privatePreviousFirewall = "previous_firewall"
 	// Maximum number of rules per firewall, limitation from the Robot WebUI.
+	// Enforced at plan time, after the optional compaction.
 	maxRulesPerFirewall = 10
 )
 
-// Resource defines the firewall terraform resource.
-func Resource() *schema.Resource {
-	return &schema.Resource{
-		CreateContext: resourceCreate,
-		ReadContext:   resourceRead,
-		UpdateContext: resourceUpdate,
-		DeleteContext: resourceDelete,
-		Importer: &schema.ResourceImporter{
-			StateContext: resourceFirewallImportState,
-		},
-		Schema: map[string]*schema.Schema{
-			"server_id": {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "ID of the server to which the firewall will be applied.",
+// firewallResource defines the firewall terraform resource.
+type firewallResource struct {
+	client *client.HetznerRobotClient
+}
+
+// resourceModel maps the firewall resource schema. It matches the state
+// written by the former SDKv2 implementation.
+type resourceModel struct {
+	ID            types.String `tfsdk:"id"`
+	ServerID      types.String `tfsdk:"server_id"`
+	ServerIP      types.String `tfsdk:"server_ip"`
+	Active        types.Bool   `tfsdk:"active"`
+	WhitelistHOS  types.Bool   `tfsdk:"whitelist_hos"`
+	OnDestroy     types.String `tfsdk:"on_destroy"`
+	Compact       types.Bool   `tfsdk:"compact"`
+	EffectiveRule types.List   `tfsdk:"effective_rule"`
+	Rule          types.List   `tfsdk:"rule"`
+}
+
+// identityModel maps the firewall identity schema.
+type identityModel struct {
+	ServerID types.String `tfsdk:"server_id"`
+}
+
+// ruleModel maps a rule and effective_rule element.
+type ruleModel struct {
+	IPVersion types.String `tfsdk:"ip_version"`
+	Name      types.String `tfsdk:"name"`
+	SrcIP     types.String `tfsdk:"src_ip"`
+	SrcPort   types.String `tfsdk:"src_port"`
+	DstIP     types.String `tfsdk:"dst_ip"`
+	DstPort   types.String `tfsdk:"dst_port"`
+	Protocol  types.String `tfsdk:"protocol"`
+	TCPFlags  types.String `tfsdk:"tcp_flags"`
+	Action    types.String `tfsdk:"action"`
+}
+
+// ruleFields lists the attributes of a rule, in schema order.
+//
+//nolint:gochecknoglobals
+var ruleFields = []string{
+	"ip_version",
+	"name",
+	"src_ip",
+	"src_port",
+	"dst_ip",
+	"dst_port",
+	"protocol",
+	"tcp_flags",
+	"action",
+}
+
+// ruleObjectType returns the object type of a rule.
+func ruleObjectType() types.ObjectType {
+	attrTypes := make(map[string]attr.Type, len(ruleFields))
+	for _, field := range ruleFields {
+		attrTypes[field] = types.StringType
+	}
+
+	return types.ObjectType{AttrTypes: attrTypes}
+}
+
+// NewResource returns the firewall terraform resource.
+//
+//nolint:ireturn
+func NewResource() resource.Resource {
+	return &firewallResource{client: nil}
+}
+
+func (r *firewallResource) Metadata(
+	_ context.Context,
+	_ resource.MetadataRequest,
+	resp *resource.MetadataResponse,
+) {
+	resp.TypeName = ResourceType
+	// The firewall moves to another server in place, changing its identity.
+	resp.ResourceBehavior.MutableIdentity = true
+}
+
+//nolint:funlen
+func (r *firewallResource) Schema(
+	_ context.Context,
+	_ resource.SchemaRequest,
+	resp *resource.SchemaResponse,
+) {
+	keep := []planmodifier.String{stringplanmodifier.UseStateForUnknown()}
+
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:      true,
+				PlanModifiers: keep,
+			},
+			"server_id": schema.StringAttribute{
+				Required: true,
+				Description: "ID of the server to which the firewall will be applied. Changing it applies " +
+					"the configuration to the other server in place: the firewall of the former server " +
+					"is left untouched, and `restore_previous` then restores the configuration found " +
+					"on the new server.",
 			},
-			"active": {
-				Type:        schema.TypeBool,
+			"server_ip": schema.StringAttribute{
+				Computed:      true,
+				PlanModifiers: keep,
+				Description:   "Main IPv4 address of the server, which identifies the firewall on Robot.",
+			},
+			"active": schema.BoolAttribute{
 				Required:    true,
 				Description: "Whether the firewall is active.",
 			},
-			"whitelist_hos": {
-				Type:        schema.TypeBool,
+			"whitelist_hos": schema.BoolAttribute{
 				Required:    true,
 				Description: "Whether to whitelist Hetzner services.",
 			},
-			"rule": {
-				Type:     schema.TypeList,
-				Required: true,
-				MaxItems: maxRulesPerFirewall,
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"name": {
-							Type:        schema.TypeString,
+			"on_destroy": schema.StringAttribute{
+				Optional: true,
+				Computed: true,
+				Default:  stringdefault.StaticString(onDestroyAllowAll),
+				Validators: []validator.String{
+					stringvalidator.OneOf(
+						onDestroyAllowAll,
+						onDestroyDisable,
+						onDestroyKeep,
+						onDestroyRestorePrevious,
+					),
+				},
+				Description: "What to do with the firewall when the resource is destroyed: " +
+					"`allow_all` installs a single active rule accepting all traffic, " +
+					"`disable` disables the firewall keeping its rules, " +
+					"`keep` leaves the firewall untouched and " +
+					"`restore_previous` restores the configuration found when the resource " +
+					"was created or imported, kept in the private state of the resource.",
+			},
+			"compact": schema.BoolAttribute{
+				Optional: true,
+				Computed: true,
+				Default:  booldefault.StaticBool(false),
+				Description: "Whether to merge consecutive rules sharing action, protocol, source and " +
+					"destination address into destination port ranges, to fit policies larger than " +
+					"the 10 rules allowed by Robot. The merged rules are shown in `effective_rule`.",
+			},
+			"effective_rule": schema.ListAttribute{
+				Computed:    true,
+				ElementType: ruleObjectType(),
+				Description: "Rules applied on Robot, after the optional compaction.",
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"rule": schema.ListNestedBlock{
+				Description: "Firewall rules, evaluated in order. Reordering rules that share " +
+					"the same action, or formatting differences such as protocol casing, do not " +
+					"produce a diff. Otherwise rules are matched by name and an unchanged named " +
+					"rule keeps its prior value, which Terraform only allows in a plan for a rule " +
+					"that keeps its position in the list. " +
+					"Robot allows at most 10 rules, after the optional compaction.",
+				Validators: []validator.List{listvalidator.IsRequired()},
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"ip_version": schema.StringAttribute{
+							Optional: true,
+							Computed: true,
+							Default:  stringdefault.StaticString(ipVersion4),
+							Validators: []validator.String{
+								stringvalidator.OneOf(validIPVersions...),
+							},
+							Description: "IP version the rule applies to (ipv4 or ipv6).",
+						},
+						"name": schema.StringAttribute{
 							Optional:    true,
 							Description: "Name of the firewall rule.",
 						},
-						"src_ip": {
-							Type:        schema.TypeString,
+						"src_ip": schema.StringAttribute{
 							Optional:    true,
-							Description: "Source IP address.",
+							Description: "Source IP address or CIDR, matching ip_version.",
 						},
-						"src_port": {
-							Type:        schema.TypeString,
+						"src_port": schema.StringAttribute{
 							Optional:    true,
-							Description: "Source port.",
+							Description: "Source port or port range (e.g., 1024-65535).",
 						},
-						"dst_ip": {
-							Type:        schema.TypeString,
+						"dst_ip": schema.StringAttribute{
 							Optional:    true,
-							Description: "Destination IP address.",
+							Description: "Destination IP address or CIDR, matching ip_version.",
 						},
-						"dst_port": {
-							Type:        schema.TypeString,
+						"dst_port": schema.StringAttribute{
 							Optional:    true,
-							Description: "Destination port.",
+							Description: "Destination port or port range (e.g., 1024-65535).",
 						},
-						"protocol": {
-							Type:        schema.TypeString,
+						"protocol": schema.StringAttribute{
 							Optional:    true,
-							Description: "Protocol (e.g., tcp, udp).",
+							Description: "Protocol (tcp, udp, gre, icmp, ipip, ah or esp).",
 						},
-						"tcp_flags": {
-							Type:        schema.TypeString,
+						"tcp_flags": schema.StringAttribute{
 							Optional:    true,
-							Description: "TCP flags.",
+							Description: "TCP flags combined with | or & (e.g., syn|fin). Only valid with the tcp protocol.",
 						},
-						"action": {
-							Type:     schema.TypeString,
+						"action": schema.StringAttribute{
 							Required: true,
-							ValidateDiagFunc: validation.ToDiagFunc(
-								validation.StringInSlice([]string{"accept", "discard"}, false),
-							),
+							Validators: []validator.String{
+								stringvalidator.OneOf(validActions...),
+							},
 							Description: "Action to take (accept or discard).",
 						},
 					},
@@ -101,205 +252,678 @@ func Resource() *schema.Resource {
 	}
 }
 
-func resourceCreate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
-	hClient, ok := meta.(*client.HetznerRobotClient)
+func (r *firewallResource) IdentitySchema(
+	_ context.Context,
+	_ resource.IdentitySchemaRequest,
+	resp *resource.IdentitySchemaResponse,
+) {
+	resp.IdentitySchema = identityschema.Schema{
+		Attributes: map[string]identityschema.Attribute{
+			"server_id": identityschema.StringAttribute{
+				RequiredForImport: true,
+				Description:       "ID of the server to which the firewall is applied.",
+			},
+		},
+	}
+}
+
+func (r *firewallResource) Configure(
+	_ context.Context,
+	req resource.ConfigureRequest,
+	resp *resource.ConfigureResponse,
+) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	hClient, ok := req.ProviderData.(*client.HetznerRobotClient)
 	if !ok {
-		return diag.Errorf("invalid client type")
+		resp.Diagnostics.AddError("invalid client type", fmt.Sprintf("got %T", req.ProviderData))
+
+		return
 	}
 
-	serverID := d.Get("server_id").(string)
+	r.client = hClient
+}
+
+func (r *firewallResource) Create(
+	ctx context.Context,
+	req resource.CreateRequest,
+	resp *resource.CreateResponse,
+) {
+	var plan resourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	server, err := hClient.FetchServerByID(ctx, serverID)
+	server, err := r.client.FetchServerByID(ctx, plan.ServerID.ValueString())
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("error fetching server: %w", err))
+		resp.Diagnostics.AddError("error fetching server", err.Error())
+
+		return
 	}
 
-	status := "disabled"
-	if d.Get("active").(bool) {
-		status = statusTrue
+	previous, err := r.client.GetFirewall(ctx, server.IP)
+	if err != nil {
+		resp.Diagnostics.AddError("error fetching previous firewall", err.Error())
+
+		return
 	}
 
-	rules := buildFirewallRules(d.Get("rule").([]any))
+	encoded, err := encodePreviousFirewall(*previous)
+	if err != nil {
+		resp.Diagnostics.AddError("error encoding previous firewall", err.Error())
 
-	err = hClient.SetFirewall(ctx, client.Firewall{
-		IP:                       server.IP,
-		WhitelistHetznerServices: d.Get("whitelist_hos").(bool),
-		Status:                   status,
-		Rules:                    client.FirewallRules{Input: rules},
-	})
+		return
+	}
+
+	firewall, diags := expandFirewall(ctx, plan, server.IP)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err = r.client.SetFirewall(ctx, firewall)
 	if err != nil {
-		return diag.FromErr(err)
+		resp.Diagnostics.AddError("error setting firewall", err.Error())
+
+		return
 	}
 
-	d.SetId(serverID)
+	plan.ID = plan.ServerID
+	plan.ServerIP = types.StringValue(server.IP)
 
-	return resourceRead(ctx, d, meta)
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+	resp.Diagnostics.Append(resp.Private.SetKey(ctx, privatePreviousFirewall, []byte(encoded))...)
+	resp.Diagnostics.Append(resp.Identity.Set(ctx, identityModel{ServerID: plan.ServerID})...)
 }
 
-func resourceRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
-	hClient, ok := meta.(*client.HetznerRobotClient)
-	if !ok {
-		return diag.Errorf("invalid client type")
-	}
+func (r *firewallResource) Read(
+	ctx context.Context,
+	req resource.ReadRequest,
+	resp *resource.ReadResponse,
+) {
+	var state resourceModel
 
-	serverID := d.Get("server_id").(string)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 
-	server, err := hClient.FetchServerByID(ctx, serverID)
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("error fetching server: %w", err))
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	firewall, err := hClient.GetFirewall(ctx, server.IP)
+	serverIP, err := r.resolveServerIP(ctx, state)
 	if err != nil {
-		return diag.FromErr(err)
+		resp.Diagnostics.AddError("error fetching server", err.Error())
+
+		return
 	}
 
-	err = d.Set("active", firewall.Status == statusTrue)
+	firewall, err := r.client.GetFirewall(ctx, serverIP)
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("error setting active attribute: %w", err))
+		resp.Diagnostics.AddError("error fetching firewall", err.Error())
+
+		return
 	}
 
-	err = d.Set("whitelist_hos", firewall.WhitelistHetznerServices)
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("error setting whitelist_hos attribute: %w", err))
+	// States written by SDKv2 before on_destroy and compact existed hold
+	// nulls, which the schema defaults would plan as a change.
+	if state.OnDestroy.IsNull() {
+		state.OnDestroy = types.StringValue(onDestroyAllowAll)
 	}
 
-	err = d.Set("rule", flattenFirewallRules(firewall.Rules.Input))
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("error setting rule attribute: %w", err))
+	if state.Compact.IsNull() {
+		state.Compact = types.BoolValue(false)
 	}
 
-	return nil
-}
+	state.ServerIP = types.StringValue(serverIP)
+	state.Active = types.BoolValue(firewall.Status == statusTrue)
+	state.WhitelistHOS = types.BoolValue(firewall.WhitelistHetznerServices)
+
+	resp.Diagnostics.Append(refreshRules(ctx, &state, firewall.Rules.Input)...)
 
-func resourceUpdate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
-	return resourceCreate(ctx, d, meta)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
+	resp.Diagnostics.Append(resp.Identity.Set(ctx, identityModel{ServerID: state.ServerID})...)
 }
 
-func resourceDelete(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
-	hClient, ok := meta.(*client.HetznerRobotClient)
-	if !ok {
-		return diag.Errorf("invalid client type")
+func (r *firewallResource) Update(
+	ctx context.Context,
+	req resource.UpdateRequest,
+	resp *resource.UpdateResponse,
+) {
+	var plan, state resourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	serverID := d.Get("server_id").(string)
+	serverIP := state.ServerIP.ValueString()
+	moved := !plan.ServerID.Equal(state.ServerID)
 
-	server, err := hClient.FetchServerByID(ctx, serverID)
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("error fetching server: %w", err))
-	}
-
-	// Set a rule to allow all traffic
-	err = hClient.SetFirewall(ctx, client.Firewall{
-		IP:                       server.IP,
-		WhitelistHetznerServices: false,
-		Status:                   "active",
-		Rules: client.FirewallRules{
-			Input: []client.FirewallRule{
-				{
-					Name:     "Allow all",
-					SrcIP:    "",
-					SrcPort:  "",
-					DstIP:    "",
-					DstPort:  "",
-					Protocol: "",
-					TCPFlags: "",
-					Action:   "accept",
-				},
-			},
-		},
-	})
+	if moved || serverIP == "" {
+		server, err := r.client.FetchServerByID(ctx, plan.ServerID.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError("error fetching server", err.Error())
+
+			return
+		}
+
+		serverIP = server.IP
+	}
+
+	// The configuration restored on destroy is the one of the new server.
+	if moved {
+		previous, err := r.client.GetFirewall(ctx, serverIP)
+		if err != nil {
+			resp.Diagnostics.AddError("error fetching previous firewall", err.Error())
+
+			return
+		}
+
+		encoded, err := encodePreviousFirewall(*previous)
+		if err != nil {
+			resp.Diagnostics.AddError("error encoding previous firewall", err.Error())
+
+			return
+		}
+
+		resp.Diagnostics.Append(resp.Private.SetKey(ctx, privatePreviousFirewall, []byte(encoded))...)
+	}
+
+	firewall, diags := expandFirewall(ctx, plan, serverIP)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.SetFirewall(ctx, firewall)
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("error setting firewall to allow all: %w", err))
+		resp.Diagnostics.AddError("error setting firewall", err.Error())
+
+		return
 	}
 
-	d.SetId("")
+	plan.ID = plan.ServerID
+	plan.ServerIP = types.StringValue(serverIP)
 
-	return nil
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+	resp.Diagnostics.Append(resp.Identity.Set(ctx, identityModel{ServerID: plan.ServerID})...)
 }
 
-func resourceFirewallImportState(
+func (r *firewallResource) Delete(
 	ctx context.Context,
-	d *schema.ResourceData,
-	meta any,
-) ([]*schema.ResourceData, error) {
-	hClient, ok := meta.(*client.HetznerRobotClient)
-	if !ok {
-		return nil, fmt.Errorf("invalid client type: %t", ok)
+	req resource.DeleteRequest,
+	resp *resource.DeleteResponse,
+) {
+	var state resourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	serverID := d.Id()
+	serverIP, err := r.resolveServerIP(ctx, state)
+	if err != nil {
+		resp.Diagnostics.AddError("error fetching server", err.Error())
+
+		return
+	}
 
-	server, err := hClient.FetchServerByID(ctx, serverID)
+	switch state.OnDestroy.ValueString() {
+	case onDestroyKeep:
+	case onDestroyDisable:
+		firewall, diags := expandFirewall(ctx, state, serverIP)
+		resp.Diagnostics.Append(diags...)
+
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		firewall.Status = statusFalse
+
+		err = r.client.SetFirewall(ctx, firewall)
+		if err != nil {
+			resp.Diagnostics.AddError("error disabling firewall", err.Error())
+		}
+	case onDestroyRestorePrevious:
+		encoded, diags := req.Private.GetKey(ctx, privatePreviousFirewall)
+		resp.Diagnostics.Append(diags...)
+
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		previous, err := decodePreviousFirewall(string(encoded))
+		if err != nil {
+			resp.Diagnostics.AddError("error restoring previous firewall", err.Error())
+
+			return
+		}
+
+		previous.IP = serverIP
+
+		err = r.client.SetFirewall(ctx, previous)
+		if err != nil {
+			resp.Diagnostics.AddError("error restoring previous firewall", err.Error())
+		}
+	default:
+		// Set a rule to allow all traffic
+		err = r.client.SetFirewall(ctx, client.Firewall{
+			IP:                       serverIP,
+			WhitelistHetznerServices: false,
+			FilterIPv6:               false,
+			Port:                     "",
+			Status:                   statusTrue,
+			Rules: client.FirewallRules{
+				Input: []client.FirewallRule{
+					{
+						IPVersion: "",
+						Name:      "Allow all",
+						SrcIP:     "",
+						SrcPort:   "",
+						DstIP:     "",
+						DstPort:   "",
+						Protocol:  "",
+						TCPFlags:  "",
+						Action:    "accept",
+					},
+				},
+			},
+		})
+		if err != nil {
+			resp.Diagnostics.AddError("error setting firewall to allow all", err.Error())
+		}
+	}
+}
+
+func (r *firewallResource) ImportState(
+	ctx context.Context,
+	req resource.ImportStateRequest,
+	resp *resource.ImportStateResponse,
+) {
+	id := req.ID
+
+	// Import blocks may identify the firewall by identity instead of ID.
+	if id == "" && req.Identity != nil {
+		resp.Diagnostics.Append(req.Identity.GetAttribute(ctx, path.Root("server_id"), &id)...)
+
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	server, err := importServer(ctx, r.client, id)
 	if err != nil {
-		return nil, fmt.Errorf("error fetching server: %w", err)
+		resp.Diagnostics.AddError("error importing firewall", err.Error())
+
+		return
 	}
 
-	firewall, err := hClient.GetFirewall(ctx, server.IP)
+	serverID := strconv.Itoa(server.Number)
+
+	firewall, err := r.client.GetFirewall(ctx, server.IP)
 	if err != nil {
-		return nil, fmt.Errorf("could not find firewall for server ID %s: %w", serverID, err)
+		resp.Diagnostics.AddError(
+			"could not find firewall for server ID "+serverID,
+			err.Error(),
+		)
+
+		return
 	}
 
-	err = d.Set("active", firewall.Status == "active")
+	state, diags := importedModel(ctx, server, *firewall)
+	resp.Diagnostics.Append(diags...)
+
+	encoded, err := encodePreviousFirewall(*firewall)
 	if err != nil {
-		return nil, fmt.Errorf("error setting active attribute: %w", err)
+		resp.Diagnostics.AddError("error encoding previous firewall", err.Error())
+	}
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
+	resp.Diagnostics.Append(resp.Private.SetKey(ctx, privatePreviousFirewall, []byte(encoded))...)
+	resp.Diagnostics.Append(resp.Identity.Set(ctx, identityModel{ServerID: state.ServerID})...)
+}
+
+// importedModel returns the state of an imported firewall, keeping the rules
+// as found on Robot.
+func importedModel(
+	ctx context.Context,
+	server client.Server,
+	firewall client.Firewall,
+) (resourceModel, diag.Diagnostics) {
+	rules, diags := flattenRules(ctx, firewall.Rules.Input, true)
+
+	effective, ruleDiags := flattenRules(ctx, firewall.Rules.Input, false)
+	diags.Append(ruleDiags...)
+
+	serverID := strconv.Itoa(server.Number)
+
+	return resourceModel{
+		ID:            types.StringValue(serverID),
+		ServerID:      types.StringValue(serverID),
+		ServerIP:      types.StringValue(server.IP),
+		Active:        types.BoolValue(firewall.Status == statusTrue),
+		WhitelistHOS:  types.BoolValue(firewall.WhitelistHetznerServices),
+		OnDestroy:     types.StringValue(onDestroyAllowAll),
+		Compact:       types.BoolValue(false),
+		EffectiveRule: effective,
+		Rule:          rules,
+	}, diags
+}
+
+// Helper functions.
+
+// importServer resolves the import ID, either a server number or the main
+// IPv4 address of the server.
+func importServer(
+	ctx context.Context,
+	hClient *client.HetznerRobotClient,
+	id string,
+) (client.Server, error) {
+	if ip := net.ParseIP(id); ip != nil {
+		if ip.To4() == nil {
+			return client.Server{}, fmt.Errorf(
+				"invalid import ID %q: firewalls are identified by the main IPv4 address",
+				id,
+			)
+		}
+
+		server, err := hClient.FetchServerByIP(ctx, id)
+		if err != nil {
+			return client.Server{}, fmt.Errorf("error fetching server: %w", err)
+		}
+
+		return server, nil
 	}
 
-	err = d.Set("whitelist_hos", firewall.WhitelistHetznerServices)
+	_, err := strconv.Atoi(id)
 	if err != nil {
-		return nil, fmt.Errorf("error setting whitelist_hos attribute: %w", err)
+		return client.Server{}, fmt.Errorf(
+			"invalid import ID %q: expected a server number or an IPv4 address",
+			id,
+		)
 	}
 
-	err = d.Set("rule", flattenFirewallRules(firewall.Rules.Input))
+	server, err := hClient.FetchServerByID(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("error setting rule attribute: %w", err)
+		return client.Server{}, fmt.Errorf("error fetching server: %w", err)
 	}
 
-	err = d.Set("server_id", serverID)
+	return server, nil
+}
+
+// resolveServerIP returns the IP of the server, only looking it up when it is
+// not in the state yet, e.g. in states written before server_ip existed.
+func (r *firewallResource) resolveServerIP(
+	ctx context.Context,
+	state resourceModel,
+) (string, error) {
+	if serverIP := state.ServerIP.ValueString(); serverIP != "" {
+		return serverIP, nil
+	}
+
+	server, err := r.client.FetchServerByID(ctx, state.ServerID.ValueString())
 	if err != nil {
-		return nil, fmt.Errorf("error setting server_id attribute: %w", err)
+		return "", fmt.Errorf("error fetching server: %w", err)
 	}
 
-	d.SetId(serverID)
+	return server.IP, nil
+}
+
+// refreshRules updates the rules of model from the ones found on Robot,
+// keeping the rules as ordered in the state when Robot only reordered or
+// reformatted them, to avoid perpetual diffs. Otherwise the rules found on
+// Robot are matched by name, and an unchanged rule keeps its prior value.
+func refreshRules(
+	ctx context.Context,
+	model *resourceModel,
+	remote []client.FirewallRule,
+) diag.Diagnostics {
+	expected, diags := effectiveRules(ctx, *model)
+	if diags.HasError() {
+		return diags
+	}
+
+	// States written by SDKv2 hold empty strings for unset attributes.
+	prior, diags := nullEmptyRules(ctx, model.Rule)
+	if diags.HasError() {
+		return diags
+	}
 
-	return []*schema.ResourceData{d}, nil
+	if equivalentRules(expected, remote) {
+		model.EffectiveRule, diags = flattenRules(ctx, expected, false)
+		model.Rule = prior
+
+		return diags
+	}
+
+	priorRules, diags := expandRules(ctx, prior)
+	if diags.HasError() {
+		return diags
+	}
+
+	priorModels, diags := ruleModels(ctx, prior)
+	if diags.HasError() {
+		return diags
+	}
+
+	rules, diags := flattenRules(ctx, remote, true)
+	if diags.HasError() {
+		return diags
+	}
+
+	models, diags := ruleModels(ctx, rules)
+	if diags.HasError() {
+		return diags
+	}
+
+	for index, match := range matchRulesByName(priorRules, remote) {
+		if match >= 0 {
+			models[index] = priorModels[match]
+		}
+	}
+
+	model.Rule, diags = types.ListValueFrom(ctx, ruleObjectType(), models)
+	if diags.HasError() {
+		return diags
+	}
+
+	model.EffectiveRule, diags = flattenRules(ctx, remote, false)
+
+	return diags
 }
 
-// Helper functions.
-func buildFirewallRules(ruleList []any) []client.FirewallRule {
-	rules := make([]client.FirewallRule, 0, len(ruleList))
-
-	for _, ruleMap := range ruleList {
-		ruleProps := ruleMap.(map[string]any)
-		rules = append(rules, client.FirewallRule{
-			Name:     ruleProps["name"].(string),
-			SrcIP:    ruleProps["src_ip"].(string),
-			SrcPort:  ruleProps["src_port"].(string),
-			DstIP:    ruleProps["dst_ip"].(string),
-			DstPort:  ruleProps["dst_port"].(string),
-			Protocol: ruleProps["protocol"].(string),
-			TCPFlags: ruleProps["tcp_flags"].(string),
-			Action:   ruleProps["action"].(string),
-		})
+func expandFirewall(
+	ctx context.Context,
+	model resourceModel,
+	ip string,
+) (client.Firewall, diag.Diagnostics) {
+	status := statusFalse
+	if model.Active.ValueBool() {
+		status = statusTrue
 	}
 
-	return rules
+	rules, diags := effectiveRules(ctx, model)
+
+	return client.Firewall{
+		IP:                       ip,
+		WhitelistHetznerServices: model.WhitelistHOS.ValueBool(),
+		FilterIPv6:               false,
+		Port:                     "",
+		Status:                   status,
+		Rules:                    client.FirewallRules{Input: rules},
+	}, diags
 }
 
-func flattenFirewallRules(rules []client.FirewallRule) []map[string]any {
-	result := make([]map[string]any, 0, len(rules))
+// effectiveRules returns the rules to send to Robot, compacted if requested.
+// The rule budget has already been checked at plan time.
+func effectiveRules(ctx context.Context, model resourceModel) ([]client.FirewallRule, diag.Diagnostics) {
+	rules, diags := expandRules(ctx, model.Rule)
+	if diags.HasError() || !model.Compact.ValueBool() {
+		return rules, diags
+	}
+
+	compacted := compactRules(rules)
+
+	result := make([]client.FirewallRule, 0, len(compacted))
+	for _, rule := range compacted {
+		result = append(result, rule.rule)
+	}
+
+	return result, diags
+}
+
+// ruleModels returns the elements of a rule list.
+func ruleModels(ctx context.Context, list types.List) ([]ruleModel, diag.Diagnostics) {
+	var models []ruleModel
+
+	if list.IsNull() || list.IsUnknown() {
+		return models, nil
+	}
+
+	diags := list.ElementsAs(ctx, &models, false)
+
+	return models, diags
+}
+
+// expandRules returns the normalized rules of a rule list.
+func expandRules(ctx context.Context, list types.List) ([]client.FirewallRule, diag.Diagnostics) {
+	models, diags := ruleModels(ctx, list)
+
+	rules := make([]client.FirewallRule, 0, len(models))
+	for _, model := range models {
+		rules = append(rules, normalizeRule(client.FirewallRule{
+			IPVersion: model.IPVersion.ValueString(),
+			Name:      model.Name.ValueString(),
+			SrcIP:     model.SrcIP.ValueString(),
+			SrcPort:   model.SrcPort.ValueString(),
+			DstIP:     model.DstIP.ValueString(),
+			DstPort:   model.DstPort.ValueString(),
+			Protocol:  model.Protocol.ValueString(),
+			TCPFlags:  model.TCPFlags.ValueString(),
+			Action:    model.Action.ValueString(),
+		}))
+	}
+
+	return rules, diags
+}
+
+// flattenRules returns the list value of normalized rules. Empty values are
+// null in rule, to match unset attributes of the configuration, and empty
+// strings in effective_rule.
+func flattenRules(
+	ctx context.Context,
+	rules []client.FirewallRule,
+	emptyAsNull bool,
+) (types.List, diag.Diagnostics) {
+	value := types.StringValue
+	if emptyAsNull {
+		value = nullableString
+	}
+
+	models := make([]ruleModel, 0, len(rules))
 	for _, rule := range rules {
-		result = append(result, map[string]any{
-			"name":      rule.Name,
-			"src_ip":    rule.SrcIP,
-			"src_port":  rule.SrcPort,
-			"dst_ip":    rule.DstIP,
-			"dst_port":  rule.DstPort,
-			"protocol":  rule.Protocol,
-			"tcp_flags": rule.TCPFlags,
-			"action":    rule.Action,
+		rule = normalizeRule(rule)
+
+		models = append(models, ruleModel{
+			IPVersion: types.StringValue(rule.IPVersion),
+			Name:      value(rule.Name),
+			SrcIP:     value(rule.SrcIP),
+			SrcPort:   value(rule.SrcPort),
+			DstIP:     value(rule.DstIP),
+			DstPort:   value(rule.DstPort),
+			Protocol:  value(rule.Protocol),
+			TCPFlags:  value(rule.TCPFlags),
+			Action:    types.StringValue(rule.Action),
 		})
 	}
 
-	return result
+	return types.ListValueFrom(ctx, ruleObjectType(), models)
+}
+
+// nullEmptyRules turns the empty strings of a rule list into nulls and
+// defaults a missing ip_version, keeping the other values as they are.
+func nullEmptyRules(ctx context.Context, list types.List) (types.List, diag.Diagnostics) {
+	models, diags := ruleModels(ctx, list)
+	if diags.HasError() || list.IsNull() || list.IsUnknown() {
+		return list, diags
+	}
+
+	for i := range models {
+		if models[i].IPVersion.IsNull() {
+			models[i].IPVersion = types.StringValue(ipVersion4)
+		}
+
+		for _, field := range []*types.String{
+			&models[i].Name,
+			&models[i].SrcIP,
+			&models[i].SrcPort,
+			&models[i].DstIP,
+			&models[i].DstPort,
+			&models[i].Protocol,
+			&models[i].TCPFlags,
+		} {
+			if !field.IsUnknown() {
+				*field = nullableString(field.ValueString())
+			}
+		}
+	}
+
+	return types.ListValueFrom(ctx, ruleObjectType(), models)
+}
+
+func nullableString(value string) types.String {
+	if value == "" {
+		return types.StringNull()
+	}
+
+	return types.StringValue(value)
+}
+
+func encodePreviousFirewall(firewall client.Firewall) (string, error) {
+	data, err := json.Marshal(firewall)
+	if err != nil {
+		return "", fmt.Errorf("error encoding previous firewall: %w", err)
+	}
+
+	return string(data), nil
+}
+
+func decodePreviousFirewall(data string) (client.Firewall, error) {
+	var firewall client.Firewall
+
+	if data == "" {
+		return firewall, errors.New(
+			"no previous firewall was captured for this resource, " +
+				"set on_destroy to another mode to destroy it",
+		)
+	}
+
+	err := json.Unmarshal([]byte(data), &firewall)
+	if err != nil {
+		return firewall, fmt.Errorf("error decoding previous firewall: %w", err)
+	}
+
+	// Robot reports a firewall being applied as "in process", restore it active.
+	if firewall.Status != statusFalse {
+		firewall.Status = statusTrue
+	}
+
+	return firewall, nil
 }
@@ -0,0 +1,136 @@
+package firewall
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/client"
+)
+
+func TestPreviousFirewall(t *testing.T) {
+	t.Parallel()
+
+	firewall := client.Firewall{
+		IP:                       "1.2.3.4",
+		WhitelistHetznerServices: true,
+		FilterIPv6:               false,
+		Port:                     "main",
+		Status:                   "in process",
+		Rules: client.FirewallRules{
+			//exhaustruct:ignore
+			Input: []client.FirewallRule{
+				{IPVersion: "ipv4", Name: "ssh", DstPort: "22", Protocol: "tcp", Action: "accept"},
+			},
+		},
+	}
+
+	encoded, err := encodePreviousFirewall(firewall)
+	if err != nil {
+		t.Fatalf("encodePreviousFirewall() error: %v", err)
+	}
+
+	decoded, err := decodePreviousFirewall(encoded)
+	if err != nil {
+		t.Fatalf("decodePreviousFirewall() error: %v", err)
+	}
+
+	want := firewall
+	want.Status = statusTrue
+
+	if !reflect.DeepEqual(want, decoded) {
+		t.Errorf("decoded firewall\nwant: %+v\ngot:  %+v", want, decoded)
+	}
+
+	_, err = decodePreviousFirewall("")
+	if err == nil {
+		t.Error("decodePreviousFirewall(\"\"): want error, got nil")
+	}
+}
+
+func TestImportServerInvalidID(t *testing.T) {
+	t.Parallel()
+
+	for _, id := range []string{"2001:db8::1", "server-1", ""} {
+		t.Run(id, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := importServer(context.Background(), nil, id)
+			if err == nil {
+				t.Errorf("importServer(%q) expected error", id)
+			}
+		})
+	}
+}
+
+func TestRefreshRules(t *testing.T) {
+	t.Parallel()
+
+	ssh := planRule("ssh", "22", "accept")
+	ssh.Protocol = types.StringValue("TCP")
+	ssh.SrcIP = types.StringValue("10.0.0.1/32")
+	web := planRule("web", "443", "accept")
+
+	//exhaustruct:ignore
+	remoteSSH := client.FirewallRule{
+		IPVersion: "ipv4", Name: "ssh", SrcIP: "10.0.0.1", DstPort: "22", Protocol: "tcp", Action: "accept",
+	}
+	//exhaustruct:ignore
+	remoteWeb := client.FirewallRule{IPVersion: "ipv4", Name: "web", DstPort: "443", Protocol: "tcp", Action: "accept"}
+	//exhaustruct:ignore
+	inserted := client.FirewallRule{IPVersion: "ipv4", Name: "icmp", Protocol: "icmp", Action: "accept"}
+
+	changedWeb := remoteWeb
+	changedWeb.DstPort = "8443"
+
+	icmp := planRule("icmp", "", "accept")
+	icmp.DstPort = types.StringNull()
+	icmp.Protocol = types.StringValue("icmp")
+
+	tests := []struct {
+		name      string
+		remote    []client.FirewallRule
+		wantRules []ruleModel
+	}{
+		{
+			name:      "Unchanged",
+			remote:    []client.FirewallRule{remoteSSH, remoteWeb},
+			wantRules: []ruleModel{ssh, web},
+		},
+		{
+			name:      "Rule inserted at index 0",
+			remote:    []client.FirewallRule{inserted, remoteSSH, remoteWeb},
+			wantRules: []ruleModel{icmp, ssh, web},
+		},
+		{
+			name:      "Changed rule",
+			remote:    []client.FirewallRule{remoteSSH, changedWeb},
+			wantRules: []ruleModel{ssh, planRule("web", "8443", "accept")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			model := firewallModel(t, true, ssh, web)
+
+			diags := refreshRules(ctx, &model, tt.remote)
+			if diags.HasError() {
+				t.Fatalf("refreshRules() diagnostics: %v", diags)
+			}
+
+			want := firewallModel(t, true, tt.wantRules...)
+			if !model.Rule.Equal(want.Rule) {
+				t.Errorf("refreshed rules\nwant: %s\ngot:  %s", want.Rule, model.Rule)
+			}
+
+			wantEffective, _ := flattenRules(ctx, tt.remote, false)
+			if !model.EffectiveRule.Equal(wantEffective) {
+				t.Errorf("effective rules\nwant: %s\ngot:  %s", wantEffective, model.EffectiveRule)
+			}
+		})
+	}
+}
@@ -0,0 +1,417 @@
+package firewall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/client"
+)
+
+const (
+	ipVersion4 = "ipv4"
+	ipVersion6 = "ipv6"
+	protoTCP   = "tcp"
+	maxPort    = 65535
+)
+
+// IP versions and actions accepted by the Robot firewall.
+//
+//nolint:gochecknoglobals
+var (
+	validIPVersions = []string{ipVersion4, ipVersion6}
+	validActions    = []string{"accept", "discard"}
+)
+
+// Protocols accepted by the Robot firewall.
+//
+//nolint:gochecknoglobals
+var validProtocols = []string{protoTCP, "udp", "gre", "icmp", "ipip", "ah", "esp"}
+
+// TCP flags accepted by the Robot firewall, combined with "|" (or) and "&" (and).
+//
+//nolint:gochecknoglobals
+var validTCPFlags = []string{"syn", "fin", "rst", "psh", "urg", "ack"}
+
+// ModifyPlan validates the firewall rules at plan time, so that a typo does
+// not leave the server half-configured in the middle of an apply. It also
+// computes the rules actually sent to Robot, compacted if requested, and
+// keeps the rules of the state when the configuration only reorders or
+// reformats them.
+func (r *firewallResource) ModifyPlan(
+	ctx context.Context,
+	req resource.ModifyPlanRequest,
+	resp *resource.ModifyPlanResponse,
+) {
+	// Destroy.
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan resourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	var state *resourceModel
+
+	if !req.State.Raw.IsNull() {
+		state = &resourceModel{}
+		resp.Diagnostics.Append(req.State.Get(ctx, state)...)
+	}
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The ID and IP are resolved again when the firewall moves to another server.
+	if state != nil && !plan.ServerID.Equal(state.ServerID) {
+		plan.ID = types.StringUnknown()
+		plan.ServerIP = types.StringUnknown()
+	}
+
+	models, diags := ruleModels(ctx, plan.Rule)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if plan.Rule.IsUnknown() || !rulesKnown(models) || plan.Compact.IsUnknown() {
+		plan.EffectiveRule = types.ListUnknown(ruleObjectType())
+		resp.Diagnostics.Append(resp.Plan.Set(ctx, plan)...)
+
+		return
+	}
+
+	rules, diags := expandRules(ctx, plan.Rule)
+	resp.Diagnostics.Append(diags...)
+
+	err := validateRules(rules)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("rule"), "Invalid firewall rules", err.Error())
+	}
+
+	effective, err := budgetRules(rules, plan.Compact.ValueBool())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("rule"), "Too many firewall rules", err.Error())
+	}
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	plan.EffectiveRule, diags = flattenRules(ctx, effective, false)
+	resp.Diagnostics.Append(diags...)
+
+	if state != nil {
+		resp.Diagnostics.Append(keepEquivalentRules(ctx, req.Config, &plan, *state)...)
+	}
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, plan)...)
+}
+
+// keepEquivalentRules plans the rules of the state when the configured ones
+// are equivalent. Otherwise the rules are matched by name, and an unchanged
+// rule keeps its prior value. Terraform only accepts the prior value of an
+// attribute set in the configuration, and compares list elements by index,
+// so a rule is kept when every attribute is set in both or in neither and,
+// for a single rule, when it did not move.
+func keepEquivalentRules(
+	ctx context.Context,
+	config tfsdk.Config,
+	plan *resourceModel,
+	state resourceModel,
+) diag.Diagnostics {
+	stateRules, diags := expandRules(ctx, state.Rule)
+	if diags.HasError() {
+		return diags
+	}
+
+	planRules, diags := expandRules(ctx, plan.Rule)
+	if diags.HasError() {
+		return diags
+	}
+
+	var configList types.List
+
+	diags = config.GetAttribute(ctx, path.Root("rule"), &configList)
+	if diags.HasError() {
+		return diags
+	}
+
+	configModels, diags := ruleModels(ctx, configList)
+	if diags.HasError() {
+		return diags
+	}
+
+	stateModels, diags := ruleModels(ctx, state.Rule)
+	if diags.HasError() || len(configModels) != len(planRules) {
+		return diags
+	}
+
+	if equivalentRules(stateRules, planRules) {
+		if plan.Compact.Equal(state.Compact) && !state.EffectiveRule.IsNull() {
+			plan.EffectiveRule = state.EffectiveRule
+		}
+
+		if len(configModels) == len(stateModels) && allSameNullness(configModels, stateModels) {
+			plan.Rule = state.Rule
+
+			return nil
+		}
+	}
+
+	planModels, diags := ruleModels(ctx, plan.Rule)
+	if diags.HasError() {
+		return diags
+	}
+
+	kept := false
+
+	for index, match := range matchRulesByName(stateRules, planRules) {
+		if match == index && sameNullness(configModels[index], stateModels[match]) {
+			planModels[index] = stateModels[match]
+			kept = true
+		}
+	}
+
+	if !kept {
+		return nil
+	}
+
+	plan.Rule, diags = types.ListValueFrom(ctx, ruleObjectType(), planModels)
+
+	return diags
+}
+
+// allSameNullness reports whether every rule of a has the same attributes set
+// as the rule of b at the same index.
+func allSameNullness(a, b []ruleModel) bool {
+	for i := range a {
+		if !sameNullness(a[i], b[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// sameNullness reports whether the optional attributes without default of
+// two rules are set in both or in neither.
+func sameNullness(a, b ruleModel) bool {
+	return a.Name.IsNull() == b.Name.IsNull() &&
+		a.SrcIP.IsNull() == b.SrcIP.IsNull() &&
+		a.SrcPort.IsNull() == b.SrcPort.IsNull() &&
+		a.DstIP.IsNull() == b.DstIP.IsNull() &&
+		a.DstPort.IsNull() == b.DstPort.IsNull() &&
+		a.Protocol.IsNull() == b.Protocol.IsNull() &&
+		a.TCPFlags.IsNull() == b.TCPFlags.IsNull()
+}
+
+// rulesKnown reports whether every attribute of the rules is known.
+func rulesKnown(models []ruleModel) bool {
+	for _, model := range models {
+		for _, value := range []types.String{
+			model.IPVersion,
+			model.Name,
+			model.SrcIP,
+			model.SrcPort,
+			model.DstIP,
+			model.DstPort,
+			model.Protocol,
+			model.TCPFlags,
+			model.Action,
+		} {
+			if value.IsUnknown() {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// validateRules checks every rule against the Robot syntax and returns all
+// the problems found, each prefixed with the index of the offending rule.
+func validateRules(rules []client.FirewallRule) error {
+	var errs []error
+
+	names := make(map[string]int, len(rules))
+
+	for index, rule := range rules {
+		for _, err := range validateRule(rule) {
+			errs = append(errs, fmt.Errorf("rule[%d]: %w", index, err))
+		}
+
+		if rule.Name == "" {
+			continue
+		}
+
+		if first, ok := names[rule.Name]; ok {
+			errs = append(errs, fmt.Errorf(
+				"rule[%d]: name %q is already used by rule[%d]",
+				index,
+				rule.Name,
+				first,
+			))
+
+			continue
+		}
+
+		names[rule.Name] = index
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateRule(rule client.FirewallRule) []error {
+	var errs []error
+
+	ipVersion := rule.IPVersion
+	if ipVersion == "" {
+		ipVersion = ipVersion4
+	}
+
+	for _, field := range []struct{ key, value string }{
+		{key: "src_ip", value: rule.SrcIP},
+		{key: "dst_ip", value: rule.DstIP},
+	} {
+		if field.value == "" {
+			continue
+		}
+
+		err := validateIP(field.value, ipVersion)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", field.key, err))
+		}
+	}
+
+	for _, field := range []struct{ key, value string }{
+		{key: "src_port", value: rule.SrcPort},
+		{key: "dst_port", value: rule.DstPort},
+	} {
+		if field.value == "" {
+			continue
+		}
+
+		err := validatePort(field.value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", field.key, err))
+		}
+	}
+
+	protocol := strings.ToLower(rule.Protocol)
+	if protocol != "" && !slices.Contains(validProtocols, protocol) {
+		errs = append(errs, fmt.Errorf(
+			"protocol: %q is not one of %s",
+			rule.Protocol,
+			strings.Join(validProtocols, ", "),
+		))
+	}
+
+	if rule.TCPFlags != "" {
+		if protocol != protoTCP {
+			errs = append(errs, errors.New("tcp_flags: can only be set when protocol is tcp"))
+		}
+
+		err := validateTCPFlags(rule.TCPFlags)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("tcp_flags: %w", err))
+		}
+	}
+
+	return errs
+}
+
+// validateIP accepts an address or a CIDR of the given ip version.
+func validateIP(value, ipVersion string) error {
+	ip := net.ParseIP(value)
+	if ip == nil {
+		var err error
+
+		ip, _, err = net.ParseCIDR(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a valid address or CIDR", value)
+		}
+	}
+
+	isIPv4 := ip.To4() != nil
+	if (ipVersion == ipVersion4) != isIPv4 {
+		return fmt.Errorf("%q does not match ip_version %s", value, ipVersion)
+	}
+
+	return nil
+}
+
+// validatePort accepts a port ("22") or a port range ("1024-65535").
+func validatePort(value string) error {
+	first, last, isRange := strings.Cut(value, "-")
+
+	from, err := parsePort(first)
+	if err != nil {
+		return fmt.Errorf("%q: %w", value, err)
+	}
+
+	if !isRange {
+		return nil
+	}
+
+	to, err := parsePort(last)
+	if err != nil {
+		return fmt.Errorf("%q: %w", value, err)
+	}
+
+	if from > to {
+		return fmt.Errorf("%q: range start is greater than its end", value)
+	}
+
+	return nil
+}
+
+func parsePort(value string) (int, error) {
+	port, err := strconv.Atoi(value)
+	if err != nil || port < 0 || port > maxPort {
+		return 0, fmt.Errorf("%q is not a port between 0 and %d", value, maxPort)
+	}
+
+	return port, nil
+}
+
+// validateTCPFlags accepts flags combined with either "|" or "&", e.g. "syn|fin".
+func validateTCPFlags(value string) error {
+	separator := "|"
+
+	if strings.Contains(value, "&") {
+		if strings.Contains(value, "|") {
+			return fmt.Errorf("%q mixes | and &", value)
+		}
+
+		separator = "&"
+	}
+
+	for _, flag := range strings.Split(value, separator) {
+		if !slices.Contains(validTCPFlags, flag) {
+			return fmt.Errorf(
+				"%q: %q is not one of %s",
+				value,
+				flag,
+				strings.Join(validTCPFlags, ", "),
+			)
+		}
+	}
+
+	return nil
+}
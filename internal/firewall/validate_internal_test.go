@@ -0,0 +1,311 @@
+package firewall
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/client"
+)
+
+func TestValidateRules(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		rules    []client.FirewallRule
+		wantErrs []string
+	}{
+		{
+			name: "Valid",
+			//exhaustruct:ignore
+			rules: []client.FirewallRule{
+				{
+					Name:     "ssh",
+					SrcIP:    "10.0.0.0/8",
+					DstPort:  "22",
+					Protocol: "tcp",
+					Action:   "accept",
+				},
+				{Name: "ephemeral", DstPort: "32768-65535", Protocol: "UDP", Action: "accept"},
+				{IPVersion: "ipv6", SrcIP: "2001:db8::/32", Protocol: "icmp", Action: "accept"},
+				{Name: "syn", Protocol: "tcp", TCPFlags: "syn|fin", Action: "discard"},
+				{Action: "discard"},
+			},
+			wantErrs: nil,
+		},
+		{
+			name: "Invalid IPs",
+			//exhaustruct:ignore
+			rules: []client.FirewallRule{
+				{SrcIP: "10.0.0.0/33", Action: "accept"},
+				{DstIP: "2001:db8::1", Action: "accept"},
+				{IPVersion: "ipv6", SrcIP: "1.2.3.4", Action: "accept"},
+			},
+			wantErrs: []string{
+				`rule[0]: src_ip: "10.0.0.0/33" is not a valid address or CIDR`,
+				`rule[1]: dst_ip: "2001:db8::1" does not match ip_version ipv4`,
+				`rule[2]: src_ip: "1.2.3.4" does not match ip_version ipv6`,
+			},
+		},
+		{
+			name: "Invalid ports",
+			//exhaustruct:ignore
+			rules: []client.FirewallRule{
+				{DstPort: "65536", Action: "accept"},
+				{SrcPort: "200-100", Action: "accept"},
+				{DstPort: "ssh", Action: "accept"},
+			},
+			wantErrs: []string{
+				`rule[0]: dst_port: "65536"`,
+				`rule[1]: src_port: "200-100": range start is greater than its end`,
+				`rule[2]: dst_port: "ssh"`,
+			},
+		},
+		{
+			name: "Invalid protocol and flags",
+			//exhaustruct:ignore
+			rules: []client.FirewallRule{
+				{Protocol: "sctp", Action: "accept"},
+				{Protocol: "udp", TCPFlags: "syn", Action: "accept"},
+				{Protocol: "tcp", TCPFlags: "syn|ack&fin", Action: "accept"},
+				{Protocol: "tcp", TCPFlags: "syn||ack", Action: "accept"},
+			},
+			wantErrs: []string{
+				`rule[0]: protocol: "sctp" is not one of`,
+				`rule[1]: tcp_flags: can only be set when protocol is tcp`,
+				`rule[2]: tcp_flags: "syn|ack&fin" mixes | and &`,
+				`rule[3]: tcp_flags: "syn||ack": "" is not one of`,
+			},
+		},
+		{
+			name: "Duplicated names",
+			//exhaustruct:ignore
+			rules: []client.FirewallRule{
+				{Name: "ssh", Action: "accept"},
+				{Name: "web", Action: "accept"},
+				{Name: "ssh", Action: "discard"},
+			},
+			wantErrs: []string{`rule[2]: name "ssh" is already used by rule[0]`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateRules(tt.rules)
+
+			if tt.wantErrs == nil {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+
+				return
+			}
+
+			if err == nil {
+				t.Fatalf("want errors %v, got nil", tt.wantErrs)
+			}
+
+			for _, wantErr := range tt.wantErrs {
+				if !strings.Contains(err.Error(), wantErr) {
+					t.Errorf("want error containing %s, got %s", wantErr, err)
+				}
+			}
+		})
+	}
+}
+
+// planRule returns a rule as planned, with the unset attributes null.
+func planRule(name, dstPort, action string) ruleModel {
+	return ruleModel{
+		IPVersion: types.StringValue(ipVersion4),
+		Name:      types.StringValue(name),
+		SrcIP:     types.StringNull(),
+		SrcPort:   types.StringNull(),
+		DstIP:     types.StringNull(),
+		DstPort:   types.StringValue(dstPort),
+		Protocol:  types.StringValue(protoTCP),
+		TCPFlags:  types.StringNull(),
+		Action:    types.StringValue(action),
+	}
+}
+
+// firewallModel returns a firewall of rules, with the computed attributes
+// known when created is set.
+func firewallModel(t *testing.T, created bool, rules ...ruleModel) resourceModel {
+	t.Helper()
+
+	ruleList, diags := types.ListValueFrom(context.Background(), ruleObjectType(), rules)
+	if diags.HasError() {
+		t.Fatalf("rule list: %v", diags)
+	}
+
+	model := resourceModel{
+		ID:            types.StringUnknown(),
+		ServerID:      types.StringValue("1"),
+		ServerIP:      types.StringUnknown(),
+		Active:        types.BoolValue(true),
+		WhitelistHOS:  types.BoolValue(true),
+		OnDestroy:     types.StringValue(onDestroyAllowAll),
+		Compact:       types.BoolValue(false),
+		EffectiveRule: types.ListUnknown(ruleObjectType()),
+		Rule:          ruleList,
+	}
+
+	if created {
+		model.ID = types.StringValue("1")
+		model.ServerIP = types.StringValue("1.2.3.4")
+		model.EffectiveRule = ruleList
+	}
+
+	return model
+}
+
+func TestModifyPlan(t *testing.T) {
+	t.Parallel()
+
+	ssh := planRule("ssh", "22", "accept")
+	web := planRule("web", "443", "accept")
+	discard := planRule("other", "0-65535", "discard")
+
+	// Same rules as ssh and web, formatted differently.
+	sshUpper := planRule("ssh", "22", "accept")
+	sshUpper.Protocol = types.StringValue("TCP")
+	webRange := planRule("web", "443-443", "accept")
+
+	icmp := planRule("icmp", "", "accept")
+	icmp.DstPort = types.StringNull()
+	icmp.Protocol = types.StringValue("icmp")
+
+	invalid := planRule("ssh", "22", "accept")
+	invalid.TCPFlags = types.StringValue("syn")
+	invalid.Protocol = types.StringValue("udp")
+
+	tests := []struct {
+		name      string
+		plan      []ruleModel
+		state     []ruleModel
+		wantRules []ruleModel
+		wantErr   bool
+	}{
+		{
+			name:      "Create",
+			plan:      []ruleModel{ssh, web},
+			state:     nil,
+			wantRules: []ruleModel{ssh, web},
+			wantErr:   false,
+		},
+		{
+			name:      "Reordered rules keep the state",
+			plan:      []ruleModel{web, ssh, discard},
+			state:     []ruleModel{ssh, web, discard},
+			wantRules: []ruleModel{ssh, web, discard},
+			wantErr:   false,
+		},
+		{
+			name:      "Reordered actions change the plan",
+			plan:      []ruleModel{discard, ssh},
+			state:     []ruleModel{ssh, discard},
+			wantRules: []ruleModel{discard, ssh},
+			wantErr:   false,
+		},
+		{
+			name:      "Appended rule keeps the prior rules",
+			plan:      []ruleModel{sshUpper, webRange, discard},
+			state:     []ruleModel{ssh, web},
+			wantRules: []ruleModel{ssh, web, discard},
+			wantErr:   false,
+		},
+		{
+			name:      "Rule inserted at index 0 moves the prior rules",
+			plan:      []ruleModel{icmp, sshUpper, web},
+			state:     []ruleModel{ssh, web},
+			wantRules: []ruleModel{icmp, sshUpper, web},
+			wantErr:   false,
+		},
+		{
+			name:      "Changed rule keeps the others",
+			plan:      []ruleModel{sshUpper, planRule("web", "8443", "accept"), discard},
+			state:     []ruleModel{ssh, web, discard},
+			wantRules: []ruleModel{ssh, planRule("web", "8443", "accept"), discard},
+			wantErr:   false,
+		},
+		{
+			name:      "Invalid rule",
+			plan:      []ruleModel{invalid},
+			state:     nil,
+			wantRules: nil,
+			wantErr:   true,
+		},
+	}
+
+	ctx := context.Background()
+	res := &firewallResource{client: nil}
+
+	//exhaustruct:ignore
+	schemaResp := resource.SchemaResponse{}
+	res.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			empty := tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil)
+			plan := tfsdk.Plan{Schema: schemaResp.Schema, Raw: empty}
+			state := tfsdk.State{Schema: schemaResp.Schema, Raw: empty}
+
+			if diags := plan.Set(ctx, firewallModel(t, false, tt.plan...)); diags.HasError() {
+				t.Fatalf("plan: %v", diags)
+			}
+
+			if tt.state != nil {
+				if diags := state.Set(ctx, firewallModel(t, true, tt.state...)); diags.HasError() {
+					t.Fatalf("state: %v", diags)
+				}
+			}
+
+			req := resource.ModifyPlanRequest{
+				Config:       tfsdk.Config{Schema: schemaResp.Schema, Raw: plan.Raw},
+				Plan:         plan,
+				State:        state,
+				Private:      nil,
+				ProviderMeta: tfsdk.Config{Schema: nil, Raw: tftypes.Value{}},
+				ClientCapabilities: resource.ModifyPlanClientCapabilities{
+					DeferralAllowed: false,
+				},
+			}
+			//exhaustruct:ignore
+			resp := resource.ModifyPlanResponse{Plan: plan}
+
+			res.ModifyPlan(ctx, req, &resp)
+
+			if resp.Diagnostics.HasError() != tt.wantErr {
+				t.Fatalf("ModifyPlan() diagnostics: %v", resp.Diagnostics)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			var got resourceModel
+			if diags := resp.Plan.Get(ctx, &got); diags.HasError() {
+				t.Fatalf("plan: %v", diags)
+			}
+
+			want := firewallModel(t, false, tt.wantRules...)
+			if !got.Rule.Equal(want.Rule) {
+				t.Errorf("planned rules\nwant: %s\ngot:  %s", want.Rule, got.Rule)
+			}
+
+			if got.EffectiveRule.IsUnknown() {
+				t.Error("effective_rule is unknown with known rules")
+			}
+		})
+	}
+}
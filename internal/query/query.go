@@ -0,0 +1,80 @@
+// Package query holds the helpers shared by the list resources backing
+// `terraform query`.
+package query
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/list/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// NameAttribute returns the filter matching the name of the listed objects.
+func NameAttribute() schema.StringAttribute {
+	return schema.StringAttribute{
+		Optional: true,
+		Description: "Only list the objects whose name matches this shell pattern " +
+			"(e.g. `web-*`). Lists all of them when not set.",
+		Validators: []validator.String{patternValidator{}},
+	}
+}
+
+// DatacenterAttribute returns the filter matching the datacenter of the
+// servers related to the listed objects.
+func DatacenterAttribute() schema.StringAttribute {
+	return schema.StringAttribute{
+		Optional: true,
+		Description: "Only list the objects located in datacenters starting with this " +
+			"value, case-insensitive (e.g. `FSN1` matches `FSN1-DC14`). Lists all of them when not set.",
+	}
+}
+
+// MatchName reports whether name matches the shell pattern, an empty pattern
+// matching every name. The pattern is validated by NameAttribute.
+func MatchName(pattern, name string) bool {
+	if pattern == "" {
+		return true
+	}
+
+	matched, err := path.Match(pattern, name)
+
+	return err == nil && matched
+}
+
+// MatchDatacenter reports whether datacenter starts with prefix, ignoring case.
+func MatchDatacenter(prefix, datacenter string) bool {
+	return strings.HasPrefix(strings.ToUpper(datacenter), strings.ToUpper(prefix))
+}
+
+// patternValidator checks that a name filter is a valid shell pattern.
+type patternValidator struct{}
+
+func (v patternValidator) Description(_ context.Context) string {
+	return "value must be a valid shell pattern"
+}
+
+func (v patternValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v patternValidator) ValidateString(
+	_ context.Context,
+	req validator.StringRequest,
+	resp *validator.StringResponse,
+) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	_, err := path.Match(req.ConfigValue.ValueString(), "")
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid name pattern",
+			fmt.Sprintf("%q is not a valid shell pattern: %s", req.ConfigValue.ValueString(), err),
+		)
+	}
+}
@@ -0,0 +1,53 @@
+package query_test
+
+import (
+	"testing"
+
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/query"
+)
+
+func TestMatchName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{pattern: "", name: "web-1", want: true},
+		{pattern: "web-*", name: "web-1", want: true},
+		{pattern: "web-?", name: "web-10", want: false},
+		{pattern: "db", name: "db-1", want: false},
+		{pattern: "[", name: "[", want: false},
+	}
+
+	for _, tt := range tests {
+		got := query.MatchName(tt.pattern, tt.name)
+		if got != tt.want {
+			t.Errorf("MatchName(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMatchDatacenter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		prefix     string
+		datacenter string
+		want       bool
+	}{
+		{prefix: "", datacenter: "FSN1-DC14", want: true},
+		{prefix: "fsn1", datacenter: "FSN1-DC14", want: true},
+		{prefix: "FSN1-DC14", datacenter: "FSN1-DC14", want: true},
+		{prefix: "HEL1", datacenter: "FSN1-DC14", want: false},
+		{prefix: "FSN1", datacenter: "", want: false},
+	}
+
+	for _, tt := range tests {
+		got := query.MatchDatacenter(tt.prefix, tt.datacenter)
+		if got != tt.want {
+			t.Errorf("MatchDatacenter(%q, %q) = %v, want %v", tt.prefix, tt.datacenter, got, tt.want)
+		}
+	}
+}
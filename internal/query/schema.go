@@ -0,0 +1,90 @@
+package query
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/list"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// SDKSchemas returns the protocol 6 schemas of an SDKv2 resource, which the
+// framework needs to list resources it does not serve itself.
+func SDKSchemas(ctx context.Context, res *schema.Resource, resp *list.RawV6SchemaResponse) {
+	proto := res.ProtoSchema(ctx)()
+
+	resp.ProtoV6Schema = &tfprotov6.Schema{
+		Version: proto.Version,
+		Block:   upgradeBlock(proto.Block),
+	}
+
+	if res.Identity == nil {
+		return
+	}
+
+	identity := res.ProtoIdentitySchema(ctx)()
+
+	attributes := make([]*tfprotov6.ResourceIdentitySchemaAttribute, 0, len(identity.IdentityAttributes))
+	for _, attribute := range identity.IdentityAttributes {
+		attributes = append(attributes, &tfprotov6.ResourceIdentitySchemaAttribute{
+			Name:              attribute.Name,
+			Type:              attribute.Type,
+			RequiredForImport: attribute.RequiredForImport,
+			OptionalForImport: attribute.OptionalForImport,
+			Description:       attribute.Description,
+		})
+	}
+
+	resp.ProtoV6IdentitySchema = &tfprotov6.ResourceIdentitySchema{
+		Version:            identity.Version,
+		IdentityAttributes: attributes,
+	}
+}
+
+// upgradeBlock converts a protocol 5 schema block to protocol 6, which only
+// adds nested attribute types the SDKv2 never uses.
+func upgradeBlock(block *tfprotov5.SchemaBlock) *tfprotov6.SchemaBlock {
+	if block == nil {
+		return nil
+	}
+
+	attributes := make([]*tfprotov6.SchemaAttribute, 0, len(block.Attributes))
+	for _, attribute := range block.Attributes {
+		attributes = append(attributes, &tfprotov6.SchemaAttribute{
+			Name:               attribute.Name,
+			Type:               attribute.Type,
+			NestedType:         nil,
+			Description:        attribute.Description,
+			Required:           attribute.Required,
+			Optional:           attribute.Optional,
+			Computed:           attribute.Computed,
+			Sensitive:          attribute.Sensitive,
+			DescriptionKind:    tfprotov6.StringKind(attribute.DescriptionKind),
+			Deprecated:         attribute.Deprecated,
+			WriteOnly:          attribute.WriteOnly,
+			DeprecationMessage: attribute.DeprecationMessage,
+		})
+	}
+
+	blockTypes := make([]*tfprotov6.SchemaNestedBlock, 0, len(block.BlockTypes))
+	for _, nested := range block.BlockTypes {
+		blockTypes = append(blockTypes, &tfprotov6.SchemaNestedBlock{
+			TypeName: nested.TypeName,
+			Block:    upgradeBlock(nested.Block),
+			Nesting:  tfprotov6.SchemaNestedBlockNestingMode(nested.Nesting),
+			MinItems: nested.MinItems,
+			MaxItems: nested.MaxItems,
+		})
+	}
+
+	return &tfprotov6.SchemaBlock{
+		Version:            block.Version,
+		Attributes:         attributes,
+		BlockTypes:         blockTypes,
+		Description:        block.Description,
+		DescriptionKind:    tfprotov6.StringKind(block.DescriptionKind),
+		Deprecated:         block.Deprecated,
+		DeprecationMessage: block.DeprecationMessage,
+	}
+}
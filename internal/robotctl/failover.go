@@ -0,0 +1,118 @@
+package robotctl
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/client"
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/failover"
+)
+
+func failoverCommands() []command {
+	//exhaustruct:ignore
+	return []command{
+		{
+			subsystem: "failover",
+			name:      "list",
+			summary:   "List the failover IPs",
+			setup: func(*flag.FlagSet) func(context.Context, *runner, []string) error {
+				return failoverList
+			},
+		},
+		{
+			subsystem: "failover",
+			name:      "show",
+			args:      "<ip>",
+			nargs:     1,
+			summary:   "Show the routing of a failover IP",
+			setup: func(*flag.FlagSet) func(context.Context, *runner, []string) error {
+				return failoverShow
+			},
+		},
+		{
+			subsystem: "failover",
+			name:      "switch",
+			args:      "<ip> <active-server-ip>",
+			nargs:     2,
+			summary:   "Route a failover IP to another server",
+			setup:     setupFailoverSwitch,
+		},
+	}
+}
+
+//nolint:gochecknoglobals
+var failoverHeader = []string{"IP", "NETMASK", "SERVER", "SERVER_IP", "ACTIVE_SERVER_IP"}
+
+func failoverRows(failovers []client.Failover) [][]string {
+	rows := make([][]string, len(failovers))
+	for i, failover := range failovers {
+		rows[i] = []string{
+			failover.IP,
+			failover.Netmask,
+			strconv.Itoa(failover.ServerNumber),
+			failover.ServerIP,
+			failover.ActiveServerIP,
+		}
+	}
+
+	return rows
+}
+
+func failoverList(ctx context.Context, r *runner, _ []string) error {
+	failovers, err := r.client.FetchAllFailovers(ctx)
+	if err != nil {
+		return fmt.Errorf("error fetching failover IPs: %w", err)
+	}
+
+	return r.print(failovers, failoverHeader, failoverRows(failovers))
+}
+
+func failoverShow(ctx context.Context, r *runner, args []string) error {
+	rec, err := r.client.FetchFailover(ctx, args[0])
+	if err != nil {
+		return fmt.Errorf("error fetching failover %s: %w", args[0], err)
+	}
+
+	return r.print(rec, failoverHeader, failoverRows([]client.Failover{rec}))
+}
+
+func setupFailoverSwitch(flags *flag.FlagSet) func(context.Context, *runner, []string) error {
+	wait := flags.Bool("wait", false, "Wait for Robot to report the new route")
+
+	return func(ctx context.Context, r *runner, args []string) error {
+		ip, target := args[0], args[1]
+
+		rec, err := r.client.FetchFailover(ctx, ip)
+		if err != nil {
+			return fmt.Errorf("error fetching failover %s: %w", ip, err)
+		}
+
+		if rec.ActiveServerIP == target {
+			r.progress("Failover %s already routes to %s", ip, target)
+
+			return r.print(rec, failoverHeader, failoverRows([]client.Failover{rec}))
+		}
+
+		r.progress("Routing failover %s from %s to %s", ip, rec.ActiveServerIP, target)
+
+		err = r.client.SetFailover(ctx, ip, target)
+		if err != nil {
+			return fmt.Errorf("error routing failover %s: %w", ip, err)
+		}
+
+		if *wait {
+			err = failover.WaitForRoute(ctx, r.client, ip, target, func(active string) {
+				r.progress("Failover %s still routes to %s", ip, active)
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		rec.ActiveServerIP = target
+
+		return r.print(rec, failoverHeader, failoverRows([]client.Failover{rec}))
+	}
+}
@@ -0,0 +1,77 @@
+package robotctl
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/netip"
+)
+
+func firewallCommands() []command {
+	//exhaustruct:ignore
+	return []command{
+		{
+			subsystem: "firewall",
+			name:      "show",
+			args:      "<server-id|server-ip>",
+			nargs:     1,
+			summary:   "Show the firewall of a server",
+			setup: func(*flag.FlagSet) func(context.Context, *runner, []string) error {
+				return firewallShow
+			},
+		},
+	}
+}
+
+// firewallShow prints the firewall of a server, as a summary line followed by
+// the rules in table output.
+func firewallShow(ctx context.Context, r *runner, args []string) error {
+	ip := args[0]
+
+	// Like the firewall resource, accept the server number or its main IP.
+	if _, err := netip.ParseAddr(ip); err != nil {
+		server, err := r.client.FetchServerByID(ctx, args[0])
+		if err != nil {
+			return fmt.Errorf("error fetching server %s: %w", args[0], err)
+		}
+
+		ip = server.IP
+	}
+
+	firewall, err := r.client.GetFirewall(ctx, ip)
+	if err != nil {
+		return fmt.Errorf("error fetching firewall of %s: %w", args[0], err)
+	}
+
+	firewall.IP = ip
+
+	rows := make([][]string, 0, len(firewall.Rules.Input))
+	for _, rule := range firewall.Rules.Input {
+		rows = append(rows, []string{
+			rule.IPVersion,
+			rule.Name,
+			rule.SrcIP,
+			rule.SrcPort,
+			rule.DstIP,
+			rule.DstPort,
+			rule.Protocol,
+			rule.TCPFlags,
+			rule.Action,
+		})
+	}
+
+	if r.output == outputTable {
+		_, _ = fmt.Fprintf(
+			r.stdout,
+			"Firewall of %s: %s, Hetzner services whitelisted: %t\n\n",
+			ip,
+			firewall.Status,
+			firewall.WhitelistHetznerServices,
+		)
+	}
+
+	return r.print(firewall, []string{
+		"IP_VERSION", "NAME", "SRC_IP", "SRC_PORT",
+		"DST_IP", "DST_PORT", "PROTOCOL", "TCP_FLAGS", "ACTION",
+	}, rows)
+}
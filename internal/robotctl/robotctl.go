@@ -0,0 +1,188 @@
+// Package robotctl implements robotctl, a command-line tool for the Robot
+// operations needed without a Terraform workspace, e.g. during an incident.
+// It shares the client, the credential sources and the waiters of the
+// provider.
+package robotctl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/cli"
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/client"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+var (
+	// ErrUsage is returned for an unknown command or wrong arguments.
+	ErrUsage = errors.New("invalid usage")
+	// ErrUnknownOutput is returned for an output other than table and json.
+	ErrUnknownOutput = errors.New("unknown output")
+)
+
+// command is a subcommand of a subsystem, e.g. server reset.
+type command struct {
+	subsystem string
+	name      string
+	// args describes the positional arguments, nargs is their number. The
+	// last one repeats if variadic.
+	args     string
+	nargs    int
+	variadic bool
+	summary  string
+	// setup registers the flags of the command and returns its runner.
+	setup func(flags *flag.FlagSet) func(ctx context.Context, r *runner, args []string) error
+}
+
+// commands returns the commands, in the order of the usage.
+func commands() []command {
+	return slices.Concat(serverCommands(), failoverCommands(), firewallCommands(), vswitchCommands())
+}
+
+// runner holds what commands use to talk to Robot and to the user.
+type runner struct {
+	client *client.HetznerRobotClient
+	output string
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// progress reports an ongoing step on stderr, so that stdout stays parsable.
+func (r *runner) progress(format string, args ...any) {
+	_, _ = fmt.Fprintf(r.stderr, format+"\n", args...)
+}
+
+// print writes value as JSON, or header and rows as a table.
+func (r *runner) print(value any, header []string, rows [][]string) error {
+	if r.output == outputJSON {
+		encoder := json.NewEncoder(r.stdout)
+		encoder.SetIndent("", "  ")
+
+		err := encoder.Encode(value)
+		if err != nil {
+			return fmt.Errorf("error writing output: %w", err)
+		}
+
+		return nil
+	}
+
+	table := tabwriter.NewWriter(r.stdout, 0, 0, 2, ' ', 0)
+
+	_, _ = fmt.Fprintln(table, strings.Join(header, "\t"))
+	for _, row := range rows {
+		_, _ = fmt.Fprintln(table, strings.Join(row, "\t"))
+	}
+
+	err := table.Flush()
+	if err != nil {
+		return fmt.Errorf("error writing output: %w", err)
+	}
+
+	return nil
+}
+
+// Run runs robotctl with args, without the program name.
+func Run(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	if len(args) < 2 || strings.HasPrefix(args[0], "-") {
+		usage(stderr)
+
+		if len(args) == 1 && (args[0] == "-h" || args[0] == "--help" || args[0] == "help") {
+			return flag.ErrHelp
+		}
+
+		return fmt.Errorf("%w: expected a subsystem and a command", ErrUsage)
+	}
+
+	for _, cmd := range commands() {
+		if cmd.subsystem == args[0] && cmd.name == args[1] {
+			return cmd.run(ctx, args[2:], stdout, stderr)
+		}
+	}
+
+	usage(stderr)
+
+	return fmt.Errorf("%w: unknown command %q", ErrUsage, args[0]+" "+args[1])
+}
+
+// run parses the flags, wherever they are among the arguments, and runs cmd.
+func (cmd command) run(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	var config cli.Config
+
+	flags := flag.NewFlagSet("robotctl "+cmd.subsystem+" "+cmd.name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		_, _ = fmt.Fprintf(stderr, "Usage: %s [flags] %s\n\n%s\n\nFlags:\n", flags.Name(), cmd.args, cmd.summary)
+		flags.PrintDefaults()
+	}
+
+	output := flags.String("output", outputTable, "Output format, table or json")
+	config.RegisterFlags(flags)
+	runCmd := cmd.setup(flags)
+
+	positional, err := parseInterspersed(flags, args)
+	if err != nil {
+		return fmt.Errorf("error parsing flags: %w", err)
+	}
+
+	if *output != outputTable && *output != outputJSON {
+		return fmt.Errorf("%w %q, want %s or %s", ErrUnknownOutput, *output, outputTable, outputJSON)
+	}
+
+	if len(positional) < cmd.nargs || (!cmd.variadic && len(positional) > cmd.nargs) {
+		flags.Usage()
+
+		return fmt.Errorf("%w: %s expects %s", ErrUsage, flags.Name(), cmd.args)
+	}
+
+	hClient, err := config.Client(ctx)
+	if err != nil {
+		return err
+	}
+
+	return runCmd(ctx, &runner{client: hClient, output: *output, stdout: stdout, stderr: stderr}, positional)
+}
+
+// parseInterspersed parses flags placed before, between or after the
+// positional arguments, which it returns.
+func parseInterspersed(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+
+	for {
+		err := flags.Parse(args)
+		if err != nil {
+			return nil, err
+		}
+
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func usage(w io.Writer) {
+	_, _ = fmt.Fprint(w, "Usage: robotctl <subsystem> <command> [flags] [args]\n\nCommands:\n")
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, cmd := range commands() {
+		_, _ = fmt.Fprintf(table, "  %s %s %s\t%s\n", cmd.subsystem, cmd.name, cmd.args, cmd.summary)
+	}
+
+	_ = table.Flush()
+
+	_, _ = fmt.Fprint(w, "\nRun robotctl <subsystem> <command> --help for the flags of a command.\n")
+}
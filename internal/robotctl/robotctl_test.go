@@ -0,0 +1,205 @@
+package robotctl_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/client"
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/robotctl"
+)
+
+// mockRobot serves two servers, a failover IP routed by POST requests and a
+// vSwitch, recording the POST requests.
+func mockRobot(t *testing.T) *[]string {
+	t.Helper()
+
+	var (
+		mu       sync.Mutex
+		requests []string
+		route    = "1.2.3.4"
+	)
+
+	robot := httptest.NewServer(
+		http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			err := req.ParseForm()
+			if err != nil {
+				t.Errorf("ParseForm() error: %v", err)
+			}
+
+			if req.Method == http.MethodPost {
+				requests = append(requests, req.URL.Path+"?"+req.PostForm.Encode())
+			}
+
+			switch req.URL.Path {
+			case "/server":
+				_, _ = writer.Write([]byte(`[` +
+					`{"server":{"server_ip":"1.2.3.4","server_number":1,"server_name":"web-1",` +
+					`"product":"AX41","dc":"FSN1-DC14","status":"ready"}},` +
+					`{"server":{"server_ip":"5.6.7.8","server_number":2,"server_name":"db-1",` +
+					`"product":"AX101","dc":"HEL1-DC2","status":"ready"}}]`))
+			case "/server/1":
+				_, _ = writer.Write([]byte(`{"server":{"server_ip":"1.2.3.4","server_number":1}}`))
+			case "/failover/9.9.9.9":
+				if req.Method == http.MethodPost {
+					route = req.PostForm.Get("active_server_ip")
+				}
+
+				_, _ = fmt.Fprintf(writer, `{"failover":{"ip":"9.9.9.9","netmask":"255.255.255.255",`+
+					`"server_ip":"1.2.3.4","server_number":1,"active_server_ip":%q}}`, route)
+			case "/firewall/1.2.3.4":
+				_, _ = writer.Write([]byte(`{"firewall":{"server_ip":"1.2.3.4","status":"active",` +
+					`"whitelist_hos":true,"rules":{"input":[{"ip_version":"ipv4","name":"ssh",` +
+					`"dst_port":"22","protocol":"tcp","action":"accept"}]}}}`))
+			case "/vswitch/4321/server":
+				writer.WriteHeader(http.StatusCreated)
+			case "/vswitch/4321":
+				_, _ = writer.Write([]byte(`{"id":4321,"name":"private","vlan":4000,"cancelled":false,` +
+					`"server":[{"server_number":1,"server_ip":"1.2.3.4","status":"ready"},` +
+					`{"server_number":2,"server_ip":"5.6.7.8","status":"ready"}]}`))
+			default:
+				http.NotFound(writer, req)
+			}
+		}),
+	)
+	t.Cleanup(robot.Close)
+
+	t.Setenv("HETZNERROBOT_USERNAME", "foo")
+	t.Setenv("HETZNERROBOT_PASSWORD", "bar")
+	t.Setenv("HETZNERROBOT_URL", robot.URL)
+
+	return &requests
+}
+
+func run(t *testing.T, args ...string) (string, string) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+
+	err := robotctl.Run(context.Background(), args, &stdout, &stderr)
+	if err != nil {
+		t.Fatalf("Run(%v) error: %v, stderr: %s", args, err, stderr.String())
+	}
+
+	return stdout.String(), stderr.String()
+}
+
+func TestServerList(t *testing.T) {
+	mockRobot(t)
+
+	stdout, _ := run(t, "server", "list")
+
+	want := "" +
+		"NUMBER  NAME   IP       PRODUCT  DATACENTER  STATUS  CANCELLED\n" +
+		"1       web-1  1.2.3.4  AX41     FSN1-DC14   ready   false\n" +
+		"2       db-1   5.6.7.8  AX101    HEL1-DC2    ready   false\n"
+	if stdout != want {
+		t.Errorf("server list:\n%s\nwant:\n%s", stdout, want)
+	}
+
+	stdout, _ = run(t, "server", "list", "--output", "json")
+
+	var servers []client.Server
+
+	err := json.Unmarshal([]byte(stdout), &servers)
+	if err != nil {
+		t.Fatalf("decoding output: %v", err)
+	}
+
+	if len(servers) != 2 || servers[1].ServerName != "db-1" {
+		t.Errorf("server list --output json = %+v, want web-1 and db-1", servers)
+	}
+}
+
+func TestFailoverSwitch(t *testing.T) {
+	requests := mockRobot(t)
+
+	stdout, stderr := run(t, "failover", "switch", "9.9.9.9", "5.6.7.8", "--wait", "--output", "json")
+
+	var rec client.Failover
+
+	err := json.Unmarshal([]byte(stdout), &rec)
+	if err != nil {
+		t.Fatalf("decoding output: %v", err)
+	}
+
+	if rec.ActiveServerIP != "5.6.7.8" {
+		t.Errorf("active server = %s, want 5.6.7.8", rec.ActiveServerIP)
+	}
+
+	if !strings.Contains(stderr, "Routing failover 9.9.9.9 from 1.2.3.4 to 5.6.7.8") {
+		t.Errorf("progress = %q, want the routing", stderr)
+	}
+
+	// Already routed, nothing is sent.
+	_, stderr = run(t, "failover", "switch", "9.9.9.9", "5.6.7.8")
+
+	if !strings.Contains(stderr, "already routes to 5.6.7.8") {
+		t.Errorf("progress = %q, want already routed", stderr)
+	}
+
+	want := []string{"/failover/9.9.9.9?active_server_ip=5.6.7.8"}
+	if !slices.Equal(*requests, want) {
+		t.Errorf("requests = %v, want %v", *requests, want)
+	}
+}
+
+func TestFirewallShow(t *testing.T) {
+	mockRobot(t)
+
+	stdout, _ := run(t, "firewall", "show", "1")
+
+	want := "" +
+		"Firewall of 1.2.3.4: active, Hetzner services whitelisted: true\n\n" +
+		"IP_VERSION  NAME  SRC_IP  SRC_PORT  DST_IP  DST_PORT  PROTOCOL  TCP_FLAGS  ACTION\n" +
+		"ipv4        ssh                             22        tcp                  accept\n"
+	if stdout != want {
+		t.Errorf("firewall show:\n%s\nwant:\n%s", stdout, want)
+	}
+}
+
+func TestVSwitchAdd(t *testing.T) {
+	requests := mockRobot(t)
+
+	stdout, _ := run(t, "vswitch", "add", "--wait", "4321", "2")
+
+	if !strings.Contains(stdout, "2       5.6.7.8    ready") {
+		t.Errorf("vswitch add output:\n%s\nwant server 2 ready", stdout)
+	}
+
+	want := []string{"/vswitch/4321/server?server%5B%5D=2"}
+	if !slices.Equal(*requests, want) {
+		t.Errorf("requests = %v, want %v", *requests, want)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	t.Parallel()
+
+	for _, args := range [][]string{
+		{},
+		{"server"},
+		{"server", "format"},
+		{"server", "reset"},
+		{"failover", "switch", "9.9.9.9"},
+		{"vswitch", "add", "4321"},
+	} {
+		var stdout, stderr bytes.Buffer
+
+		err := robotctl.Run(context.Background(), args, &stdout, &stderr)
+		if !errors.Is(err, robotctl.ErrUsage) {
+			t.Errorf("Run(%v) error = %v, want ErrUsage", args, err)
+		}
+	}
+}
@@ -0,0 +1,307 @@
+package robotctl
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/client"
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/server"
+)
+
+const (
+	defaultResetType  = "hw"
+	defaultRescueOS   = "linux"
+	defaultSSHTimeout = 3 * time.Minute
+	sshInterval       = 10 * time.Second
+)
+
+// serverResult is the outcome of a server operation.
+type serverResult struct {
+	ServerID  string `json:"server_id"`
+	ServerIP  string `json:"server_ip"`
+	Operation string `json:"operation"`
+	// Password is the root password of the rescue system.
+	Password string `json:"password,omitempty"`
+	// SSH is whether SSH was waited for and answered.
+	SSH bool `json:"ssh"`
+}
+
+// stringsFlag is a flag that can be repeated.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+
+	return nil
+}
+
+// waitFlags registers the flags waiting for SSH.
+func waitFlags(flags *flag.FlagSet) (*bool, *time.Duration) {
+	wait := flags.Bool("wait", false, "Wait for SSH to answer")
+	timeout := flags.Duration("ssh-timeout", defaultSSHTimeout, "How long --wait waits for SSH")
+
+	return wait, timeout
+}
+
+func serverCommands() []command {
+	//exhaustruct:ignore
+	return []command{
+		{
+			subsystem: "server",
+			name:      "list",
+			summary:   "List the servers",
+			setup: func(*flag.FlagSet) func(context.Context, *runner, []string) error {
+				return serverList
+			},
+		},
+		{
+			subsystem: "server",
+			name:      "show",
+			args:      "<server-id>",
+			nargs:     1,
+			summary:   "Show a server",
+			setup: func(*flag.FlagSet) func(context.Context, *runner, []string) error {
+				return serverShow
+			},
+		},
+		{
+			subsystem: "server",
+			name:      "reset",
+			args:      "<server-id>",
+			nargs:     1,
+			summary:   "Reset a server",
+			setup:     setupServerReset,
+		},
+		{
+			subsystem: "server",
+			name:      "rescue",
+			args:      "<server-id>",
+			nargs:     1,
+			summary:   "Activate the rescue system of a server and reset it into it",
+			setup:     setupServerRescue,
+		},
+		{
+			subsystem: "server",
+			name:      "wake",
+			args:      "<server-id>",
+			nargs:     1,
+			summary:   "Send Wake on LAN to a server",
+			setup:     setupServerWake,
+		},
+	}
+}
+
+func serverRows(servers []client.Server) [][]string {
+	rows := make([][]string, len(servers))
+	for i, srv := range servers {
+		rows[i] = []string{
+			strconv.Itoa(srv.Number),
+			srv.ServerName,
+			srv.IP,
+			srv.Product,
+			srv.Datacenter,
+			srv.Status,
+			strconv.FormatBool(srv.Cancelled),
+		}
+	}
+
+	return rows
+}
+
+//nolint:gochecknoglobals
+var serverHeader = []string{"NUMBER", "NAME", "IP", "PRODUCT", "DATACENTER", "STATUS", "CANCELLED"}
+
+func serverList(ctx context.Context, r *runner, _ []string) error {
+	servers, err := r.client.FetchAllServers(ctx)
+	if err != nil {
+		return fmt.Errorf("error fetching servers: %w", err)
+	}
+
+	return r.print(servers, serverHeader, serverRows(servers))
+}
+
+func serverShow(ctx context.Context, r *runner, args []string) error {
+	srv, err := r.client.FetchServerByID(ctx, args[0])
+	if err != nil {
+		return fmt.Errorf("error fetching server %s: %w", args[0], err)
+	}
+
+	return r.print(srv, serverHeader, serverRows([]client.Server{srv}))
+}
+
+// printServerResult prints the outcome of a server operation.
+func (r *runner) printServerResult(result serverResult) error {
+	row := []string{result.ServerID, result.ServerIP, result.Operation, strconv.FormatBool(result.SSH)}
+	header := []string{"SERVER", "IP", "OPERATION", "SSH"}
+
+	if result.Password != "" {
+		row = append(row, result.Password)
+		header = append(header, "PASSWORD")
+	}
+
+	return r.print(result, header, [][]string{row})
+}
+
+// waitForSSH waits for the SSH port of ip, reporting progress.
+func (r *runner) waitForSSH(ctx context.Context, ip string, timeout time.Duration) error {
+	r.progress("Waiting up to %s for SSH on %s", timeout, ip)
+
+	return server.WaitForSSH(ctx, ip, timeout, sshInterval, func(waited time.Duration) {
+		r.progress("SSH on %s not available yet after %s", ip, waited.Round(time.Second))
+	})
+}
+
+// waitForSSHDown waits for the SSH port of ip to go down, reporting progress,
+// and returns the time waited.
+func (r *runner) waitForSSHDown(ctx context.Context, ip string, timeout time.Duration) (time.Duration, error) {
+	r.progress("Waiting up to %s for SSH on %s to go down", timeout, ip)
+
+	start := time.Now()
+
+	err := server.WaitForSSHDown(ctx, ip, timeout, sshInterval, func(waited time.Duration) {
+		r.progress("SSH on %s still available after %s", ip, waited.Round(time.Second))
+	})
+
+	return time.Since(start), err
+}
+
+func setupServerReset(flags *flag.FlagSet) func(context.Context, *runner, []string) error {
+	resetType := flags.String("type", defaultResetType, "Reset type: sw, hw, man, power or power_long")
+	wait, timeout := waitFlags(flags)
+
+	return func(ctx context.Context, r *runner, args []string) error {
+		serverID := args[0]
+
+		srv, err := r.client.FetchServerByID(ctx, serverID)
+		if err != nil {
+			return fmt.Errorf("error fetching server %s: %w", serverID, err)
+		}
+
+		r.progress("Sending %s reset to server %s", *resetType, serverID)
+
+		err = r.client.RebootServer(ctx, serverID, *resetType)
+		if err != nil {
+			return fmt.Errorf("error resetting server %s: %w", serverID, err)
+		}
+
+		if *wait {
+			remaining := *timeout
+
+			// SSH may still answer for a while after sw and man resets.
+			if server.ResetKeepsSSHUp(*resetType) {
+				waited, err := r.waitForSSHDown(ctx, srv.IP, remaining)
+				if err != nil {
+					return err
+				}
+
+				remaining -= waited
+			}
+
+			err = r.waitForSSH(ctx, srv.IP, remaining)
+			if err != nil {
+				return err
+			}
+		}
+
+		//exhaustruct:ignore
+		return r.printServerResult(serverResult{
+			ServerID:  serverID,
+			ServerIP:  srv.IP,
+			Operation: *resetType + " reset",
+			SSH:       *wait,
+		})
+	}
+}
+
+func setupServerRescue(flags *flag.FlagSet) func(context.Context, *runner, []string) error {
+	var sshKeys stringsFlag
+
+	rescueOS := flags.String("os", defaultRescueOS, "Rescue system, e.g. linux or vkvm")
+	reboot := flags.Bool("reboot", true, "Reset the server into the rescue system")
+	flags.Var(&sshKeys, "ssh-key", "Fingerprint of an SSH key to authorize, can be repeated")
+	wait, timeout := waitFlags(flags)
+
+	return func(ctx context.Context, r *runner, args []string) error {
+		serverID := args[0]
+
+		r.progress("Activating the %s rescue system of server %s", *rescueOS, serverID)
+
+		ip, password, err := server.ActivateRescue(ctx, r.client, serverID, *rescueOS, sshKeys)
+		if err != nil {
+			return err
+		}
+
+		result := serverResult{
+			ServerID:  serverID,
+			ServerIP:  ip,
+			Operation: *rescueOS + " rescue",
+			Password:  password,
+			SSH:       false,
+		}
+
+		if !*reboot {
+			return r.printServerResult(result)
+		}
+
+		r.progress("Sending %s reset to server %s", defaultResetType, serverID)
+
+		err = r.client.RebootServer(ctx, serverID, defaultResetType)
+		if err != nil {
+			return fmt.Errorf("error resetting server %s: %w", serverID, err)
+		}
+
+		if *wait {
+			err = r.waitForSSH(ctx, ip, *timeout)
+			if err != nil {
+				return err
+			}
+
+			result.SSH = true
+		}
+
+		return r.printServerResult(result)
+	}
+}
+
+func setupServerWake(flags *flag.FlagSet) func(context.Context, *runner, []string) error {
+	wait, timeout := waitFlags(flags)
+
+	return func(ctx context.Context, r *runner, args []string) error {
+		serverID := args[0]
+
+		srv, err := r.client.FetchServerByID(ctx, serverID)
+		if err != nil {
+			return fmt.Errorf("error fetching server %s: %w", serverID, err)
+		}
+
+		r.progress("Sending Wake on LAN to server %s", serverID)
+
+		err = r.client.WakeOnLAN(ctx, serverID)
+		if err != nil {
+			return fmt.Errorf("error waking server %s: %w", serverID, err)
+		}
+
+		if *wait {
+			err = r.waitForSSH(ctx, srv.IP, *timeout)
+			if err != nil {
+				return err
+			}
+		}
+
+		//exhaustruct:ignore
+		return r.printServerResult(serverResult{
+			ServerID:  serverID,
+			ServerIP:  srv.IP,
+			Operation: "wake",
+			SSH:       *wait,
+		})
+	}
+}
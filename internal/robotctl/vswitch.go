@@ -0,0 +1,148 @@
+package robotctl
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/client"
+)
+
+func vswitchCommands() []command {
+	//exhaustruct:ignore
+	return []command{
+		{
+			subsystem: "vswitch",
+			name:      "list",
+			summary:   "List the vSwitches",
+			setup: func(*flag.FlagSet) func(context.Context, *runner, []string) error {
+				return vswitchList
+			},
+		},
+		{
+			subsystem: "vswitch",
+			name:      "show",
+			args:      "<vswitch-id>",
+			nargs:     1,
+			summary:   "Show the servers of a vSwitch and their status",
+			setup: func(*flag.FlagSet) func(context.Context, *runner, []string) error {
+				return vswitchShow
+			},
+		},
+		{
+			subsystem: "vswitch",
+			name:      "add",
+			args:      "<vswitch-id> <server-id>...",
+			nargs:     2,
+			variadic:  true,
+			summary:   "Connect servers to a vSwitch",
+			setup:     setupVSwitchMembership(true),
+		},
+		{
+			subsystem: "vswitch",
+			name:      "remove",
+			args:      "<vswitch-id> <server-id>...",
+			nargs:     2,
+			variadic:  true,
+			summary:   "Disconnect servers from a vSwitch",
+			setup:     setupVSwitchMembership(false),
+		},
+	}
+}
+
+func vswitchList(ctx context.Context, r *runner, _ []string) error {
+	vswitches, err := r.client.FetchAllVSwitches(ctx)
+	if err != nil {
+		return fmt.Errorf("error fetching vSwitches: %w", err)
+	}
+
+	rows := make([][]string, len(vswitches))
+	for i, vsw := range vswitches {
+		rows[i] = []string{
+			strconv.Itoa(vsw.ID),
+			vsw.Name,
+			strconv.Itoa(vsw.VLAN),
+			strconv.FormatBool(vsw.Cancelled),
+		}
+	}
+
+	return r.print(vswitches, []string{"ID", "NAME", "VLAN", "CANCELLED"}, rows)
+}
+
+func vswitchShow(ctx context.Context, r *runner, args []string) error {
+	vsw, err := r.client.FetchVSwitchByID(ctx, args[0])
+	if err != nil {
+		return fmt.Errorf("error fetching vSwitch %s: %w", args[0], err)
+	}
+
+	return r.printVSwitch(vsw)
+}
+
+// printVSwitch prints a vSwitch, as a summary line followed by its servers in
+// table output.
+func (r *runner) printVSwitch(vsw client.VSwitch) error {
+	if r.output == outputTable {
+		_, _ = fmt.Fprintf(r.stdout, "vSwitch %d %s, VLAN %d\n\n", vsw.ID, vsw.Name, vsw.VLAN)
+	}
+
+	rows := make([][]string, len(vsw.Servers))
+	for i, server := range vsw.Servers {
+		rows[i] = []string{strconv.Itoa(server.ServerNumber), server.ServerIP, server.Status}
+	}
+
+	return r.print(vsw, []string{"SERVER", "SERVER_IP", "STATUS"}, rows)
+}
+
+// setupVSwitchMembership returns the setup of vswitch add, or of vswitch
+// remove if add is false.
+func setupVSwitchMembership(add bool) func(*flag.FlagSet) func(context.Context, *runner, []string) error {
+	return func(flags *flag.FlagSet) func(context.Context, *runner, []string) error {
+		wait := flags.Bool("wait", false, "Wait for no server to be processing anymore")
+
+		return func(ctx context.Context, r *runner, args []string) error {
+			id := args[0]
+
+			servers := make([]client.VSwitchServer, 0, len(args)-1)
+			numbers := make([]int, 0, len(args)-1)
+
+			for _, arg := range args[1:] {
+				number, err := strconv.Atoi(arg)
+				if err != nil {
+					return fmt.Errorf("%w: server id %q is not a number", ErrUsage, arg)
+				}
+
+				//exhaustruct:ignore
+				servers = append(servers, client.VSwitchServer{ServerNumber: number})
+				numbers = append(numbers, number)
+			}
+
+			var err error
+
+			if add {
+				r.progress("Connecting %d server(s) to vSwitch %s", len(servers), id)
+				err = r.client.AddVSwitchServers(ctx, id, servers)
+			} else {
+				r.progress("Disconnecting %d server(s) from vSwitch %s", len(servers), id)
+				err = r.client.RemoveVSwitchServers(ctx, id, servers)
+				// The failures of disconnected servers do not matter.
+				numbers = nil
+			}
+
+			if err != nil {
+				return err
+			}
+
+			if *wait {
+				r.progress("Waiting for vSwitch %s to be ready", id)
+
+				err = r.client.WaitForVSwitchServers(ctx, id, numbers)
+				if err != nil {
+					return err
+				}
+			}
+
+			return vswitchShow(ctx, r, []string{id})
+		}
+	}
+}
@@ -0,0 +1,455 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/action"
+	"github.com/hashicorp/terraform-plugin-framework/action/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/client"
+)
+
+const (
+	// ActionResetType is the type name of the Hetzner Robot server reset action.
+	ActionResetType = "hetznerrobot_server_reset"
+	// ActionRescueType is the type name of the Hetzner Robot server rescue action.
+	ActionRescueType = "hetznerrobot_server_rescue"
+	// ActionWakeType is the type name of the Hetzner Robot Wake on LAN action.
+	ActionWakeType = "hetznerrobot_server_wake"
+
+	defaultResetType = "hw"
+)
+
+// Reset types accepted by Robot.
+//
+//nolint:gochecknoglobals
+var validResetTypes = []string{"sw", "hw", "man", "power", "power_long"}
+
+// serverAction holds the client shared by the server actions.
+type serverAction struct {
+	client *client.HetznerRobotClient
+}
+
+func (a *serverAction) Configure(
+	_ context.Context,
+	req action.ConfigureRequest,
+	resp *action.ConfigureResponse,
+) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	hClient, ok := req.ProviderData.(*client.HetznerRobotClient)
+	if !ok {
+		resp.Diagnostics.AddError("invalid client type", fmt.Sprintf("got %T", req.ProviderData))
+
+		return
+	}
+
+	a.client = hClient
+}
+
+// waitForServerSSH waits for the SSH port of a server, reporting progress. If
+// down is true, it first waits for the port to go down, within the same
+// timeout.
+func (a *serverAction) waitForServerSSH(
+	ctx context.Context,
+	ip string,
+	timeoutMinutes types.Int64,
+	down bool,
+	resp *action.InvokeResponse,
+) error {
+	timeout := waitMin * time.Minute
+	if !timeoutMinutes.IsNull() {
+		timeout = time.Duration(timeoutMinutes.ValueInt64()) * time.Minute
+	}
+
+	if down {
+		resp.SendProgress(action.InvokeProgressEvent{
+			Message: fmt.Sprintf("Waiting up to %s for SSH on %s to go down", timeout, ip),
+		})
+
+		start := time.Now()
+
+		err := WaitForSSHDown(ctx, ip, timeout, retryAfterSec*time.Second, func(waited time.Duration) {
+			resp.SendProgress(action.InvokeProgressEvent{
+				Message: fmt.Sprintf("SSH on %s still available after %s", ip, waited.Round(time.Second)),
+			})
+		})
+		if err != nil {
+			return err
+		}
+
+		timeout -= time.Since(start)
+	}
+
+	resp.SendProgress(action.InvokeProgressEvent{
+		Message: fmt.Sprintf("Waiting up to %s for SSH on %s", timeout.Round(time.Second), ip),
+	})
+
+	return WaitForSSH(ctx, ip, timeout, retryAfterSec*time.Second, func(waited time.Duration) {
+		resp.SendProgress(action.InvokeProgressEvent{
+			Message: fmt.Sprintf("SSH on %s not available yet after %s", ip, waited.Round(time.Second)),
+		})
+	})
+}
+
+// serverIP returns the main IPv4 of a server.
+func (a *serverAction) serverIP(ctx context.Context, serverID string) (string, error) {
+	server, err := a.client.FetchServerByID(ctx, serverID)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch server %s info: %w", serverID, err)
+	}
+
+	return server.IP, nil
+}
+
+func serverIDAttribute() schema.StringAttribute {
+	return schema.StringAttribute{
+		Required:    true,
+		Description: "Server ID (Hetzner server number).",
+	}
+}
+
+func sshTimeoutAttribute() schema.Int64Attribute {
+	return schema.Int64Attribute{
+		Optional:    true,
+		Validators:  []validator.Int64{int64validator.AtLeast(1)},
+		Description: fmt.Sprintf("Minutes to wait for SSH. Defaults to %d.", waitMin),
+	}
+}
+
+// resetAction defines the server_reset action.
+type resetAction struct {
+	serverAction
+}
+
+// resetModel maps the server_reset action schema.
+type resetModel struct {
+	ServerID          types.String `tfsdk:"server_id"`
+	Type              types.String `tfsdk:"type"`
+	WaitForSSH        types.Bool   `tfsdk:"wait_for_ssh"`
+	SSHTimeoutMinutes types.Int64  `tfsdk:"ssh_timeout_minutes"`
+}
+
+// NewResetAction returns the server_reset action.
+//
+//nolint:ireturn
+func NewResetAction() action.Action {
+	return &resetAction{serverAction: serverAction{client: nil}}
+}
+
+func (a *resetAction) Metadata(
+	_ context.Context,
+	_ action.MetadataRequest,
+	resp *action.MetadataResponse,
+) {
+	resp.TypeName = ActionResetType
+}
+
+func (a *resetAction) Schema(
+	_ context.Context,
+	_ action.SchemaRequest,
+	resp *action.SchemaResponse,
+) {
+	resp.Schema = schema.Schema{
+		Description: "Resets a server. Power resets power the server back on after 30 seconds. " +
+			"Requires Terraform 1.14 or later.",
+		Attributes: map[string]schema.Attribute{
+			"server_id": serverIDAttribute(),
+			"type": schema.StringAttribute{
+				Optional:   true,
+				Validators: []validator.String{stringvalidator.OneOf(validResetTypes...)},
+				Description: "Reset type: `sw` (CTRL+ALT+DEL), `hw` (reset button), `man` (manual reset " +
+					"by a technician), `power` or `power_long` (power button). Defaults to `hw`.",
+			},
+			"wait_for_ssh": schema.BoolAttribute{
+				Optional: true,
+				Description: "Whether to wait for the SSH port of the server to come back up. After `sw` " +
+					"and `man` resets, which may leave SSH answering for a while, it first waits for the " +
+					"port to go down, within the same timeout. Defaults to false.",
+			},
+			"ssh_timeout_minutes": sshTimeoutAttribute(),
+		},
+	}
+}
+
+func (a *resetAction) Invoke(
+	ctx context.Context,
+	req action.InvokeRequest,
+	resp *action.InvokeResponse,
+) {
+	var model resetModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &model)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	serverID := model.ServerID.ValueString()
+
+	resetType := model.Type.ValueString()
+	if resetType == "" {
+		resetType = defaultResetType
+	}
+
+	var ip string
+
+	// The IP is looked up first, to start waiting as soon as the reset is
+	// sent.
+	if model.WaitForSSH.ValueBool() {
+		var err error
+
+		ip, err = a.serverIP(ctx, serverID)
+		if err != nil {
+			resp.Diagnostics.AddError("failed to reset server "+serverID, err.Error())
+
+			return
+		}
+	}
+
+	resp.SendProgress(action.InvokeProgressEvent{
+		Message: fmt.Sprintf("Sending %s reset to server %s", resetType, serverID),
+	})
+
+	err := a.client.RebootServer(ctx, serverID, resetType)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to reset server "+serverID, err.Error())
+
+		return
+	}
+
+	if !model.WaitForSSH.ValueBool() {
+		return
+	}
+
+	err = a.waitForServerSSH(ctx, ip, model.SSHTimeoutMinutes, ResetKeepsSSHUp(resetType), resp)
+	if err != nil {
+		resp.Diagnostics.AddError("SSH not available on server "+serverID, err.Error())
+	}
+}
+
+// rescueAction defines the server_rescue action.
+type rescueAction struct {
+	serverAction
+}
+
+// rescueModel maps the server_rescue action schema.
+type rescueModel struct {
+	ServerID          types.String `tfsdk:"server_id"`
+	RescueOS          types.String `tfsdk:"rescue_os"`
+	SSHKeys           types.List   `tfsdk:"ssh_keys"`
+	Reboot            types.Bool   `tfsdk:"reboot"`
+	WaitForSSH        types.Bool   `tfsdk:"wait_for_ssh"`
+	SSHTimeoutMinutes types.Int64  `tfsdk:"ssh_timeout_minutes"`
+}
+
+// NewRescueAction returns the server_rescue action.
+//
+//nolint:ireturn
+func NewRescueAction() action.Action {
+	return &rescueAction{serverAction: serverAction{client: nil}}
+}
+
+func (a *rescueAction) Metadata(
+	_ context.Context,
+	_ action.MetadataRequest,
+	resp *action.MetadataResponse,
+) {
+	resp.TypeName = ActionRescueType
+}
+
+func (a *rescueAction) Schema(
+	_ context.Context,
+	_ action.SchemaRequest,
+	resp *action.SchemaResponse,
+) {
+	resp.Schema = schema.Schema{
+		Description: "Activates the rescue system of a server, or reuses it if already active with the " +
+			"same OS and SSH keys, and reboots the server into it. Unlike `hetznerrobot_os_rescue`, " +
+			"nothing is stored in state: read the root password with the `" +
+			EphemeralRescueCredentialsType + "` ephemeral resource, which reuses the active rescue " +
+			"system. Requires Terraform 1.14 or later.",
+		Attributes: map[string]schema.Attribute{
+			"server_id": serverIDAttribute(),
+			"rescue_os": schema.StringAttribute{
+				Optional:    true,
+				Description: "Operating system for rescue mode (e.g. linux, freebsd). Defaults to `linux`.",
+			},
+			"ssh_keys": schema.ListAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "List of public SSH keys to install in the rescue system's authorized_keys. " +
+					"If non-empty, the rescue system disables password authentication.",
+			},
+			"reboot": schema.BoolAttribute{
+				Optional: true,
+				Description: "Whether to hw reset the server into the rescue system. Otherwise it boots " +
+					"into it on its next reboot. Defaults to true.",
+			},
+			"wait_for_ssh": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Whether to wait for the SSH port of the rescue system after the reboot. Defaults to true.",
+			},
+			"ssh_timeout_minutes": sshTimeoutAttribute(),
+		},
+	}
+}
+
+func (a *rescueAction) Invoke(
+	ctx context.Context,
+	req action.InvokeRequest,
+	resp *action.InvokeResponse,
+) {
+	var model rescueModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &model)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var sshKeys []string
+
+	resp.Diagnostics.Append(model.SSHKeys.ElementsAs(ctx, &sshKeys, false)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	serverID := model.ServerID.ValueString()
+
+	rescueOS := model.RescueOS.ValueString()
+	if rescueOS == "" {
+		rescueOS = defaultRescueOS
+	}
+
+	resp.SendProgress(action.InvokeProgressEvent{
+		Message: fmt.Sprintf("Activating the %s rescue system of server %s", rescueOS, serverID),
+	})
+
+	ip, _, err := ActivateRescue(ctx, a.client, serverID, rescueOS, sshKeys)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to activate rescue system", err.Error())
+
+		return
+	}
+
+	if !model.Reboot.IsNull() && !model.Reboot.ValueBool() {
+		return
+	}
+
+	resp.SendProgress(action.InvokeProgressEvent{
+		Message: fmt.Sprintf("Sending %s reset to server %s", defaultResetType, serverID),
+	})
+
+	err = a.client.RebootServer(ctx, serverID, defaultResetType)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to reset server "+serverID, err.Error())
+
+		return
+	}
+
+	if !model.WaitForSSH.IsNull() && !model.WaitForSSH.ValueBool() {
+		return
+	}
+
+	err = a.waitForServerSSH(ctx, ip, model.SSHTimeoutMinutes, false, resp)
+	if err != nil {
+		resp.Diagnostics.AddError("SSH not available on server "+serverID, err.Error())
+	}
+}
+
+// wakeAction defines the server_wake action.
+type wakeAction struct {
+	serverAction
+}
+
+// wakeModel maps the server_wake action schema.
+type wakeModel struct {
+	ServerID          types.String `tfsdk:"server_id"`
+	WaitForSSH        types.Bool   `tfsdk:"wait_for_ssh"`
+	SSHTimeoutMinutes types.Int64  `tfsdk:"ssh_timeout_minutes"`
+}
+
+// NewWakeAction returns the server_wake action.
+//
+//nolint:ireturn
+func NewWakeAction() action.Action {
+	return &wakeAction{serverAction: serverAction{client: nil}}
+}
+
+func (a *wakeAction) Metadata(
+	_ context.Context,
+	_ action.MetadataRequest,
+	resp *action.MetadataResponse,
+) {
+	resp.TypeName = ActionWakeType
+}
+
+func (a *wakeAction) Schema(
+	_ context.Context,
+	_ action.SchemaRequest,
+	resp *action.SchemaResponse,
+) {
+	resp.Schema = schema.Schema{
+		Description: "Sends a Wake on LAN packet to a server. Requires Terraform 1.14 or later.",
+		Attributes: map[string]schema.Attribute{
+			"server_id": serverIDAttribute(),
+			"wait_for_ssh": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Whether to wait for the SSH port of the server to come up. Defaults to false.",
+			},
+			"ssh_timeout_minutes": sshTimeoutAttribute(),
+		},
+	}
+}
+
+func (a *wakeAction) Invoke(
+	ctx context.Context,
+	req action.InvokeRequest,
+	resp *action.InvokeResponse,
+) {
+	var model wakeModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &model)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	serverID := model.ServerID.ValueString()
+
+	resp.SendProgress(action.InvokeProgressEvent{
+		Message: "Sending Wake on LAN to server " + serverID,
+	})
+
+	err := a.client.WakeOnLAN(ctx, serverID)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to wake server "+serverID, err.Error())
+
+		return
+	}
+
+	if !model.WaitForSSH.ValueBool() {
+		return
+	}
+
+	ip, err := a.serverIP(ctx, serverID)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to wake server "+serverID, err.Error())
+
+		return
+	}
+
+	err = a.waitForServerSSH(ctx, ip, model.SSHTimeoutMinutes, false, resp)
+	if err != nil {
+		resp.Diagnostics.AddError("SSH not available on server "+serverID, err.Error())
+	}
+}
@@ -3,6 +3,7 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -19,6 +20,13 @@ func DataSourceServers() *schema.Resource {
 	return &schema.Resource{
 		ReadContext: dataSourceServersRead,
 		Schema: map[string]*schema.Schema{
+			"allow_partial": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				Description: "Whether to return the servers that could be read, with a warning for " +
+					"each one that failed, instead of failing.",
+			},
 			"ids": {
 				Type:     schema.TypeList,
 				Optional: true,
@@ -60,10 +68,20 @@ func dataSourceServersRead(ctx context.Context, d *schema.ResourceData, meta any
 	}
 
 	var (
+		diags   diag.Diagnostics
 		servers []client.Server
 		err     error
 	)
 
+	err = hClient.CheckQuota(client.QuotaFamilyServer, len(ids))
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "Robot request quota",
+			Detail:   err.Error(),
+		})
+	}
+
 	if len(ids) == 0 {
 		servers, err = hClient.FetchAllServers(ctx)
 	} else {
@@ -71,7 +89,18 @@ func dataSourceServersRead(ctx context.Context, d *schema.ResourceData, meta any
 	}
 
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("failed to fetch servers: %w", err))
+		var taskErrs client.TaskErrors
+		if !d.Get("allow_partial").(bool) || !errors.As(err, &taskErrs) {
+			return diag.FromErr(fmt.Errorf("failed to fetch servers: %w", err))
+		}
+
+		for _, id := range taskErrs.IDs() {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  "Skipped server " + id,
+				Detail:   taskErrs[id].Error(),
+			})
+		}
 	}
 
 	serverList := make([]map[string]any, 0, len(servers))
@@ -102,5 +131,5 @@ func dataSourceServersRead(ctx context.Context, d *schema.ResourceData, meta any
 
 	d.SetId("servers-" + idStr)
 
-	return nil
+	return diags
 }
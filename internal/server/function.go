@@ -0,0 +1,140 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/netip"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+const (
+	// IPv6HostFunctionName is the name of the ipv6_host provider function.
+	IPv6HostFunctionName = "ipv6_host"
+	// Prefix length of the server_ipv6_net subnets, which Robot reports
+	// without it.
+	serverIPv6PrefixLen = 64
+	ipv6Bits            = 128
+)
+
+// ipv6HostFunction defines the ipv6_host provider function.
+type ipv6HostFunction struct{}
+
+// NewIPv6HostFunction returns the ipv6_host provider function.
+//
+//nolint:ireturn
+func NewIPv6HostFunction() function.Function {
+	return &ipv6HostFunction{}
+}
+
+func (f *ipv6HostFunction) Metadata(
+	_ context.Context,
+	_ function.MetadataRequest,
+	resp *function.MetadataResponse,
+) {
+	resp.Name = IPv6HostFunctionName
+}
+
+func (f *ipv6HostFunction) Definition(
+	_ context.Context,
+	_ function.DefinitionRequest,
+	resp *function.DefinitionResponse,
+) {
+	//exhaustruct:ignore
+	resp.Definition = function.Definition{
+		Summary: "Address of a host in a server IPv6 subnet",
+		Description: "Returns the n-th address of an IPv6 subnet, such as the `ipv6_net` of " +
+			"`" + DataSourceType + "`, e.g. `ipv6_host(\"2a01:4f8:a:b::\", 2)` is `2a01:4f8:a:b::2`. " +
+			"Subnets without prefix length are /64, like the ones reported by Robot.",
+		Parameters: []function.Parameter{
+			//exhaustruct:ignore
+			function.StringParameter{
+				Name:        "net",
+				Description: "IPv6 subnet, with or without prefix length.",
+			},
+			//exhaustruct:ignore
+			function.Int64Parameter{
+				Name:        "n",
+				Description: "Host number in the subnet, from 0.",
+			},
+		},
+		//exhaustruct:ignore
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *ipv6HostFunction) Run(
+	ctx context.Context,
+	req function.RunRequest,
+	resp *function.RunResponse,
+) {
+	var (
+		network string
+		host    int64
+	)
+
+	resp.Error = req.Arguments.Get(ctx, &network, &host)
+	if resp.Error != nil {
+		return
+	}
+
+	prefix, err := parseIPv6Net(network)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+
+		return
+	}
+
+	address, err := ipv6Host(prefix, host)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(1, err.Error())
+
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, address.String())
+}
+
+// parseIPv6Net parses an IPv6 subnet, defaulting to a /64 as server_ipv6_net.
+func parseIPv6Net(network string) (netip.Prefix, error) {
+	if !strings.Contains(network, "/") {
+		network = fmt.Sprintf("%s/%d", network, serverIPv6PrefixLen)
+	}
+
+	prefix, err := netip.ParsePrefix(network)
+	if err != nil {
+		return prefix, fmt.Errorf("invalid IPv6 subnet: %w", err)
+	}
+
+	if !prefix.Addr().Is6() || prefix.Addr().Is4In6() {
+		return prefix, fmt.Errorf("%s is not an IPv6 subnet", network)
+	}
+
+	return prefix.Masked(), nil
+}
+
+// ipv6Host returns the host-th address of prefix.
+func ipv6Host(prefix netip.Prefix, host int64) (netip.Addr, error) {
+	if host < 0 {
+		return netip.Addr{}, errors.New("host number must not be negative")
+	}
+
+	hostBits := uint(ipv6Bits - prefix.Bits())
+
+	offset := big.NewInt(host)
+	if offset.BitLen() > int(hostBits) {
+		return netip.Addr{}, fmt.Errorf("host number %d does not fit in %s", host, prefix)
+	}
+
+	base := prefix.Addr().As16()
+	sum := new(big.Int).Add(new(big.Int).SetBytes(base[:]), offset)
+
+	var addr [16]byte
+
+	sum.FillBytes(addr[:])
+
+	return netip.AddrFrom16(addr), nil
+}
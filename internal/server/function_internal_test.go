@@ -0,0 +1,48 @@
+package server
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestIPv6Host(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		network string
+		host    int64
+		want    string
+		wantErr bool
+	}{
+		{name: "Robot subnet", network: "2a01:4f8:a:b::", host: 2, want: "2a01:4f8:a:b::2", wantErr: false},
+		{name: "Network address", network: "2a01:4f8:a:b::/64", host: 0, want: "2a01:4f8:a:b::", wantErr: false},
+		{name: "Unmasked", network: "2a01:4f8:a:b::1/64", host: 256, want: "2a01:4f8:a:b::100", wantErr: false},
+		{name: "Last host", network: "2a01:4f8:a:b::/120", host: 255, want: "2a01:4f8:a:b::ff", wantErr: false},
+		{name: "Outside subnet", network: "2a01:4f8:a:b::/120", host: 256, want: "", wantErr: true},
+		{name: "Negative", network: "2a01:4f8:a:b::", host: -1, want: "", wantErr: true},
+		{name: "IPv4", network: "10.0.0.0/8", host: 1, want: "", wantErr: true},
+		{name: "Invalid", network: "not-a-subnet", host: 1, want: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var addr netip.Addr
+
+			prefix, err := parseIPv6Net(tt.network)
+			if err == nil {
+				addr, err = ipv6Host(prefix, tt.host)
+			}
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %t", err, tt.wantErr)
+			}
+
+			if err == nil && addr.String() != tt.want {
+				t.Errorf("ipv6Host() = %s, want %s", addr, tt.want)
+			}
+		})
+	}
+}
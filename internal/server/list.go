@@ -0,0 +1,133 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework/list"
+	"github.com/hashicorp/terraform-plugin-framework/list/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/client"
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/query"
+)
+
+// serverList lists the servers of the account.
+type serverList struct {
+	client *client.HetznerRobotClient
+}
+
+// listModel maps the server list configuration.
+type listModel struct {
+	Name       types.String `tfsdk:"name"`
+	Datacenter types.String `tfsdk:"datacenter"`
+}
+
+// NewList returns the server list resource.
+//
+//nolint:ireturn
+func NewList() list.ListResource {
+	return &serverList{client: nil}
+}
+
+func (l *serverList) Metadata(
+	_ context.Context,
+	_ resource.MetadataRequest,
+	resp *resource.MetadataResponse,
+) {
+	resp.TypeName = ResourceType
+}
+
+func (l *serverList) ListResourceConfigSchema(
+	_ context.Context,
+	_ list.ListResourceSchemaRequest,
+	resp *list.ListResourceSchemaResponse,
+) {
+	resp.Schema = schema.Schema{
+		Description: "Lists the servers of the account, cancelled servers excepted.",
+		Attributes: map[string]schema.Attribute{
+			"name":       query.NameAttribute(),
+			"datacenter": query.DatacenterAttribute(),
+		},
+	}
+}
+
+func (l *serverList) Configure(
+	_ context.Context,
+	req resource.ConfigureRequest,
+	resp *resource.ConfigureResponse,
+) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	hClient, ok := req.ProviderData.(*client.HetznerRobotClient)
+	if !ok {
+		resp.Diagnostics.AddError("invalid client type", fmt.Sprintf("got %T", req.ProviderData))
+
+		return
+	}
+
+	l.client = hClient
+}
+
+func (l *serverList) List(
+	ctx context.Context,
+	req list.ListRequest,
+	stream *list.ListResultsStream,
+) {
+	var config listModel
+
+	diags := req.Config.Get(ctx, &config)
+	if diags.HasError() {
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+
+		return
+	}
+
+	servers, err := l.client.FetchAllServers(ctx)
+	if err != nil {
+		diags.AddError("error fetching servers", err.Error())
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+
+		return
+	}
+
+	stream.Results = func(push func(list.ListResult) bool) {
+		for _, server := range servers {
+			if server.Cancelled ||
+				!query.MatchName(config.Name.ValueString(), server.ServerName) ||
+				!query.MatchDatacenter(config.Datacenter.ValueString(), server.Datacenter) {
+				continue
+			}
+
+			if !push(listResult(ctx, req, server)) {
+				return
+			}
+		}
+	}
+}
+
+// listResult returns the list result of server. The list of servers already
+// holds all their attributes, so the resource costs no extra request.
+func listResult(ctx context.Context, req list.ListRequest, server client.Server) list.ListResult {
+	result := req.NewListResult(ctx)
+
+	serverID := strconv.Itoa(server.Number)
+
+	result.DisplayName = server.ServerName
+	if result.DisplayName == "" {
+		result.DisplayName = "server " + serverID
+	}
+
+	result.Diagnostics.Append(
+		result.Identity.Set(ctx, identityModel{ServerID: types.StringValue(serverID)})...,
+	)
+
+	if req.IncludeResource {
+		result.Diagnostics.Append(result.Resource.Set(ctx, serverModel(server))...)
+	}
+
+	return result
+}
@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"time"
@@ -16,23 +17,36 @@ const (
 	ResourceOSRescueType = "hetznerrobot_os_rescue"
 	waitMin              = 3
 	retryAfterSec        = 10
+	defaultRescueOS      = "linux"
 )
 
 // ResourceOSRescue defines the os_rescue terraform resource.
 func ResourceOSRescue() *schema.Resource {
 	return &schema.Resource{
 		Description: `Reboot a server into Hetzner Robot rescue system:
-1. activate the Hetzner Robot rescue system
+1. activate the Hetzner Robot rescue system, or reuse it if already active with the same OS and SSH keys
 2. issue a hw reset (equivalent to pressing the reset button)
 3. wait for the rescue system's SSH port to come up
 4. rename the server
 
 Updates only handle server_name changes; all other fields are effectively immutable.
-Read and Delete are no-ops, so destroying the resource does not deactivate rescue mode or reboot the server back to its installed OS.`,
+Read only records the resource identity and Delete is a no-op, so destroying the resource does not deactivate rescue mode or reboot the server back to its installed OS.
+On Terraform 1.14 or later, prefer the hetznerrobot_server_rescue action, which does not pretend to track the rescue system in state.`,
 		CreateContext: resourceOSRescueCreate,
-		ReadContext:   schema.NoopContext,
+		ReadContext:   resourceOSRescueRead,
 		UpdateContext: resourceOSRescueUpdate,
 		DeleteContext: schema.NoopContext,
+		Identity: &schema.ResourceIdentity{
+			SchemaFunc: func() map[string]*schema.Schema {
+				return map[string]*schema.Schema{
+					"server_id": {
+						Type:              schema.TypeString,
+						RequiredForImport: true,
+						Description:       "Server ID (Hetzner server number).",
+					},
+				}
+			},
+		},
 		Schema: map[string]*schema.Schema{
 			"server_name": {
 				Type:        schema.TypeString,
@@ -47,7 +61,7 @@ Read and Delete are no-ops, so destroying the resource does not deactivate rescu
 			"rescue_os": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				Default:     "linux",
+				Default:     defaultRescueOS,
 				Description: "Operating system for rescue mode (e.g. linux, freebsd).",
 			},
 			"ssh_keys": {
@@ -64,10 +78,13 @@ Read and Delete are no-ops, so destroying the resource does not deactivate rescu
 				Description: "Public IPv4 of the server.",
 			},
 			"ssh_password": {
-				Type:        schema.TypeString,
-				Computed:    true,
-				Sensitive:   true,
-				Description: "One-shot root password for the rescue system. Set only when ssh_keys is empty; otherwise this is empty and you authenticate with one of the listed keys.",
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+				Description: "One-shot root password for the rescue system, stored in state. Set only when ssh_keys is empty; " +
+					"otherwise this is empty and you authenticate with one of the listed keys. To keep the password " +
+					"out of state, use the `" + EphemeralRescueCredentialsType + "` ephemeral resource with the " +
+					"`" + ActionResetType + "` action instead.",
 			},
 		},
 	}
@@ -93,16 +110,11 @@ func resourceOSRescueCreate(
 		sshKeys = append(sshKeys, key.(string))
 	}
 
-	rescueResp, err := hClient.EnableRescueMode(ctx, serverID, rescueOS, sshKeys)
+	ip, pass, err := ActivateRescue(ctx, hClient, serverID, rescueOS, sshKeys)
 	if err != nil {
-		return diag.FromErr(
-			fmt.Errorf("failed to enable rescue mode for server %s: %w", serverID, err),
-		)
+		return diag.FromErr(err)
 	}
 
-	ip := rescueResp.Rescue.ServerIP
-	pass := rescueResp.Rescue.Password
-
 	err = hClient.RebootServer(ctx, serverID, "hw")
 	if err != nil {
 		return diag.FromErr(
@@ -110,7 +122,7 @@ func resourceOSRescueCreate(
 		)
 	}
 
-	err = waitForSSH(ctx, ip, waitMin*time.Minute, retryAfterSec*time.Second)
+	err = WaitForSSH(ctx, ip, waitMin*time.Minute, retryAfterSec*time.Second, nil)
 	if err != nil {
 		return diag.FromErr(fmt.Errorf("SSH not available on server %s: %w", serverID, err))
 	}
@@ -132,6 +144,22 @@ func resourceOSRescueCreate(
 
 	d.SetId(serverID)
 
+	return resourceOSRescueRead(ctx, d, meta)
+}
+
+// resourceOSRescueRead only sets the identity, the rescue system state is not
+// tracked once the server is renamed.
+func resourceOSRescueRead(_ context.Context, d *schema.ResourceData, _ any) diag.Diagnostics {
+	identity, err := d.Identity()
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("error getting identity: %w", err))
+	}
+
+	err = identity.Set("server_id", d.Id())
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("error setting server_id identity: %w", err))
+	}
+
 	return nil
 }
 
@@ -163,11 +191,78 @@ func resourceOSRescueUpdate(
 	return nil
 }
 
-func waitForSSH(
+// ActivateRescue activates the rescue system of a server, returning its IP and
+// root password. A rescue system already active is reused only if it runs
+// rescueOS with exactly sshKeys, otherwise ErrRescueAlreadyActive is returned
+// rather than silently ignoring them.
+func ActivateRescue(
+	ctx context.Context,
+	hClient *client.HetznerRobotClient,
+	serverID string,
+	rescueOS string,
+	sshKeys []string,
+) (string, string, error) {
+	rescueResp, err := hClient.EnableRescueMode(ctx, serverID, rescueOS, sshKeys)
+	if errors.Is(err, client.ErrRescueAlreadyActive) {
+		active, fetchErr := hClient.FetchRescue(ctx, serverID)
+		if fetchErr != nil {
+			return "", "", fmt.Errorf("failed to read rescue mode of server %s: %w", serverID, fetchErr)
+		}
+
+		if active.Matches(rescueOS, sshKeys) {
+			rescueResp, err = active, nil
+		}
+	}
+
+	if err != nil {
+		return "", "", fmt.Errorf("failed to enable rescue mode for server %s: %w", serverID, err)
+	}
+
+	return rescueResp.Rescue.ServerIP, rescueResp.Rescue.Password, nil
+}
+
+// WaitForSSH waits for the SSH port of ip to accept connections, calling
+// progress, if not nil, with the time waited so far after each failed attempt.
+func WaitForSSH(
 	ctx context.Context,
 	ip string,
 	timeout time.Duration,
 	interval time.Duration,
+	progress func(waited time.Duration),
+) error {
+	return waitForPort(ctx, ip, true, timeout, interval, progress)
+}
+
+// WaitForSSHDown waits for the SSH port of ip to stop accepting connections,
+// calling progress, if not nil, with the time waited so far after each attempt
+// still answered.
+func WaitForSSHDown(
+	ctx context.Context,
+	ip string,
+	timeout time.Duration,
+	interval time.Duration,
+	progress func(waited time.Duration),
+) error {
+	return waitForPort(ctx, ip, false, timeout, interval, progress)
+}
+
+// ResetKeepsSSHUp reports whether SSH may still answer for a while after a
+// reset of resetType: a software reset shuts the server down cleanly and a
+// manual one waits for a technician, so SSH answering right after them does
+// not mean the server is back.
+func ResetKeepsSSHUp(resetType string) bool {
+	return resetType == "sw" || resetType == "man"
+}
+
+// waitForPort waits for the SSH port of ip to accept connections, or to stop
+// accepting them if up is false.
+func waitForPort(
+	ctx context.Context,
+	ip string,
+	up bool,
+	timeout time.Duration,
+	interval time.Duration,
+	progress func(waited time.Duration),
 ) error {
 	const waitTime = 5
 
@@ -176,16 +271,35 @@ func waitForSSH(
 		Timeout: waitTime * time.Second,
 	}
 
-	deadline := time.Now().Add(timeout)
+	start := time.Now()
+	deadline := start.Add(timeout)
+
 	for time.Now().Before(deadline) {
 		conn, err := dialer.DialContext(ctx, "tcp", ip+":22")
 		if err == nil {
 			_ = conn.Close()
+		} else if ctx.Err() != nil {
+			// A cancelled dial does not mean the port is down.
+			return ctx.Err()
+		}
 
+		if (err == nil) == up {
 			return nil
 		}
 
-		time.Sleep(interval)
+		if progress != nil {
+			progress(time.Since(start))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+
+	if !up {
+		return fmt.Errorf("SSH still available on %s after %v", ip, timeout)
 	}
 
 	return fmt.Errorf("SSH not available on %s after %v", ip, timeout)
@@ -0,0 +1,28 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWaitForSSHCancelled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	// 192.0.2.1 is reserved for documentation, nothing answers there: the
+	// wait only ends with the context.
+	err := WaitForSSH(ctx, "192.0.2.1", time.Hour, time.Hour, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("WaitForSSH() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	if waited := time.Since(start); waited > 10*time.Second {
+		t.Errorf("WaitForSSH() returned after %s, want right after the context is done", waited)
+	}
+}
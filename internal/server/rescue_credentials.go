@@ -0,0 +1,151 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/client"
+)
+
+// EphemeralRescueCredentialsType is the type name of the Hetzner Robot rescue
+// credentials ephemeral resource.
+const EphemeralRescueCredentialsType = "hetznerrobot_rescue_credentials"
+
+// rescueCredentials defines the rescue_credentials ephemeral resource.
+type rescueCredentials struct {
+	client *client.HetznerRobotClient
+}
+
+// rescueCredentialsModel maps the rescue_credentials ephemeral resource schema.
+type rescueCredentialsModel struct {
+	ServerID    types.String `tfsdk:"server_id"`
+	RescueOS    types.String `tfsdk:"rescue_os"`
+	SSHKeys     types.List   `tfsdk:"ssh_keys"`
+	IP          types.String `tfsdk:"ip"`
+	SSHPassword types.String `tfsdk:"ssh_password"`
+}
+
+// NewEphemeralRescueCredentials returns the rescue_credentials ephemeral
+// resource.
+//
+//nolint:ireturn
+func NewEphemeralRescueCredentials() ephemeral.EphemeralResource {
+	return &rescueCredentials{client: nil}
+}
+
+func (e *rescueCredentials) Metadata(
+	_ context.Context,
+	_ ephemeral.MetadataRequest,
+	resp *ephemeral.MetadataResponse,
+) {
+	resp.TypeName = EphemeralRescueCredentialsType
+}
+
+func (e *rescueCredentials) Schema(
+	_ context.Context,
+	_ ephemeral.SchemaRequest,
+	resp *ephemeral.SchemaResponse,
+) {
+	resp.Schema = schema.Schema{
+		Description: "Activates the rescue system of a server, or reuses it if already active with " +
+			"the same OS and SSH keys, and returns its root password without storing it in state or " +
+			"plan. Requires Terraform 1.10 or later. The server is not rebooted: reset it into the " +
+			"rescue system, e.g. with the `" + ActionResetType + "` action. Terraform opens ephemeral " +
+			"resources during plan too, so a plan already activates the rescue system, and the apply " +
+			"reuses it. Once the server booted into it, Robot deactivates the rescue system, so the " +
+			"next plan activates a new one.",
+		Attributes: map[string]schema.Attribute{
+			"server_id": schema.StringAttribute{
+				Required:    true,
+				Description: "Server ID (Hetzner server number).",
+			},
+			"rescue_os": schema.StringAttribute{
+				Optional:    true,
+				Description: "Operating system for rescue mode (e.g. linux, freebsd). Defaults to `linux`.",
+			},
+			"ssh_keys": schema.ListAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "List of public SSH keys to install in the rescue system's authorized_keys. " +
+					"If non-empty, the rescue system disables password authentication and `ssh_password` will be empty.",
+			},
+			"ip": schema.StringAttribute{
+				Computed:    true,
+				Description: "Public IPv4 of the server.",
+			},
+			"ssh_password": schema.StringAttribute{
+				Computed:    true,
+				Sensitive:   true,
+				Description: "Root password of the rescue system. Empty when it was activated with SSH keys.",
+			},
+		},
+	}
+}
+
+func (e *rescueCredentials) Configure(
+	_ context.Context,
+	req ephemeral.ConfigureRequest,
+	resp *ephemeral.ConfigureResponse,
+) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	hClient, ok := req.ProviderData.(*client.HetznerRobotClient)
+	if !ok {
+		resp.Diagnostics.AddError("invalid client type", fmt.Sprintf("got %T", req.ProviderData))
+
+		return
+	}
+
+	e.client = hClient
+}
+
+func (e *rescueCredentials) Open(
+	ctx context.Context,
+	req ephemeral.OpenRequest,
+	resp *ephemeral.OpenResponse,
+) {
+	var model rescueCredentialsModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &model)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	rescueOS := model.RescueOS.ValueString()
+	if rescueOS == "" {
+		rescueOS = defaultRescueOS
+	}
+
+	var sshKeys []string
+
+	resp.Diagnostics.Append(model.SSHKeys.ElementsAs(ctx, &sshKeys, false)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ip, password, err := ActivateRescue(
+		ctx,
+		e.client,
+		model.ServerID.ValueString(),
+		rescueOS,
+		sshKeys,
+	)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to activate rescue system", err.Error())
+
+		return
+	}
+
+	model.RescueOS = types.StringValue(rescueOS)
+	model.IP = types.StringValue(ip)
+	model.SSHPassword = types.StringValue(password)
+
+	resp.Diagnostics.Append(resp.Result.Set(ctx, model)...)
+}
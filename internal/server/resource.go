@@ -0,0 +1,327 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/identityschema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/client"
+)
+
+// ResourceType is the type name of the Hetzner Robot server resource.
+const ResourceType = "hetznerrobot_server"
+
+// serverResource adopts an existing server of the account, managing its name.
+// Servers are ordered and cancelled outside of Terraform.
+type serverResource struct {
+	client *client.HetznerRobotClient
+}
+
+// resourceModel maps the server resource schema.
+type resourceModel struct {
+	ID         types.String `tfsdk:"id"`
+	ServerID   types.String `tfsdk:"server_id"`
+	ServerName types.String `tfsdk:"server_name"`
+	ServerIP   types.String `tfsdk:"server_ip"`
+	IPv6Net    types.String `tfsdk:"ipv6_net"`
+	Product    types.String `tfsdk:"product"`
+	Datacenter types.String `tfsdk:"datacenter"`
+	Status     types.String `tfsdk:"status"`
+	Cancelled  types.Bool   `tfsdk:"cancelled"`
+	PaidUntil  types.String `tfsdk:"paid_until"`
+}
+
+// identityModel maps the server identity schema.
+type identityModel struct {
+	ServerID types.String `tfsdk:"server_id"`
+}
+
+// NewResource returns the server terraform resource.
+//
+//nolint:ireturn
+func NewResource() resource.Resource {
+	return &serverResource{client: nil}
+}
+
+func (r *serverResource) Metadata(
+	_ context.Context,
+	_ resource.MetadataRequest,
+	resp *resource.MetadataResponse,
+) {
+	resp.TypeName = ResourceType
+}
+
+func (r *serverResource) Schema(
+	_ context.Context,
+	_ resource.SchemaRequest,
+	resp *resource.SchemaResponse,
+) {
+	keep := []planmodifier.String{stringplanmodifier.UseStateForUnknown()}
+
+	resp.Schema = schema.Schema{
+		Description: "Adopts an existing server of the account and manages its name. Servers are " +
+			"ordered and cancelled in Robot: creating the resource renames the server if " +
+			"`server_name` is set, and destroying it only removes it from the state.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:      true,
+				PlanModifiers: keep,
+			},
+			"server_id": schema.StringAttribute{
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Description: "Server ID (Hetzner server number).",
+			},
+			"server_name": schema.StringAttribute{
+				Optional:      true,
+				Computed:      true,
+				PlanModifiers: keep,
+				Description:   "Name of the server. Left as found on Robot when not set.",
+			},
+			"server_ip": schema.StringAttribute{
+				Computed:      true,
+				PlanModifiers: keep,
+				Description:   "Main IPv4 address of the server.",
+			},
+			"ipv6_net": schema.StringAttribute{
+				Computed:      true,
+				PlanModifiers: keep,
+				Description:   "IPv6 subnet of the server.",
+			},
+			"product": schema.StringAttribute{
+				Computed:      true,
+				PlanModifiers: keep,
+				Description:   "Product of the server (e.g. `AX41`).",
+			},
+			"datacenter": schema.StringAttribute{
+				Computed:      true,
+				PlanModifiers: keep,
+				Description:   "Datacenter of the server (e.g. `FSN1-DC14`).",
+			},
+			"status": schema.StringAttribute{
+				Computed:    true,
+				Description: "Status of the server, `ready` or `in process`.",
+			},
+			"cancelled": schema.BoolAttribute{
+				Computed:    true,
+				Description: "Whether the server is cancelled.",
+			},
+			"paid_until": schema.StringAttribute{
+				Computed:    true,
+				Description: "Date until which the server is paid.",
+			},
+		},
+	}
+}
+
+func (r *serverResource) IdentitySchema(
+	_ context.Context,
+	_ resource.IdentitySchemaRequest,
+	resp *resource.IdentitySchemaResponse,
+) {
+	resp.IdentitySchema = identityschema.Schema{
+		Attributes: map[string]identityschema.Attribute{
+			"server_id": identityschema.StringAttribute{
+				RequiredForImport: true,
+				Description:       "Server ID (Hetzner server number).",
+			},
+		},
+	}
+}
+
+func (r *serverResource) Configure(
+	_ context.Context,
+	req resource.ConfigureRequest,
+	resp *resource.ConfigureResponse,
+) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	hClient, ok := req.ProviderData.(*client.HetznerRobotClient)
+	if !ok {
+		resp.Diagnostics.AddError("invalid client type", fmt.Sprintf("got %T", req.ProviderData))
+
+		return
+	}
+
+	r.client = hClient
+}
+
+func (r *serverResource) Create(
+	ctx context.Context,
+	req resource.CreateRequest,
+	resp *resource.CreateResponse,
+) {
+	var plan resourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state, err := r.adopt(ctx, plan)
+	if err != nil {
+		resp.Diagnostics.AddError("error adopting server", err.Error())
+
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
+	resp.Diagnostics.Append(resp.Identity.Set(ctx, identityModel{ServerID: state.ServerID})...)
+}
+
+func (r *serverResource) Read(
+	ctx context.Context,
+	req resource.ReadRequest,
+	resp *resource.ReadResponse,
+) {
+	var state resourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	server, err := r.client.FetchServerByID(ctx, state.ServerID.ValueString())
+	if err != nil {
+		if errors.Is(err, client.ErrServerNotFound) {
+			resp.State.RemoveResource(ctx)
+
+			return
+		}
+
+		resp.Diagnostics.AddError("error fetching server "+state.ServerID.ValueString(), err.Error())
+
+		return
+	}
+
+	state = serverModel(server)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
+	resp.Diagnostics.Append(resp.Identity.Set(ctx, identityModel{ServerID: state.ServerID})...)
+}
+
+func (r *serverResource) Update(
+	ctx context.Context,
+	req resource.UpdateRequest,
+	resp *resource.UpdateResponse,
+) {
+	var plan resourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state, err := r.adopt(ctx, plan)
+	if err != nil {
+		resp.Diagnostics.AddError("error updating server", err.Error())
+
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
+	resp.Diagnostics.Append(resp.Identity.Set(ctx, identityModel{ServerID: state.ServerID})...)
+}
+
+// Delete leaves the server untouched, it is only removed from the state.
+func (r *serverResource) Delete(
+	_ context.Context,
+	_ resource.DeleteRequest,
+	_ *resource.DeleteResponse,
+) {
+}
+
+func (r *serverResource) ImportState(
+	ctx context.Context,
+	req resource.ImportStateRequest,
+	resp *resource.ImportStateResponse,
+) {
+	id := req.ID
+
+	// Import blocks may identify the server by identity instead of ID.
+	if id == "" && req.Identity != nil {
+		resp.Diagnostics.Append(req.Identity.GetAttribute(ctx, path.Root("server_id"), &id)...)
+
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	_, err := strconv.Atoi(id)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"error importing server",
+			fmt.Sprintf("invalid import ID %q: expected a server number", id),
+		)
+
+		return
+	}
+
+	server, err := r.client.FetchServerByID(ctx, id)
+	if err != nil {
+		resp.Diagnostics.AddError("error fetching server "+id, err.Error())
+
+		return
+	}
+
+	state := serverModel(server)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
+	resp.Diagnostics.Append(resp.Identity.Set(ctx, identityModel{ServerID: state.ServerID})...)
+}
+
+// adopt fetches the server, renames it if the planned name differs, and
+// returns its state.
+func (r *serverResource) adopt(ctx context.Context, plan resourceModel) (resourceModel, error) {
+	serverID := plan.ServerID.ValueString()
+
+	server, err := r.client.FetchServerByID(ctx, serverID)
+	if err != nil {
+		return resourceModel{}, fmt.Errorf("error fetching server %s: %w", serverID, err)
+	}
+
+	name := plan.ServerName
+	if !name.IsNull() && !name.IsUnknown() && name.ValueString() != server.ServerName {
+		_, err = r.client.RenameServer(ctx, serverID, name.ValueString())
+		if err != nil {
+			return resourceModel{}, fmt.Errorf("error renaming server %s: %w", serverID, err)
+		}
+
+		server.ServerName = name.ValueString()
+	}
+
+	return serverModel(server), nil
+}
+
+// serverModel returns the state of server.
+func serverModel(server client.Server) resourceModel {
+	serverID := types.StringValue(strconv.Itoa(server.Number))
+
+	return resourceModel{
+		ID:         serverID,
+		ServerID:   serverID,
+		ServerName: types.StringValue(server.ServerName),
+		ServerIP:   types.StringValue(server.IP),
+		IPv6Net:    types.StringValue(server.IPv6Net),
+		Product:    types.StringValue(server.Product),
+		Datacenter: types.StringValue(server.Datacenter),
+		Status:     types.StringValue(server.Status),
+		Cancelled:  types.BoolValue(server.Cancelled),
+		PaidUntil:  types.StringValue(server.PaidUntil),
+	}
+}
@@ -0,0 +1,156 @@
+package sshkey
+
+import (
+	"context"
+	"crypto/md5" //nolint:gosec // Robot identifies keys by their MD5 fingerprint.
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// FingerprintFunctionName is the name of the ssh_fingerprint provider function.
+const FingerprintFunctionName = "ssh_fingerprint"
+
+// fingerprintFunction defines the ssh_fingerprint provider function.
+type fingerprintFunction struct{}
+
+// NewFingerprintFunction returns the ssh_fingerprint provider function.
+//
+//nolint:ireturn
+func NewFingerprintFunction() function.Function {
+	return &fingerprintFunction{}
+}
+
+func (f *fingerprintFunction) Metadata(
+	_ context.Context,
+	_ function.MetadataRequest,
+	resp *function.MetadataResponse,
+) {
+	resp.Name = FingerprintFunctionName
+}
+
+func (f *fingerprintFunction) Definition(
+	_ context.Context,
+	_ function.DefinitionRequest,
+	resp *function.DefinitionResponse,
+) {
+	//exhaustruct:ignore
+	resp.Definition = function.Definition{
+		Summary: "MD5 fingerprint of an SSH public key",
+		Description: "Returns the MD5 fingerprint of a public key in OpenSSH `authorized_keys` " +
+			"format, as computed by Robot and used as the `" + ResourceType + "` ID.",
+		Parameters: []function.Parameter{
+			//exhaustruct:ignore
+			function.StringParameter{
+				Name:        "public_key",
+				Description: "Public key in OpenSSH `authorized_keys` format (e.g. `ssh-ed25519 AAAA...`).",
+			},
+		},
+		//exhaustruct:ignore
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *fingerprintFunction) Run(
+	ctx context.Context,
+	req function.RunRequest,
+	resp *function.RunResponse,
+) {
+	var publicKey string
+
+	resp.Error = req.Arguments.Get(ctx, &publicKey)
+	if resp.Error != nil {
+		return
+	}
+
+	fingerprint, err := Fingerprint(publicKey)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, fingerprint)
+}
+
+// Fingerprint returns the colon separated MD5 fingerprint of a public key in
+// OpenSSH authorized_keys format, e.g. "c3:c9:0a:...".
+func Fingerprint(publicKey string) (string, error) {
+	blob, err := parsePublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+
+	sum := md5.Sum(blob) //nolint:gosec
+
+	hexes := make([]string, 0, len(sum))
+	for _, b := range sum {
+		hexes = append(hexes, fmt.Sprintf("%02x", b))
+	}
+
+	return strings.Join(hexes, ":"), nil
+}
+
+// parsePublicKey returns the binary key of a public key in OpenSSH
+// authorized_keys format, checking that it matches the declared algorithm.
+func parsePublicKey(publicKey string) ([]byte, error) {
+	fields := strings.Fields(publicKey)
+	if len(fields) < 2 {
+		return nil, errors.New("public key must be in the form \"<type> <base64 key> [comment]\"")
+	}
+
+	blob, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return nil, fmt.Errorf("public key is not valid base64: %w", err)
+	}
+
+	// The key starts with its algorithm name, prefixed by its length.
+	const lengthSize = 4
+	if len(blob) < lengthSize {
+		return nil, errors.New("public key is truncated")
+	}
+
+	length := binary.BigEndian.Uint32(blob)
+	if uint64(len(blob)-lengthSize) < uint64(length) {
+		return nil, errors.New("public key is truncated")
+	}
+
+	algorithm := string(blob[lengthSize : lengthSize+int(length)])
+	if algorithm != fields[0] {
+		return nil, fmt.Errorf("public key type %q does not match its %q key", fields[0], algorithm)
+	}
+
+	return blob, nil
+}
+
+// publicKeyValidator checks that a string is a public key in OpenSSH
+// authorized_keys format, as the ssh_fingerprint function does.
+type publicKeyValidator struct{}
+
+func (v publicKeyValidator) Description(_ context.Context) string {
+	return "value must be a public key in OpenSSH authorized_keys format"
+}
+
+func (v publicKeyValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v publicKeyValidator) ValidateString(
+	_ context.Context,
+	req validator.StringRequest,
+	resp *validator.StringResponse,
+) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	_, err := parsePublicKey(req.ConfigValue.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid SSH public key", err.Error())
+	}
+}
@@ -0,0 +1,51 @@
+package sshkey
+
+import "testing"
+
+func TestFingerprint(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		publicKey string
+		want      string
+		wantErr   bool
+	}{
+		{
+			name:      "ED25519",
+			publicKey: "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIAisoHodfVmpw35XXG9kLCOaD4REpVJj/E9zy+FKUsym test",
+			want:      "c3:c9:0a:0f:b8:2e:b4:51:9f:5a:39:24:db:ae:16:95",
+			wantErr:   false,
+		},
+		{
+			name:      "Without comment",
+			publicKey: "  ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIAisoHodfVmpw35XXG9kLCOaD4REpVJj/E9zy+FKUsym\n",
+			want:      "c3:c9:0a:0f:b8:2e:b4:51:9f:5a:39:24:db:ae:16:95",
+			wantErr:   false,
+		},
+		{
+			name:      "Mismatched type",
+			publicKey: "ssh-rsa AAAAC3NzaC1lZDI1NTE5AAAAIAisoHodfVmpw35XXG9kLCOaD4REpVJj/E9zy+FKUsym",
+			want:      "",
+			wantErr:   true,
+		},
+		{name: "Invalid base64", publicKey: "ssh-ed25519 not-base64!", want: "", wantErr: true},
+		{name: "Truncated", publicKey: "ssh-ed25519 AAAAC3Nz", want: "", wantErr: true},
+		{name: "Missing key", publicKey: "ssh-ed25519", want: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := Fingerprint(tt.publicKey)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Fingerprint() error = %v, wantErr %t", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("Fingerprint() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
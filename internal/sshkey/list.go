@@ -0,0 +1,127 @@
+package sshkey
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/list"
+	"github.com/hashicorp/terraform-plugin-framework/list/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/client"
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/query"
+)
+
+// sshKeyList lists the SSH keys of the account.
+type sshKeyList struct {
+	client *client.HetznerRobotClient
+}
+
+// listModel maps the ssh_key list configuration.
+type listModel struct {
+	Name types.String `tfsdk:"name"`
+}
+
+// NewList returns the ssh_key list resource.
+//
+//nolint:ireturn
+func NewList() list.ListResource {
+	return &sshKeyList{client: nil}
+}
+
+func (l *sshKeyList) Metadata(
+	_ context.Context,
+	_ resource.MetadataRequest,
+	resp *resource.MetadataResponse,
+) {
+	resp.TypeName = ResourceType
+}
+
+func (l *sshKeyList) ListResourceConfigSchema(
+	_ context.Context,
+	_ list.ListResourceSchemaRequest,
+	resp *list.ListResourceSchemaResponse,
+) {
+	resp.Schema = schema.Schema{
+		Description: "Lists the SSH keys of the account.",
+		Attributes: map[string]schema.Attribute{
+			"name": query.NameAttribute(),
+		},
+	}
+}
+
+func (l *sshKeyList) Configure(
+	_ context.Context,
+	req resource.ConfigureRequest,
+	resp *resource.ConfigureResponse,
+) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	hClient, ok := req.ProviderData.(*client.HetznerRobotClient)
+	if !ok {
+		resp.Diagnostics.AddError("invalid client type", fmt.Sprintf("got %T", req.ProviderData))
+
+		return
+	}
+
+	l.client = hClient
+}
+
+func (l *sshKeyList) List(
+	ctx context.Context,
+	req list.ListRequest,
+	stream *list.ListResultsStream,
+) {
+	var config listModel
+
+	diags := req.Config.Get(ctx, &config)
+	if diags.HasError() {
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+
+		return
+	}
+
+	keys, err := l.client.FetchAllSSHKeys(ctx)
+	if err != nil {
+		diags.AddError("error fetching ssh keys", err.Error())
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+
+		return
+	}
+
+	stream.Results = func(push func(list.ListResult) bool) {
+		for _, key := range keys {
+			if !query.MatchName(config.Name.ValueString(), key.Name) {
+				continue
+			}
+
+			result := req.NewListResult(ctx)
+			result.DisplayName = key.Name
+
+			id := types.StringValue(key.Fingerprint)
+
+			result.Diagnostics.Append(result.Identity.Set(ctx, identityModel{Fingerprint: id})...)
+
+			if req.IncludeResource {
+				model := resourceModel{
+					ID:          id,
+					Name:        types.StringValue(key.Name),
+					Data:        types.StringValue(key.Data),
+					Fingerprint: types.StringNull(),
+					Type:        types.StringNull(),
+					Size:        types.Int64Null(),
+					CreatedAt:   types.StringNull(),
+				}
+				setComputed(&model, key)
+
+				result.Diagnostics.Append(result.Resource.Set(ctx, model)...)
+			}
+
+			if !push(result) {
+				return
+			}
+		}
+	}
+}
@@ -6,139 +6,288 @@ import (
 	"errors"
 	"fmt"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/identityschema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/client"
 )
 
 // ResourceType is the type name of the Hetzner Robot SSH key resource.
 const ResourceType = "hetznerrobot_ssh_key"
 
-// Resource defines the ssh_key terraform resource.
-func Resource() *schema.Resource {
-	return &schema.Resource{
+// sshKeyResource defines the ssh_key terraform resource.
+type sshKeyResource struct {
+	client *client.HetznerRobotClient
+}
+
+// resourceModel maps the ssh_key resource schema. It matches the state
+// written by the former SDKv2 implementation.
+type resourceModel struct {
+	ID          types.String `tfsdk:"id"`
+	Name        types.String `tfsdk:"name"`
+	Data        types.String `tfsdk:"data"`
+	Fingerprint types.String `tfsdk:"fingerprint"`
+	Type        types.String `tfsdk:"type"`
+	Size        types.Int64  `tfsdk:"size"`
+	CreatedAt   types.String `tfsdk:"created_at"`
+}
+
+// identityModel maps the ssh_key identity schema.
+type identityModel struct {
+	Fingerprint types.String `tfsdk:"fingerprint"`
+}
+
+// NewResource returns the ssh_key terraform resource.
+//
+//nolint:ireturn
+func NewResource() resource.Resource {
+	return &sshKeyResource{client: nil}
+}
+
+func (r *sshKeyResource) Metadata(
+	_ context.Context,
+	_ resource.MetadataRequest,
+	resp *resource.MetadataResponse,
+) {
+	resp.TypeName = ResourceType
+}
+
+func (r *sshKeyResource) Schema(
+	_ context.Context,
+	_ resource.SchemaRequest,
+	resp *resource.SchemaResponse,
+) {
+	keep := []planmodifier.String{stringplanmodifier.UseStateForUnknown()}
+
+	resp.Schema = schema.Schema{
 		Description: "Manages an SSH key in the Hetzner Robot account-level key registry. " +
 			"Registered keys can be referenced by fingerprint when activating the rescue " +
 			"system or ordering new servers.",
-		CreateContext: resourceCreate,
-		ReadContext:   resourceRead,
-		UpdateContext: resourceUpdate,
-		DeleteContext: resourceDelete,
-		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
-		},
-		Schema: map[string]*schema.Schema{
-			"name": {
-				Type:        schema.TypeString,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:      true,
+				PlanModifiers: keep,
+			},
+			"name": schema.StringAttribute{
 				Required:    true,
 				Description: "Display name for the key.",
 			},
-			"data": {
-				Type:        schema.TypeString,
-				Required:    true,
-				ForceNew:    true,
+			"data": schema.StringAttribute{
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators:  []validator.String{publicKeyValidator{}},
 				Description: "Public key in OpenSSH `authorized_keys` format (e.g. `ssh-ed25519 AAAA...`). The key body is immutable; changing it forces recreate.",
 			},
-			"fingerprint": {
-				Type:        schema.TypeString,
-				Computed:    true,
-				Description: "MD5 fingerprint computed by Hetzner. Used as the resource ID.",
+			"fingerprint": schema.StringAttribute{
+				Computed:      true,
+				PlanModifiers: keep,
+				Description:   "MD5 fingerprint computed by Hetzner. Used as the resource ID.",
+			},
+			"type": schema.StringAttribute{
+				Computed:      true,
+				PlanModifiers: keep,
+				Description:   "Key algorithm reported by Hetzner (e.g. `ED25519`, `RSA`).",
 			},
-			"type": {
-				Type:        schema.TypeString,
-				Computed:    true,
-				Description: "Key algorithm reported by Hetzner (e.g. `ED25519`, `RSA`).",
+			"size": schema.Int64Attribute{
+				Computed:      true,
+				PlanModifiers: []planmodifier.Int64{int64planmodifier.UseStateForUnknown()},
+				Description:   "Key size in bits.",
 			},
-			"size": {
-				Type:        schema.TypeInt,
-				Computed:    true,
-				Description: "Key size in bits.",
+			"created_at": schema.StringAttribute{
+				Computed:      true,
+				PlanModifiers: keep,
+				Description:   "Timestamp at which the key was registered.",
 			},
-			"created_at": {
-				Type:        schema.TypeString,
-				Computed:    true,
-				Description: "Timestamp at which the key was registered.",
+		},
+	}
+}
+
+func (r *sshKeyResource) IdentitySchema(
+	_ context.Context,
+	_ resource.IdentitySchemaRequest,
+	resp *resource.IdentitySchemaResponse,
+) {
+	resp.IdentitySchema = identityschema.Schema{
+		Attributes: map[string]identityschema.Attribute{
+			"fingerprint": identityschema.StringAttribute{
+				RequiredForImport: true,
+				Description:       "MD5 fingerprint of the key.",
 			},
 		},
 	}
 }
 
-func resourceCreate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
-	hClient, ok := meta.(*client.HetznerRobotClient)
+func (r *sshKeyResource) Configure(
+	_ context.Context,
+	req resource.ConfigureRequest,
+	resp *resource.ConfigureResponse,
+) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	hClient, ok := req.ProviderData.(*client.HetznerRobotClient)
 	if !ok {
-		return diag.Errorf("invalid client type")
+		resp.Diagnostics.AddError("invalid client type", fmt.Sprintf("got %T", req.ProviderData))
+
+		return
+	}
+
+	r.client = hClient
+}
+
+func (r *sshKeyResource) Create(
+	ctx context.Context,
+	req resource.CreateRequest,
+	resp *resource.CreateResponse,
+) {
+	var plan resourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	key, err := hClient.CreateSSHKey(ctx, d.Get("name").(string), d.Get("data").(string))
+	key, err := r.client.CreateSSHKey(ctx, plan.Name.ValueString(), plan.Data.ValueString())
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("failed to create ssh key: %w", err))
+		resp.Diagnostics.AddError("failed to create ssh key", err.Error())
+
+		return
 	}
 
-	d.SetId(key.Fingerprint)
+	// The name and data are kept as configured, Robot may reformat them.
+	plan.ID = types.StringValue(key.Fingerprint)
+	setComputed(&plan, key)
 
-	return resourceRead(ctx, d, meta)
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+	resp.Diagnostics.Append(resp.Identity.Set(ctx, identityModel{Fingerprint: plan.ID})...)
 }
 
-func resourceRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
-	hClient, ok := meta.(*client.HetznerRobotClient)
-	if !ok {
-		return diag.Errorf("invalid client type")
+func (r *sshKeyResource) Read(
+	ctx context.Context,
+	req resource.ReadRequest,
+	resp *resource.ReadResponse,
+) {
+	var state resourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	key, err := hClient.FetchSSHKey(ctx, d.Id())
+	key, err := r.client.FetchSSHKey(ctx, state.ID.ValueString())
 	if err != nil {
 		if errors.Is(err, client.ErrSSHKeyNotFound) {
-			d.SetId("")
+			resp.State.RemoveResource(ctx)
 
-			return nil
+			return
 		}
 
-		return diag.FromErr(fmt.Errorf("failed to read ssh key %s: %w", d.Id(), err))
-	}
+		resp.Diagnostics.AddError(
+			"failed to read ssh key "+state.ID.ValueString(),
+			err.Error(),
+		)
 
-	for k, v := range map[string]any{
-		"name":        key.Name,
-		"data":        key.Data,
-		"fingerprint": key.Fingerprint,
-		"type":        key.Type,
-		"size":        key.Size,
-		"created_at":  key.CreatedAt,
-	} {
-		err = d.Set(k, v)
-		if err != nil {
-			return diag.FromErr(fmt.Errorf("error setting %s attribute: %w", k, err))
-		}
+		return
 	}
 
-	return nil
+	state.Name = types.StringValue(key.Name)
+	state.Data = types.StringValue(key.Data)
+	setComputed(&state, key)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
+	resp.Diagnostics.Append(resp.Identity.Set(ctx, identityModel{Fingerprint: state.ID})...)
 }
 
-func resourceUpdate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
-	hClient, ok := meta.(*client.HetznerRobotClient)
-	if !ok {
-		return diag.Errorf("invalid client type")
+func (r *sshKeyResource) Update(
+	ctx context.Context,
+	req resource.UpdateRequest,
+	resp *resource.UpdateResponse,
+) {
+	var plan, state resourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	if d.HasChange("name") {
-		err := hClient.RenameSSHKey(ctx, d.Id(), d.Get("name").(string))
+	if !plan.Name.Equal(state.Name) {
+		err := r.client.RenameSSHKey(ctx, state.ID.ValueString(), plan.Name.ValueString())
 		if err != nil {
-			return diag.FromErr(fmt.Errorf("failed to rename ssh key %s: %w", d.Id(), err))
+			resp.Diagnostics.AddError(
+				"failed to rename ssh key "+state.ID.ValueString(),
+				err.Error(),
+			)
+
+			return
 		}
 	}
 
-	return resourceRead(ctx, d, meta)
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
 }
 
-func resourceDelete(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
-	hClient, ok := meta.(*client.HetznerRobotClient)
-	if !ok {
-		return diag.Errorf("invalid client type")
+func (r *sshKeyResource) Delete(
+	ctx context.Context,
+	req resource.DeleteRequest,
+	resp *resource.DeleteResponse,
+) {
+	var state resourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	err := hClient.DeleteSSHKey(ctx, d.Id())
+	err := r.client.DeleteSSHKey(ctx, state.ID.ValueString())
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("failed to delete ssh key %s: %w", d.Id(), err))
+		resp.Diagnostics.AddError(
+			"failed to delete ssh key "+state.ID.ValueString(),
+			err.Error(),
+		)
+	}
+}
+
+func (r *sshKeyResource) ImportState(
+	ctx context.Context,
+	req resource.ImportStateRequest,
+	resp *resource.ImportStateResponse,
+) {
+	resource.ImportStatePassthroughWithIdentity(
+		ctx,
+		path.Root("id"),
+		path.Root("fingerprint"),
+		req,
+		resp,
+	)
+
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	return nil
+	// An import by ID sets no identity, the fingerprint is the ID.
+	var id types.String
+
+	resp.Diagnostics.Append(resp.State.GetAttribute(ctx, path.Root("id"), &id)...)
+	resp.Diagnostics.Append(resp.Identity.Set(ctx, identityModel{Fingerprint: id})...)
+}
+
+// setComputed copies the attributes computed by Robot into model.
+func setComputed(model *resourceModel, key client.SSHKey) {
+	model.Fingerprint = types.StringValue(key.Fingerprint)
+	model.Type = types.StringValue(key.Type)
+	model.Size = types.Int64Value(int64(key.Size))
+	model.CreatedAt = types.StringValue(key.CreatedAt)
 }
@@ -21,6 +21,13 @@ func DataSource() *schema.Resource {
 	return &schema.Resource{
 		ReadContext: dataSourceRead,
 		Schema: map[string]*schema.Schema{
+			"allow_partial": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				Description: "Whether to return the vSwitches that could be read, with a warning for " +
+					"each one that failed, instead of failing.",
+			},
 			"ids": {
 				Type:     schema.TypeList,
 				Elem:     &schema.Schema{Type: schema.TypeString},
@@ -60,10 +67,20 @@ func dataSourceRead(ctx context.Context, d *schema.ResourceData, meta any) diag.
 	}
 
 	var (
+		diags     diag.Diagnostics
 		vswitches []client.VSwitch
 		err       error
 	)
 
+	err = hClient.CheckQuota(client.QuotaFamilyVSwitch, len(ids))
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "Robot request quota",
+			Detail:   err.Error(),
+		})
+	}
+
 	if len(ids) == 0 {
 		vswitches, err = hClient.FetchAllVSwitches(ctx)
 		if err != nil {
@@ -72,7 +89,18 @@ func dataSourceRead(ctx context.Context, d *schema.ResourceData, meta any) diag.
 	} else {
 		vswitches, err = hClient.FetchVSwitchesByIDs(ctx, ids)
 		if err != nil {
-			return diag.FromErr(fmt.Errorf("error fetching vSwitches by IDs: %w", err))
+			var taskErrs client.TaskErrors
+			if !d.Get("allow_partial").(bool) || !errors.As(err, &taskErrs) {
+				return diag.FromErr(fmt.Errorf("error fetching vSwitches by IDs: %w", err))
+			}
+
+			for _, id := range taskErrs.IDs() {
+				diags = append(diags, diag.Diagnostic{
+					Severity: diag.Warning,
+					Summary:  "Skipped vSwitch " + id,
+					Detail:   taskErrs[id].Error(),
+				})
+			}
 		}
 	}
 
@@ -92,7 +120,7 @@ func dataSourceRead(ctx context.Context, d *schema.ResourceData, meta any) diag.
 
 	d.SetId("vswitches-" + idStr)
 
-	return nil
+	return diags
 }
 
 func flattenVSwitches(vswitches []client.VSwitch) []map[string]any {
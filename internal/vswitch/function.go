@@ -0,0 +1,69 @@
+package vswitch
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// VLANInRangeFunctionName is the name of the vlan_in_range provider function.
+const VLANInRangeFunctionName = "vlan_in_range"
+
+// vlanInRangeFunction defines the vlan_in_range provider function.
+type vlanInRangeFunction struct{}
+
+// NewVLANInRangeFunction returns the vlan_in_range provider function.
+//
+//nolint:ireturn
+func NewVLANInRangeFunction() function.Function {
+	return &vlanInRangeFunction{}
+}
+
+func (f *vlanInRangeFunction) Metadata(
+	_ context.Context,
+	_ function.MetadataRequest,
+	resp *function.MetadataResponse,
+) {
+	resp.Name = VLANInRangeFunctionName
+}
+
+func (f *vlanInRangeFunction) Definition(
+	_ context.Context,
+	_ function.DefinitionRequest,
+	resp *function.DefinitionResponse,
+) {
+	//exhaustruct:ignore
+	resp.Definition = function.Definition{
+		Summary: "Check a vSwitch VLAN ID",
+		Description: fmt.Sprintf(
+			"Returns whether a VLAN ID is accepted by Robot for a vSwitch, i.e. in [%d..%d].",
+			minVLAN,
+			maxVLAN,
+		),
+		Parameters: []function.Parameter{
+			//exhaustruct:ignore
+			function.Int64Parameter{
+				Name:        "vlan",
+				Description: "VLAN ID.",
+			},
+		},
+		//exhaustruct:ignore
+		Return: function.BoolReturn{},
+	}
+}
+
+func (f *vlanInRangeFunction) Run(
+	ctx context.Context,
+	req function.RunRequest,
+	resp *function.RunResponse,
+) {
+	var vlan int64
+
+	resp.Error = req.Arguments.Get(ctx, &vlan)
+	if resp.Error != nil {
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, vlanInRange(vlan))
+}
@@ -0,0 +1,106 @@
+package vswitch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/client"
+)
+
+// vswitchIdentity is the identity of the resources managing a whole vSwitch.
+func vswitchIdentity() *schema.ResourceIdentity {
+	//exhaustruct:ignore
+	return &schema.ResourceIdentity{
+		SchemaFunc: func() map[string]*schema.Schema {
+			return map[string]*schema.Schema{
+				"vswitch_id": {
+					Type:              schema.TypeString,
+					RequiredForImport: true,
+					Description:       "vSwitch ID.",
+				},
+			}
+		},
+	}
+}
+
+// serverIdentity is the identity of a server attachment.
+func serverIdentity() *schema.ResourceIdentity {
+	//exhaustruct:ignore
+	return &schema.ResourceIdentity{
+		SchemaFunc: func() map[string]*schema.Schema {
+			return map[string]*schema.Schema{
+				"vswitch_id": {
+					Type:              schema.TypeString,
+					RequiredForImport: true,
+					Description:       "vSwitch ID.",
+				},
+				"server_number": {
+					Type:              schema.TypeInt,
+					RequiredForImport: true,
+					Description:       "Server number attached to the vSwitch.",
+				},
+			}
+		},
+	}
+}
+
+// setIdentity sets the identity attributes of a resource.
+func setIdentity(d *schema.ResourceData, values map[string]any) error {
+	identity, err := d.Identity()
+	if err != nil {
+		return fmt.Errorf("error getting identity: %w", err)
+	}
+
+	for key, value := range values {
+		err = identity.Set(key, value)
+		if err != nil {
+			return fmt.Errorf("error setting %s identity: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// resourceImportState imports a vSwitch by ID, given as import ID or identity.
+// A vSwitch pending cancellation is imported as such, its cancellation date
+// being taken from the configuration as Robot does not report it.
+func resourceImportState(
+	ctx context.Context,
+	d *schema.ResourceData,
+	meta any,
+) ([]*schema.ResourceData, error) {
+	hClient, ok := meta.(*client.HetznerRobotClient)
+	if !ok {
+		return nil, errors.New("invalid client type")
+	}
+
+	if d.Id() == "" {
+		identity, err := d.Identity()
+		if err != nil {
+			return nil, fmt.Errorf("error getting identity: %w", err)
+		}
+
+		d.SetId(identity.Get("vswitch_id").(string))
+	}
+
+	vsw, err := hClient.FetchVSwitchByID(ctx, d.Id())
+	if err != nil {
+		return nil, fmt.Errorf("error fetching vSwitch %s: %w", d.Id(), err)
+	}
+
+	if vsw.Cancelled {
+		err = d.Set("status", statusPendingCancellation)
+		if err != nil {
+			return nil, fmt.Errorf("error setting status attribute: %w", err)
+		}
+	}
+
+	err = setIdentity(d, map[string]any{"vswitch_id": d.Id()})
+	if err != nil {
+		return nil, err
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
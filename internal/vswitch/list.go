@@ -0,0 +1,209 @@
+package vswitch
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework/list"
+	"github.com/hashicorp/terraform-plugin-framework/list/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/client"
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/query"
+)
+
+// vswitchList lists the vSwitches of the account. The resource is served by
+// the SDKv2 provider, so its schemas are converted for the framework.
+type vswitchList struct {
+	client *client.HetznerRobotClient
+}
+
+// listModel maps the vswitch list configuration.
+type listModel struct {
+	Name       types.String `tfsdk:"name"`
+	Datacenter types.String `tfsdk:"datacenter"`
+}
+
+// NewList returns the vswitch list resource.
+//
+//nolint:ireturn
+func NewList() list.ListResource {
+	return &vswitchList{client: nil}
+}
+
+func (l *vswitchList) Metadata(
+	_ context.Context,
+	_ resource.MetadataRequest,
+	resp *resource.MetadataResponse,
+) {
+	resp.TypeName = ResourceType
+}
+
+func (l *vswitchList) RawV6Schemas(
+	ctx context.Context,
+	_ list.RawV6SchemaRequest,
+	resp *list.RawV6SchemaResponse,
+) {
+	query.SDKSchemas(ctx, Resource(), resp)
+}
+
+func (l *vswitchList) ListResourceConfigSchema(
+	_ context.Context,
+	_ list.ListResourceSchemaRequest,
+	resp *list.ListResourceSchemaResponse,
+) {
+	datacenter := query.DatacenterAttribute()
+	datacenter.Description += " A vSwitch is located in the datacenters of its servers."
+
+	resp.Schema = schema.Schema{
+		Description: "Lists the vSwitches of the account, cancelled ones excepted.",
+		Attributes: map[string]schema.Attribute{
+			"name":       query.NameAttribute(),
+			"datacenter": datacenter,
+		},
+	}
+}
+
+func (l *vswitchList) Configure(
+	_ context.Context,
+	req resource.ConfigureRequest,
+	resp *resource.ConfigureResponse,
+) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	hClient, ok := req.ProviderData.(*client.HetznerRobotClient)
+	if !ok {
+		resp.Diagnostics.AddError("invalid client type", fmt.Sprintf("got %T", req.ProviderData))
+
+		return
+	}
+
+	l.client = hClient
+}
+
+func (l *vswitchList) List(
+	ctx context.Context,
+	req list.ListRequest,
+	stream *list.ListResultsStream,
+) {
+	var config listModel
+
+	diags := req.Config.Get(ctx, &config)
+	if diags.HasError() {
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+
+		return
+	}
+
+	vswitches, err := l.matchingVSwitches(ctx, config, req.IncludeResource)
+	if err != nil {
+		diags.AddError("error fetching vSwitches", err.Error())
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+
+		return
+	}
+
+	stream.Results = func(push func(list.ListResult) bool) {
+		for _, vsw := range vswitches {
+			if !push(listResult(ctx, req, vsw)) {
+				return
+			}
+		}
+	}
+}
+
+// matchingVSwitches returns the vSwitches matching the filters. The listing
+// of Robot has no servers, so they are fetched one by one when needed.
+func (l *vswitchList) matchingVSwitches(
+	ctx context.Context,
+	config listModel,
+	withServers bool,
+) ([]client.VSwitch, error) {
+	all, err := l.client.FetchAllVSwitches(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		matching []client.VSwitch
+		ids      []string
+	)
+
+	for _, vsw := range all {
+		if vsw.Cancelled || !query.MatchName(config.Name.ValueString(), vsw.Name) {
+			continue
+		}
+
+		matching = append(matching, vsw)
+		ids = append(ids, strconv.Itoa(vsw.ID))
+	}
+
+	if config.Datacenter.IsNull() && !withServers {
+		return matching, nil
+	}
+
+	matching, err = l.client.FetchVSwitchesByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Datacenter.IsNull() {
+		return matching, nil
+	}
+
+	servers, err := l.client.FetchAllServers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching servers: %w", err)
+	}
+
+	datacenters := make(map[int]string, len(servers))
+	for _, server := range servers {
+		datacenters[server.Number] = server.Datacenter
+	}
+
+	return slices.DeleteFunc(matching, func(vsw client.VSwitch) bool {
+		return !slices.ContainsFunc(vsw.Servers, func(server client.VSwitchServer) bool {
+			return query.MatchDatacenter(config.Datacenter.ValueString(), datacenters[server.ServerNumber])
+		})
+	}), nil
+}
+
+// listResult returns the list result of a vSwitch, mirroring resourceRead.
+func listResult(ctx context.Context, req list.ListRequest, vsw client.VSwitch) list.ListResult {
+	result := req.NewListResult(ctx)
+	result.DisplayName = vsw.Name
+
+	id := strconv.Itoa(vsw.ID)
+
+	result.Diagnostics.Append(
+		result.Identity.SetAttribute(ctx, path.Root("vswitch_id"), id)...,
+	)
+
+	if !req.IncludeResource {
+		return result
+	}
+
+	servers := make([]int64, 0, len(vsw.Servers))
+	for _, number := range flattenServers(vsw.Servers) {
+		servers = append(servers, int64(number))
+	}
+
+	for key, value := range map[string]any{
+		"id":           id,
+		"name":         vsw.Name,
+		"vlan":         int64(vsw.VLAN),
+		"servers":      servers,
+		"status":       statusActive,
+		"retry_failed": int64(0),
+		"incidents":    incidents(vsw.Servers),
+	} {
+		result.Diagnostics.Append(result.Resource.SetAttribute(ctx, path.Root(key), value)...)
+	}
+
+	return result
+}
@@ -8,15 +8,26 @@ import (
 	"math/big"
 	"sort"
 	"strconv"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/client"
 )
 
 const (
 	// ResourceType is the type name of the Hetzner Robot vSwitch resource.
 	ResourceType = "hetznerrobot_vswitch"
+
+	statusActive              = "active"
+	statusPendingCancellation = "pending_cancellation"
+	cancellationNow           = "now"
+	cancellationDateLayout    = "2006-01-02"
+
+	// Range of the VLAN IDs accepted by Robot for vSwitches.
+	minVLAN = 4000
+	maxVLAN = 4091
 )
 
 // Resource defines the vswitch terraform resource.
@@ -26,6 +37,10 @@ func Resource() *schema.Resource {
 		ReadContext:   resourceRead,
 		UpdateContext: resourceUpdate,
 		DeleteContext: resourceDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceImportState,
+		},
+		Identity: vswitchIdentity(),
 
 		Schema: map[string]*schema.Schema{
 			"name": {
@@ -34,10 +49,11 @@ func Resource() *schema.Resource {
 				Description: "The name of the vSwitch.",
 			},
 			"vlan": {
-				Type:        schema.TypeInt,
-				Optional:    true,
-				Computed:    true,
-				Description: "The VLAN ID for the vSwitch. If not provided, one will be chosen randomly from [4000..4091].",
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Computed:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validateVLAN),
+				Description:      "The VLAN ID for the vSwitch. If not provided, one will be chosen randomly from [4000..4091].",
 			},
 			"servers": {
 				Type:        schema.TypeList,
@@ -46,9 +62,29 @@ func Resource() *schema.Resource {
 				Elem:        &schema.Schema{Type: schema.TypeInt},
 			},
 			"cancellation_date": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "The cancellation date for the vSwitch, either 'now' or 'YYYY-MM-DD'. " +
+					"A date, the current day or one in the future as compared in UTC, schedules the " +
+					"cancellation right away and leaves the vSwitch pending cancellation until Robot " +
+					"removes it; past dates are rejected. With 'now', the vSwitch is cancelled on " +
+					"destroy. If not provided, defaults to 'now'. Robot does not report " +
+					"the date of a cancellation: for a vSwitch imported pending cancellation, the " +
+					"configured date is recorded without being sent again.",
+				ValidateDiagFunc: validation.ToDiagFunc(validateCancellationDate),
+			},
+			"status": {
 				Type:        schema.TypeString,
-				Optional:    true,
-				Description: "The cancellation date for the vSwitch. If not provided, defaults to 'now'.",
+				Computed:    true,
+				Description: "Lifecycle status of the vSwitch: 'active' or 'pending_cancellation'.",
+			},
+			"retry_failed": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  0,
+				Description: "Number of times servers that failed to connect are removed and re-added " +
+					"before the apply fails. Defaults to 0 (no retry).",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(0)),
 			},
 			"incidents": {
 				Type:        schema.TypeList,
@@ -93,24 +129,35 @@ func resourceCreate(ctx context.Context, d *schema.ResourceData, meta any) diag.
 
 	vswID := strconv.Itoa(vsw.ID)
 
-	if servers, ok := d.GetOk("servers"); ok {
-		serverIDs := parseServerIDs(servers.([]any))
+	// Track the vSwitch right away so a failed server connection taints it
+	// instead of leaking it.
+	d.SetId(vswID)
 
-		serverObjects := parseServerIDsToVSwitchServers(serverIDs)
-		if len(serverObjects) > 0 {
-			err := hClient.AddVSwitchServers(ctx, vswID, serverObjects)
-			if err != nil {
-				return diag.FromErr(fmt.Errorf("error adding servers to vSwitch: %w", err))
-			}
+	unlock := hClient.LockVSwitch(vswID)
+	defer unlock()
+
+	serverIDs := parseServerIDs(d.Get("servers").([]any))
+
+	serverObjects := parseServerIDsToVSwitchServers(serverIDs)
+	if len(serverObjects) > 0 {
+		err := hClient.AddVSwitchServers(ctx, vswID, serverObjects)
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("error adding servers to vSwitch: %w", err))
 		}
 	}
 
-	err = hClient.WaitForVSwitchReady(ctx, vswID)
+	err = waitForServers(ctx, hClient, vswID, serverIDs, d.Get("retry_failed").(int))
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("error waiting for vSwitch readiness after create: %w", err))
+		return waitDiagnostics(err, "error waiting for vSwitch readiness after create")
 	}
 
-	d.SetId(strconv.Itoa(vsw.ID))
+	cancellationDate := d.Get("cancellation_date").(string)
+	if isUpcomingDate(cancellationDate, time.Now()) {
+		err = hClient.DeleteVSwitch(ctx, vswID, cancellationDate)
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("error scheduling vSwitch cancellation: %w", err))
+		}
+	}
 
 	return resourceRead(ctx, d, meta)
 }
@@ -125,9 +172,41 @@ func resourceRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Di
 
 	vsw, err := hClient.FetchVSwitchByID(ctx, id)
 	if err != nil {
+		if errors.Is(err, client.ErrVSwitchNotFound) {
+			d.SetId("")
+
+			return nil
+		}
+
 		return diag.FromErr(fmt.Errorf("error reading vSwitch: %w", err))
 	}
 
+	status := statusActive
+
+	// Robot keeps a cancelled vSwitch until its cancellation date, then it is
+	// not found anymore. Only a cancellation scheduled through
+	// cancellation_date, or found on import, is expected: anything else was
+	// cancelled outside of terraform.
+	if vsw.Cancelled {
+		if !isDate(d.Get("cancellation_date").(string)) &&
+			d.Get("status").(string) != statusPendingCancellation {
+			d.SetId("")
+
+			return diag.Diagnostics{{
+				Severity: diag.Warning,
+				Summary:  fmt.Sprintf("vSwitch %s has been cancelled", id),
+				Detail:   "The vSwitch was cancelled outside of Terraform and has been removed from the state.",
+			}}
+		}
+
+		status = statusPendingCancellation
+	}
+
+	err = d.Set("status", status)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("error setting status attribute: %w", err))
+	}
+
 	err = d.Set("name", vsw.Name)
 	if err != nil {
 		return diag.FromErr(fmt.Errorf("error setting name attribute: %w", err))
@@ -146,24 +225,17 @@ func resourceRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Di
 		return diag.FromErr(fmt.Errorf("error setting servers attribute: %w", err))
 	}
 
-	var incidents []string
-
-	for _, server := range vsw.Servers {
-		if server.Status == "failed" {
-			message := fmt.Sprintf(
-				"Server %d failed to connect. Please check in the Hetzner web interface.",
-				server.ServerNumber,
-			)
-			incidents = append(incidents, message)
-		}
+	err = d.Set("incidents", incidents(vsw.Servers))
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("error setting incidents attribute: %w", err))
 	}
 
-	err = d.Set("incidents", incidents)
+	err = setIdentity(d, map[string]any{"vswitch_id": id})
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("error setting incidents attribute: %w", err))
+		return diag.FromErr(err)
 	}
 
-	return nil
+	return failedServerWarnings(vsw, nil)
 }
 
 func resourceUpdate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
@@ -176,6 +248,9 @@ func resourceUpdate(ctx context.Context, d *schema.ResourceData, meta any) diag.
 	name := d.Get("name").(string)
 	vlan := d.Get("vlan").(int)
 
+	unlock := hClient.LockVSwitch(id)
+	defer unlock()
+
 	var waitForReady bool
 
 	if d.HasChange("name") || d.HasChange("vlan") {
@@ -200,11 +275,17 @@ func resourceUpdate(ctx context.Context, d *schema.ResourceData, meta any) diag.
 	}
 
 	if waitForReady {
-		err := hClient.WaitForVSwitchReady(ctx, id)
+		serverIDs := parseServerIDs(d.Get("servers").([]any))
+
+		err := waitForServers(ctx, hClient, id, serverIDs, d.Get("retry_failed").(int))
 		if err != nil {
-			return diag.FromErr(
-				fmt.Errorf("error waiting for vSwitch readiness after update: %w", err),
-			)
+			return waitDiagnostics(err, "error waiting for vSwitch readiness after update")
+		}
+	}
+
+	if d.HasChange("cancellation_date") {
+		if diags := scheduleCancellation(ctx, d, hClient, id); diags != nil {
+			return diags
 		}
 	}
 
@@ -244,6 +325,46 @@ func manageServers(
 	return nil
 }
 
+func scheduleCancellation(
+	ctx context.Context,
+	d *schema.ResourceData,
+	hClient *client.HetznerRobotClient,
+	id string,
+) diag.Diagnostics {
+	now := time.Now()
+	oldRaw, newRaw := d.GetChange("cancellation_date")
+	oldDate := oldRaw.(string)
+	newDate := newRaw.(string)
+
+	pending := d.Get("status").(string) == statusPendingCancellation
+
+	// Robot does not report the date of a cancellation: the one of a vSwitch
+	// imported pending cancellation is taken from the configuration.
+	if pending && oldDate == "" && isDate(newDate) {
+		return nil
+	}
+
+	if isUpcomingDate(newDate, now) {
+		err := hClient.DeleteVSwitch(ctx, id, newDate)
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("error scheduling vSwitch cancellation: %w", err))
+		}
+
+		return nil
+	}
+
+	if pending && isDate(oldDate) {
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("vSwitch %s is pending cancellation on %s", id, oldDate),
+			Detail: "A scheduled vSwitch cancellation cannot be withdrawn through the API. " +
+				"Please withdraw it in the Hetzner web interface.",
+		}}
+	}
+
+	return nil
+}
+
 func resourceDelete(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
 	hClient, ok := meta.(*client.HetznerRobotClient)
 	if !ok {
@@ -252,9 +373,16 @@ func resourceDelete(ctx context.Context, d *schema.ResourceData, meta any) diag.
 
 	id := d.Id()
 
+	// The cancellation is already scheduled, there is nothing left to do.
+	if d.Get("status").(string) == statusPendingCancellation {
+		d.SetId("")
+
+		return nil
+	}
+
 	cancellationDate := d.Get("cancellation_date").(string)
 	if cancellationDate == "" {
-		cancellationDate = "now"
+		cancellationDate = cancellationNow
 	}
 
 	err := hClient.DeleteVSwitch(ctx, id, cancellationDate)
@@ -293,6 +421,23 @@ func parseServerIDsToVSwitchServers(serverIDs []int) []client.VSwitchServer {
 	return servers
 }
 
+// incidents returns a warning for each server that failed to connect.
+func incidents(servers []client.VSwitchServer) []string {
+	var result []string
+
+	for _, server := range servers {
+		if server.Status == "failed" {
+			message := fmt.Sprintf(
+				"Server %d failed to connect. Please check in the Hetzner web interface.",
+				server.ServerNumber,
+			)
+			result = append(result, message)
+		}
+	}
+
+	return result
+}
+
 func flattenServers(servers []client.VSwitchServer) []int {
 	result := make([]int, 0, len(servers))
 	for _, s := range servers {
@@ -317,14 +462,14 @@ func pickRandomFreeVLAN(ctx context.Context, c *client.HetznerRobotClient) (int,
 
 	var free []int
 
-	for vlan := 4000; vlan <= 4091; vlan++ {
+	for vlan := minVLAN; vlan <= maxVLAN; vlan++ {
 		if !used[vlan] {
 			free = append(free, vlan)
 		}
 	}
 
 	if len(free) == 0 {
-		return 0, errors.New("no free VLAN in [4000..4091], all are taken")
+		return 0, fmt.Errorf("no free VLAN in [%d..%d], all are taken", minVLAN, maxVLAN)
 	}
 
 	idx, err := rand.Int(rand.Reader, big.NewInt(int64(len(free))))
@@ -335,6 +480,177 @@ func pickRandomFreeVLAN(ctx context.Context, c *client.HetznerRobotClient) (int,
 	return free[idx.Int64()], nil
 }
 
+// waitForServers waits for the vSwitch to settle and reports the managed
+// servers that failed to connect, removing and re-adding them up to retries
+// times first. The caller must hold the vSwitch lock.
+func waitForServers(
+	ctx context.Context,
+	hClient *client.HetznerRobotClient,
+	id string,
+	managed []int,
+	retries int,
+) error {
+	return resolveFailedServers(
+		ctx,
+		hClient,
+		id,
+		managed,
+		retries,
+		hClient.WaitForVSwitchReady(ctx, id),
+	)
+}
+
+// resolveFailedServers narrows a wait error down to the managed servers and
+// retries them if requested. Failures of unmanaged servers are ignored.
+func resolveFailedServers(
+	ctx context.Context,
+	hClient *client.HetznerRobotClient,
+	id string,
+	managed []int,
+	retries int,
+	waitErr error,
+) error {
+	var failedErr *client.VSwitchServerFailedError
+	if !errors.As(waitErr, &failedErr) {
+		return waitErr
+	}
+
+	managedSet := make(map[int]bool, len(managed))
+	for _, server := range managed {
+		managedSet[server] = true
+	}
+
+	var failed []int
+
+	for _, server := range failedErr.Servers {
+		if managedSet[server] {
+			failed = append(failed, server)
+		}
+	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+
+	if retries == 0 {
+		return &client.VSwitchServerFailedError{VSwitchID: id, Servers: failed}
+	}
+
+	return hClient.RetryFailedVSwitchServers(ctx, id, failed, retries)
+}
+
+// waitDiagnostics turns a wait error into diagnostics, with one diagnostic
+// per server that failed to connect.
+func waitDiagnostics(err error, summary string) diag.Diagnostics {
+	var failedErr *client.VSwitchServerFailedError
+	if !errors.As(err, &failedErr) {
+		return diag.FromErr(fmt.Errorf("%s: %w", summary, err))
+	}
+
+	diags := make(diag.Diagnostics, 0, len(failedErr.Servers))
+	for _, server := range failedErr.Servers {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary: fmt.Sprintf(
+				"Server %d failed to connect to vSwitch %s",
+				server,
+				failedErr.VSwitchID,
+			),
+			Detail: "Please check in the Hetzner web interface. " +
+				"Set retry_failed to remove and re-add failed servers automatically.",
+		})
+	}
+
+	return diags
+}
+
+// failedServerWarnings returns a warning per failed server of the vSwitch.
+// If managed is not nil, only the servers it accepts are reported.
+func failedServerWarnings(vsw client.VSwitch, managed func(int) bool) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	for _, server := range vsw.Servers {
+		if server.Status != "failed" || (managed != nil && !managed(server.ServerNumber)) {
+			continue
+		}
+
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary: fmt.Sprintf(
+				"Server %d failed to connect to vSwitch %d",
+				server.ServerNumber,
+				vsw.ID,
+			),
+			Detail: "Please check in the Hetzner web interface.",
+		})
+	}
+
+	return diags
+}
+
+// validateCancellationDate accepts "now" or a YYYY-MM-DD date on or after the
+// current day, as Robot refuses past dates.
+func validateCancellationDate(value any, key string) ([]string, []error) {
+	date, ok := value.(string)
+	if !ok {
+		return nil, []error{fmt.Errorf("expected type of %s to be string", key)}
+	}
+
+	if date == cancellationNow {
+		return nil, nil
+	}
+
+	_, err := time.Parse(cancellationDateLayout, date)
+	if err != nil {
+		return nil, []error{
+			fmt.Errorf("%s must be 'now' or a date formatted as YYYY-MM-DD, got %q", key, date),
+		}
+	}
+
+	if !isUpcomingDate(date, time.Now()) {
+		return nil, []error{
+			fmt.Errorf("%s must not be in the past (dates are compared in UTC), got %q", key, date),
+		}
+	}
+
+	return nil, nil
+}
+
+// validateVLAN checks that a VLAN ID is in the range accepted by Robot, as the
+// vlan_in_range function does.
+func validateVLAN(value any, key string) ([]string, []error) {
+	vlan, ok := value.(int)
+	if !ok {
+		return nil, []error{fmt.Errorf("expected type of %s to be int", key)}
+	}
+
+	if !vlanInRange(int64(vlan)) {
+		return nil, []error{
+			fmt.Errorf("%s must be in [%d..%d], got %d", key, minVLAN, maxVLAN, vlan),
+		}
+	}
+
+	return nil, nil
+}
+
+// vlanInRange reports whether vlan is in the range accepted by Robot.
+func vlanInRange(vlan int64) bool {
+	return vlan >= minVLAN && vlan <= maxVLAN
+}
+
+// isDate reports whether date is a YYYY-MM-DD date, as opposed to "now".
+func isDate(date string) bool {
+	_, err := time.Parse(cancellationDateLayout, date)
+
+	return err == nil
+}
+
+// isUpcomingDate reports whether date is a YYYY-MM-DD date on or after the
+// current day. Robot accepts the current day as a cancellation date.
+func isUpcomingDate(date string, now time.Time) bool {
+	return isDate(date) && date >= now.UTC().Format(cancellationDateLayout)
+}
+
 func diffServers(oldList, newList []int) ([]int, []int) {
 	oldMap := make(map[int]bool)
 	newMap := make(map[int]bool)
@@ -1,9 +1,16 @@
 package vswitch
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"slices"
 	"sort"
 	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/client"
 )
 
 func TestDiffServers(t *testing.T) {
@@ -91,3 +98,175 @@ func TestDiffServers(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateCancellationDate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		date    string
+		wantErr bool
+	}{
+		{name: "Now", date: "now", wantErr: false},
+		{name: "Date", date: "2030-01-31", wantErr: false},
+		{name: "Empty", date: "", wantErr: true},
+		{name: "Wrong format", date: "31/01/2030", wantErr: true},
+		{name: "Invalid day", date: "2030-02-30", wantErr: true},
+		{name: "Past", date: "2020-01-31", wantErr: true},
+		{name: "Today", date: time.Now().UTC().Format(cancellationDateLayout), wantErr: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, errs := validateCancellationDate(tt.date, "cancellation_date")
+			if (len(errs) > 0) != tt.wantErr {
+				t.Errorf(
+					"validateCancellationDate(%q) errors = %v, wantErr %t",
+					tt.date,
+					errs,
+					tt.wantErr,
+				)
+			}
+		})
+	}
+}
+
+func TestValidateVLAN(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		vlan    int
+		wantErr bool
+	}{
+		{vlan: 3999, wantErr: true},
+		{vlan: minVLAN, wantErr: false},
+		{vlan: 4050, wantErr: false},
+		{vlan: maxVLAN, wantErr: false},
+		{vlan: 4092, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		_, errs := validateVLAN(tt.vlan, "vlan")
+		if (len(errs) > 0) != tt.wantErr {
+			t.Errorf("validateVLAN(%d) errors = %v, wantErr %t", tt.vlan, errs, tt.wantErr)
+		}
+
+		if vlanInRange(int64(tt.vlan)) == tt.wantErr {
+			t.Errorf("vlanInRange(%d) = %t, want %t", tt.vlan, tt.wantErr, !tt.wantErr)
+		}
+	}
+}
+
+func TestIsUpcomingDate(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2030, 1, 15, 23, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		date string
+		want bool
+	}{
+		{name: "Now", date: "now", want: false},
+		{name: "Empty", date: "", want: false},
+		{name: "Past", date: "2030-01-14", want: false},
+		{name: "Today", date: "2030-01-15", want: true},
+		{name: "Tomorrow", date: "2030-01-16", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := isUpcomingDate(tt.date, now); got != tt.want {
+				t.Errorf("isUpcomingDate(%q) = %t, want %t", tt.date, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResourceReadCancelled(t *testing.T) {
+	t.Parallel()
+
+	robot := httptest.NewServer(
+		http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+			if req.URL.Path != "/vswitch/4321" {
+				http.NotFound(writer, req)
+
+				return
+			}
+
+			_, _ = writer.Write([]byte(`{"id":4321,"name":"private","vlan":4000,"cancelled":true,"server":[]}`))
+		}),
+	)
+	t.Cleanup(robot.Close)
+
+	//exhaustruct:ignore
+	hClient, err := client.New(&client.ProviderConfig{Username: "foo", Password: "bar", BaseURL: robot.URL})
+	if err != nil {
+		t.Fatalf("client.New() error: %v", err)
+	}
+
+	// Robot still reports the vSwitch: its cancellation date is not over,
+	// whatever the local clock says.
+	tests := []struct {
+		name             string
+		cancellationDate string
+		status           string
+		wantKept         bool
+	}{
+		{
+			name:             "Scheduled today",
+			cancellationDate: time.Now().UTC().Format(cancellationDateLayout),
+			status:           statusActive,
+			wantKept:         true,
+		},
+		{
+			name:             "Scheduled in the past",
+			cancellationDate: "2020-01-01",
+			status:           statusPendingCancellation,
+			wantKept:         true,
+		},
+		{
+			name:             "Imported pending cancellation",
+			cancellationDate: "",
+			status:           statusPendingCancellation,
+			wantKept:         true,
+		},
+		{
+			name:             "Cancelled outside of terraform",
+			cancellationDate: "now",
+			status:           statusActive,
+			wantKept:         false,
+		},
+	}
+
+	res := Resource()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := schema.TestResourceDataWithIdentityRaw(t, res.Schema, res.Identity.SchemaMap(), nil)
+			d.SetId("4321")
+
+			_ = d.Set("cancellation_date", tt.cancellationDate)
+			_ = d.Set("status", tt.status)
+
+			diags := resourceRead(context.Background(), d, hClient)
+			if diags.HasError() {
+				t.Fatalf("resourceRead() diagnostics: %v", diags)
+			}
+
+			if kept := d.Id() != ""; kept != tt.wantKept {
+				t.Fatalf("kept in state = %t, want %t", kept, tt.wantKept)
+			}
+
+			if tt.wantKept && d.Get("status") != statusPendingCancellation {
+				t.Errorf("status = %v, want %s", d.Get("status"), statusPendingCancellation)
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package vswitch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/client"
+)
+
+const (
+	// ServerResourceType is the type name of the Hetzner Robot vSwitch Server resource.
+	ServerResourceType = "hetznerrobot_vswitch_server"
+)
+
+// ServerResource defines the vswitch server attachment terraform resource.
+func ServerResource() *schema.Resource {
+	return &schema.Resource{
+		Description: "Attaches a single server to a vSwitch. " +
+			"Unlike `hetznerrobot_vswitch_servers`, several attachments can target the same vSwitch " +
+			"without conflicting: concurrent attachments are merged into a single API call.",
+		CreateContext: resourceServerCreate,
+		ReadContext:   resourceServerRead,
+		// Only retry_failed can change in place and it only matters on create.
+		UpdateContext: resourceServerRead,
+		DeleteContext: resourceServerDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceServerImportState,
+		},
+		Identity: serverIdentity(),
+		Schema: map[string]*schema.Schema{
+			"vswitch_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Existing vSwitch ID.",
+			},
+			"server_number": {
+				Type:        schema.TypeInt,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Server number to attach to the vSwitch.",
+			},
+			"retry_failed": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  0,
+				Description: "Number of times the server is removed and re-added if it fails to connect " +
+					"before the apply fails. Defaults to 0 (no retry).",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(0)),
+			},
+			"server_ip": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Main IPv4 of the attached server.",
+			},
+			"status": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Connection status of the server reported by the vSwitch (e.g. `ready`, `processing`, `failed`).",
+			},
+		},
+	}
+}
+
+func resourceServerCreate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	hClient, ok := meta.(*client.HetznerRobotClient)
+	if !ok {
+		return diag.Errorf("invalid client type")
+	}
+
+	vswID := d.Get("vswitch_id").(string)
+	serverNumber := d.Get("server_number").(int)
+
+	err := hClient.AttachVSwitchServer(ctx, vswID, serverNumber)
+	if err != nil && !errors.Is(err, client.ErrVSwitchServerFailed) {
+		return diag.FromErr(
+			fmt.Errorf("error attaching server %d to vSwitch %s: %w", serverNumber, vswID, err),
+		)
+	}
+
+	d.SetId(serverAttachmentID(vswID, serverNumber))
+
+	if err != nil {
+		// The batch may report failures of other attachments; only ours matters.
+		retries := d.Get("retry_failed").(int)
+		if retries > 0 {
+			unlock := hClient.LockVSwitch(vswID)
+			defer unlock()
+		}
+
+		err = resolveFailedServers(ctx, hClient, vswID, []int{serverNumber}, retries, err)
+		if err != nil {
+			return waitDiagnostics(err, "error waiting for vSwitch readiness after attach")
+		}
+	}
+
+	return resourceServerRead(ctx, d, meta)
+}
+
+func resourceServerRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	hClient, ok := meta.(*client.HetznerRobotClient)
+	if !ok {
+		return diag.Errorf("invalid client type")
+	}
+
+	vswID := d.Get("vswitch_id").(string)
+	serverNumber := d.Get("server_number").(int)
+
+	vsw, err := hClient.FetchVSwitchByID(ctx, vswID)
+	if err != nil {
+		if errors.Is(err, client.ErrVSwitchNotFound) {
+			d.SetId("")
+
+			return nil
+		}
+
+		return diag.FromErr(fmt.Errorf("error reading vSwitch: %w", err))
+	}
+
+	for _, server := range vsw.Servers {
+		if server.ServerNumber != serverNumber {
+			continue
+		}
+
+		err = d.Set("server_ip", server.ServerIP)
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("error setting server_ip attribute: %w", err))
+		}
+
+		err = d.Set("status", server.Status)
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("error setting status attribute: %w", err))
+		}
+
+		err = setIdentity(d, map[string]any{"vswitch_id": vswID, "server_number": serverNumber})
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		return failedServerWarnings(vsw, func(number int) bool { return number == serverNumber })
+	}
+
+	// The server is no longer attached, let terraform recreate the attachment.
+	d.SetId("")
+
+	return nil
+}
+
+func resourceServerDelete(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	hClient, ok := meta.(*client.HetznerRobotClient)
+	if !ok {
+		return diag.Errorf("invalid client type")
+	}
+
+	vswID := d.Get("vswitch_id").(string)
+	serverNumber := d.Get("server_number").(int)
+
+	err := hClient.DetachVSwitchServer(ctx, vswID, serverNumber)
+	if err != nil {
+		return diag.FromErr(
+			fmt.Errorf("error detaching server %d from vSwitch %s: %w", serverNumber, vswID, err),
+		)
+	}
+
+	d.SetId("")
+
+	return nil
+}
+
+// resourceServerImportState imports an attachment by <vswitch_id>/<server_number>
+// import ID or by identity.
+func resourceServerImportState(
+	_ context.Context,
+	d *schema.ResourceData,
+	_ any,
+) ([]*schema.ResourceData, error) {
+	if d.Id() == "" {
+		identity, err := d.Identity()
+		if err != nil {
+			return nil, fmt.Errorf("error getting identity: %w", err)
+		}
+
+		d.SetId(serverAttachmentID(
+			identity.Get("vswitch_id").(string),
+			identity.Get("server_number").(int),
+		))
+	}
+
+	vswID, serverNumber, err := parseServerAttachmentID(d.Id())
+	if err != nil {
+		return nil, err
+	}
+
+	err = d.Set("vswitch_id", vswID)
+	if err != nil {
+		return nil, fmt.Errorf("error setting vswitch_id attribute: %w", err)
+	}
+
+	err = d.Set("server_number", serverNumber)
+	if err != nil {
+		return nil, fmt.Errorf("error setting server_number attribute: %w", err)
+	}
+
+	err = setIdentity(d, map[string]any{"vswitch_id": vswID, "server_number": serverNumber})
+	if err != nil {
+		return nil, err
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func serverAttachmentID(vswID string, serverNumber int) string {
+	return vswID + "/" + strconv.Itoa(serverNumber)
+}
+
+func parseServerAttachmentID(id string) (string, int, error) {
+	vswID, rawNumber, found := strings.Cut(id, "/")
+	if !found || vswID == "" {
+		return "", 0, fmt.Errorf("invalid id %q, expected <vswitch_id>/<server_number>", id)
+	}
+
+	serverNumber, err := strconv.Atoi(rawNumber)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid server number in id %q: %w", id, err)
+	}
+
+	return vswID, serverNumber, nil
+}
@@ -2,12 +2,14 @@ package vswitch
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"strconv"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/client"
 )
 
@@ -23,6 +25,7 @@ func ServersResource() *schema.Resource {
 		ReadContext:   resourceServersRead,
 		UpdateContext: resourceServersUpdate,
 		DeleteContext: resourceServersDelete,
+		Identity:      vswitchIdentity(),
 
 		Schema: map[string]*schema.Schema{
 			"vswitch_id": {
@@ -42,6 +45,14 @@ func ServersResource() *schema.Resource {
 				Default:     false,
 				Description: "Whether to include non-managed servers when reading the resource state.",
 			},
+			"retry_failed": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  0,
+				Description: "Number of times servers that failed to connect are removed and re-added " +
+					"before the apply fails. Defaults to 0 (no retry).",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(0)),
+			},
 		},
 	}
 }
@@ -54,6 +65,9 @@ func resourceServersCreate(ctx context.Context, d *schema.ResourceData, meta any
 
 	vswID := d.Get("vswitch_id").(string)
 
+	unlock := hClient.LockVSwitch(vswID)
+	defer unlock()
+
 	servers := d.Get("servers")
 	serverIDs := parseServerIDs(servers.([]any))
 	serverObjs := parseServerIDsToVSwitchServers(serverIDs)
@@ -63,13 +77,13 @@ func resourceServersCreate(ctx context.Context, d *schema.ResourceData, meta any
 		return diag.FromErr(fmt.Errorf("error adding servers to vSwitch: %w", err))
 	}
 
-	err = hClient.WaitForVSwitchReady(ctx, vswID)
+	d.SetId(vswID)
+
+	err = waitForServers(ctx, hClient, vswID, serverIDs, d.Get("retry_failed").(int))
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("error waiting for vSwitch readiness after create: %w", err))
+		return waitDiagnostics(err, "error waiting for vSwitch readiness after create")
 	}
 
-	d.SetId(vswID)
-
 	return resourceServersRead(ctx, d, meta)
 }
 
@@ -83,6 +97,12 @@ func resourceServersRead(ctx context.Context, d *schema.ResourceData, meta any)
 
 	vsw, err := hClient.FetchVSwitchByID(ctx, id)
 	if err != nil {
+		if errors.Is(err, client.ErrVSwitchNotFound) {
+			d.SetId("")
+
+			return nil
+		}
+
 		return diag.FromErr(fmt.Errorf("error reading vSwitch: %w", err))
 	}
 
@@ -93,8 +113,18 @@ func resourceServersRead(ctx context.Context, d *schema.ResourceData, meta any)
 		return diag.FromErr(fmt.Errorf("error setting vswitch_id attribute: %w", err))
 	}
 
+	err = setIdentity(d, map[string]any{"vswitch_id": vswID})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
 	if !d.Get("include_unmanaged").(bool) {
-		return nil
+		managed := make(map[int]bool)
+		for _, server := range parseServerIDs(d.Get("servers").([]any)) {
+			managed[server] = true
+		}
+
+		return failedServerWarnings(vsw, func(server int) bool { return managed[server] })
 	}
 
 	servers := flattenServers(vsw.Servers)
@@ -105,7 +135,7 @@ func resourceServersRead(ctx context.Context, d *schema.ResourceData, meta any)
 		return diag.FromErr(fmt.Errorf("error setting servers attribute: %w", err))
 	}
 
-	return nil
+	return failedServerWarnings(vsw, nil)
 }
 
 func resourceServersUpdate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
@@ -116,6 +146,9 @@ func resourceServersUpdate(ctx context.Context, d *schema.ResourceData, meta any
 
 	id := d.Id()
 
+	unlock := hClient.LockVSwitch(id)
+	defer unlock()
+
 	var waitForReady bool
 
 	if d.HasChange("servers") {
@@ -127,11 +160,11 @@ func resourceServersUpdate(ctx context.Context, d *schema.ResourceData, meta any
 	}
 
 	if waitForReady {
-		err := hClient.WaitForVSwitchReady(ctx, id)
+		serverIDs := parseServerIDs(d.Get("servers").([]any))
+
+		err := waitForServers(ctx, hClient, id, serverIDs, d.Get("retry_failed").(int))
 		if err != nil {
-			return diag.FromErr(
-				fmt.Errorf("error waiting for vSwitch readiness after update: %w", err),
-			)
+			return waitDiagnostics(err, "error waiting for vSwitch readiness after update")
 		}
 	}
 
@@ -145,6 +178,10 @@ func resourceServersDelete(ctx context.Context, d *schema.ResourceData, meta any
 	}
 
 	id := d.Id()
+
+	unlock := hClient.LockVSwitch(id)
+	defer unlock()
+
 	servers := d.Get("servers")
 	serverIDs := parseServerIDs(servers.([]any))
 	serverObjs := parseServerIDsToVSwitchServers(serverIDs)
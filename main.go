@@ -2,12 +2,48 @@
 package main
 
 import (
-	"github.com/hashicorp/terraform-plugin-sdk/v2/plugin"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6/tf6server"
 	"github.com/yellowhat/terraform-provider-hetznerrobot/hetznerrobot"
+	"github.com/yellowhat/terraform-provider-hetznerrobot/internal/export"
+)
+
+const (
+	providerAddr = "registry.terraform.io/yellowhat/hetzner-robot"
+	// Logs are enabled with TF_LOG_PROVIDER_HETZNERROBOT.
+	logEnvVarName = "hetznerrobot"
 )
 
 func main() {
-	plugin.Serve(&plugin.ServeOpts{
-		ProviderFunc: hetznerrobot.Provider,
-	})
+	// Terraform starts the provider without arguments.
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		err := export.Run(context.Background(), os.Args[2:], os.Stdout, os.Stderr)
+		if err != nil && !errors.Is(err, flag.ErrHelp) {
+			_, _ = fmt.Fprintf(os.Stderr, "Error exporting inventory: %s\n", err)
+
+			os.Exit(1)
+		}
+
+		return
+	}
+
+	providerServer, err := hetznerrobot.ProviderServer(context.Background())
+	if err != nil {
+		log.Fatalf("Error creating provider server: %s", err)
+	}
+
+	err = tf6server.Serve(
+		providerAddr,
+		providerServer,
+		tf6server.WithLogEnvVarName(logEnvVarName),
+	)
+	if err != nil {
+		log.Fatalf("Error serving provider: %s", err)
+	}
 }